@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Attribution represents the attribution required to reuse the text of an article,
+// as requested by its license: https://en.wikipedia.org/wiki/Wikipedia:Reusing_Wikipedia_content
+type Attribution struct {
+	Title string `json:"title" yaml:"title"`
+	URL   string `json:"url" yaml:"url"`
+
+	// Permalink is the URL of the revision of the article the text comes from,
+	// empty when the revision is unknown
+	Permalink string `json:"permalink,omitempty" yaml:"permalink,omitempty"`
+
+	// History is the URL of the history of the article, listing its contributors
+	History string `json:"history" yaml:"history"`
+
+	License    string `json:"license" yaml:"license"`
+	LicenseURL string `json:"license_url" yaml:"license_url"`
+}
+
+// newAttribution returns the attribution of the article with the given title and URL,
+// in the Wikipedia of the given language. The permalink is omitted when revid is 0.
+func newAttribution(lang, title, articleURL string, revid int) *Attribution {
+	a := &Attribution{
+		Title:      title,
+		URL:        articleURL,
+		History:    indexURL(lang, title, url.Values{"action": {"history"}}),
+		License:    contentLicense,
+		LicenseURL: contentLicenseURL,
+	}
+	if revid != 0 {
+		a.Permalink = indexURL(lang, title, url.Values{"oldid": {fmt.Sprint(revid)}})
+	}
+
+	return a
+}
+
+// indexURL returns the URL of the 'index.php' entry point of the Wikipedia of the given language
+// for the page with the given title, ie. "https://en.wikipedia.org/w/index.php?action=history&title=Go".
+func indexURL(lang, title string, params url.Values) string {
+	params.Set("title", strings.ReplaceAll(title, " ", "_"))
+
+	return fmt.Sprintf("https://%s.wikipedia.org/w/index.php?%s", lang, params.Encode())
+}
+
+// Lines returns the attribution as "label: value" lines.
+func (a *Attribution) Lines() []string {
+	lines := []string{fmt.Sprintf("Source: %s (%s)", a.Title, a.URL)}
+	if a.Permalink != "" {
+		lines = append(lines, "Permalink: "+a.Permalink)
+	}
+
+	return append(lines,
+		"Contributors: "+a.History,
+		fmt.Sprintf("License: %s (%s)", a.License, a.LicenseURL),
+	)
+}
+
+// String returns the attribution as a single line of text.
+func (a *Attribution) String() string {
+	return strings.Join(a.Lines(), "; ")
+}
+
+// Markdown returns the attribution as a markdown paragraph.
+func (a *Attribution) Markdown() string {
+	text := fmt.Sprintf("Text from the Wikipedia article [%s](%s)", a.Title, a.URL)
+	if a.Permalink != "" {
+		text += fmt.Sprintf(" ([permalink](%s))", a.Permalink)
+	}
+
+	return text + fmt.Sprintf(", by [its contributors](%s), available under the [%s](%s) license.", a.History, a.License, a.LicenseURL)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAttribution(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  string
+		title string
+		url   string
+		revid int
+		want  *Attribution
+	}{
+		{
+			name:  "With revision",
+			lang:  "en",
+			title: "Go (programming language)",
+			url:   "https://en.wikipedia.org/wiki/Go_(programming_language)",
+			revid: 1211970426,
+			want: &Attribution{
+				Title:      "Go (programming language)",
+				URL:        "https://en.wikipedia.org/wiki/Go_(programming_language)",
+				Permalink:  "https://en.wikipedia.org/w/index.php?oldid=1211970426&title=Go_%28programming_language%29",
+				History:    "https://en.wikipedia.org/w/index.php?action=history&title=Go_%28programming_language%29",
+				License:    contentLicense,
+				LicenseURL: contentLicenseURL,
+			},
+		},
+		{
+			name:  "Without revision",
+			lang:  "fr",
+			title: "AT&T",
+			url:   "https://fr.wikipedia.org/wiki/AT%26T",
+			want: &Attribution{
+				Title:      "AT&T",
+				URL:        "https://fr.wikipedia.org/wiki/AT%26T",
+				History:    "https://fr.wikipedia.org/w/index.php?action=history&title=AT%26T",
+				License:    contentLicense,
+				LicenseURL: contentLicenseURL,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newAttribution(tt.lang, tt.title, tt.url, tt.revid))
+		})
+	}
+}
+
+func TestAttributionText(t *testing.T) {
+	a := newAttribution("en", "Go", "https://en.wikipedia.org/wiki/Go", 42)
+
+	assert.Equal(t, []string{
+		"Source: Go (https://en.wikipedia.org/wiki/Go)",
+		"Permalink: https://en.wikipedia.org/w/index.php?oldid=42&title=Go",
+		"Contributors: https://en.wikipedia.org/w/index.php?action=history&title=Go",
+		"License: CC BY-SA 4.0 (https://creativecommons.org/licenses/by-sa/4.0/)",
+	}, a.Lines())
+	assert.Equal(t, "Source: Go (https://en.wikipedia.org/wiki/Go); Permalink: https://en.wikipedia.org/w/index.php?oldid=42&title=Go; Contributors: https://en.wikipedia.org/w/index.php?action=history&title=Go; License: CC BY-SA 4.0 (https://creativecommons.org/licenses/by-sa/4.0/)", a.String())
+	assert.Equal(t, "Text from the Wikipedia article [Go](https://en.wikipedia.org/wiki/Go) ([permalink](https://en.wikipedia.org/w/index.php?oldid=42&title=Go)), by [its contributors](https://en.wikipedia.org/w/index.php?action=history&title=Go), available under the [CC BY-SA 4.0](https://creativecommons.org/licenses/by-sa/4.0/) license.", a.Markdown())
+
+	a.Permalink = ""
+	assert.Len(t, a.Lines(), 3)
+	assert.Equal(t, "Text from the Wikipedia article [Go](https://en.wikipedia.org/wiki/Go), by [its contributors](https://en.wikipedia.org/w/index.php?action=history&title=Go), available under the [CC BY-SA 4.0](https://creativecommons.org/licenses/by-sa/4.0/) license.", a.Markdown())
+}
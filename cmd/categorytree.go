@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// categoryNamespace is the namespace of the categories. ref: https://www.mediawiki.org/wiki/Manual:Namespace
+	categoryNamespace = 14
+
+	// maxTreeDepth is the maximum value of the 'depth' flag of the 'category-tree' command
+	maxTreeDepth = 10
+
+	// maxConcurrency is the maximum value of the 'concurrency' flag
+	maxConcurrency = 16
+)
+
+var (
+	treeDepth    int  // number of levels of members listed below the category
+	treeLimit    int  // maximum number of members listed per category, not limited when 0
+	concurrency  int  // maximum number of requests sent at the same time
+	withExtracts bool // whether or not to attach the summary of the articles
+
+	// categoryTreeCmd represents the 'category-tree' command
+	categoryTreeCmd = &cobra.Command{
+		Use:   "category-tree <category>",
+		Short: "List the subcategories and the articles of a category, recursively",
+		Long: `List the subcategories and the articles of the given Wikipedia category as a tree,
+down to the given depth: 1 lists the members of the category, 2 the members of its subcategories too, etc.
+The "Category:" prefix is added to the title when it has no namespace.
+
+A subcategory reached again, ie. through a cycle, is marked but its members aren't listed twice.
+With '--with-extracts', the articles come with the first sentence of their introduction, ie.
+  wpdia-go category-tree --depth 2 "Category:Programming languages"
+  wpdia-go category-tree --with-extracts --output markdown "Category:Go (programming language)"
+  wpdia-go category-tree --output dot "Category:Programming languages" | dot -Tsvg > tree.svg`,
+
+		PreRunE: validateCategoryTreeFlags,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			title := categoryTitle(args[0])
+
+			w, err := NewWikiClient(APIBaseURL, "")
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+
+			logger.Info("Crawling category...", slog.String("title", title), slog.Int("depth", treeDepth))
+
+			tree, err := crawlCategoryTree(w, title, treeDepth, treeLimit, concurrency)
+			if err != nil {
+				logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.String("title", title))
+				os.Exit(1)
+			}
+
+			if withExtracts {
+				logger.Info("Getting extracts...")
+
+				if err := attachExtracts(w, tree, concurrency); err != nil {
+					logger.Error(err.Error(), slog.String("url", APIBaseURL))
+					os.Exit(1)
+				}
+			}
+
+			if err := writeCategoryTree(os.Stdout, tree, output, header); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+// CategoryNode represents a category or an article of a category tree.
+type CategoryNode struct {
+	Pageid uint64 `json:"pageid,omitempty" yaml:"pageid,omitempty"`
+	Title  string `json:"title" yaml:"title"`
+
+	// Category is whether the page is a category, an article otherwise
+	Category bool `json:"category,omitempty" yaml:"category,omitempty"`
+
+	// Extract is the first sentence of the introduction of the article, with '--with-extracts'
+	Extract string `json:"extract,omitempty" yaml:"extract,omitempty"`
+
+	// Seen is whether the category is listed elsewhere in the tree, its members are then only listed there
+	Seen bool `json:"seen,omitempty" yaml:"seen,omitempty"`
+
+	Members []*CategoryNode `json:"members,omitempty" yaml:"members,omitempty"`
+}
+
+func init() {
+	categoryTreeCmd.Flags().IntVar(&treeDepth, "depth", 1, fmt.Sprintf("Number of levels of members to list below the category. Must be between 1 and %d.", maxTreeDepth))
+	categoryTreeCmd.Flags().IntVar(&treeLimit, "limit", defaultRefsLimit, "Maximum number of members to list per category. Not limited when 0.")
+	categoryTreeCmd.Flags().IntVar(&concurrency, "concurrency", 4, fmt.Sprintf("Maximum number of requests sent at the same time. Must be between 1 and %d.", maxConcurrency))
+	categoryTreeCmd.Flags().BoolVar(&withExtracts, "with-extracts", false, "Attach the first sentence of the introduction of the articles.")
+
+	rootCmd.AddCommand(categoryTreeCmd)
+}
+
+// validateCategoryTreeFlags will determine whether the flags of the 'category-tree' command are valid.
+func validateCategoryTreeFlags(cmd *cobra.Command, args []string) error {
+	if treeDepth < 1 || treeDepth > maxTreeDepth {
+		return fmt.Errorf("error: invalid value for flag 'depth': %d. Must be between 1 and %d", treeDepth, maxTreeDepth)
+	}
+	if treeLimit < 0 {
+		return fmt.Errorf("error: invalid value for flag 'limit': %d. Must be positive", treeLimit)
+	}
+	if concurrency < 1 || concurrency > maxConcurrency {
+		return fmt.Errorf("error: invalid value for flag 'concurrency': %d. Must be between 1 and %d", concurrency, maxConcurrency)
+	}
+
+	return validateListFlags(cmd, args, "dot")
+}
+
+// categoryTitle returns the given title with the "Category:" prefix when it has no namespace.
+// The underscores are replaced by spaces, as in the titles returned by the API.
+func categoryTitle(title string) string {
+	title = strings.ReplaceAll(strings.TrimSpace(title), "_", " ")
+	if !strings.Contains(title, ":") {
+		title = "Category:" + title
+	}
+
+	return title
+}
+
+// crawlCategoryTree will list the members of the category with the given title and of its subcategories,
+// down to depth levels below the category. The categories of a level are requested concurrently,
+// with at most concurrency requests at the same time, and at most limit members are listed per category.
+// A category already listed in the tree is marked as seen and its members aren't requested again,
+// which breaks the cycles.
+// It returns the tree or any error encountered.
+func crawlCategoryTree(w *WikiClient, title string, depth, limit, concurrency int) (*CategoryNode, error) {
+	root := &CategoryNode{Title: title, Category: true}
+	visited := map[string]bool{title: true}
+
+	level := []*CategoryNode{root}
+	for d := 0; d < depth && len(level) > 0; d++ {
+		logger.Debug("Listing category members...", slog.Int("level", d+1), slog.Int("categories", len(level)))
+
+		members := make([][]PageRef, len(level))
+		err := forEachConcurrently(len(level), concurrency, func(i int) error {
+			var err error
+			members[i], err = w.GetCategoryMembers(level[i].Title, limit)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// The tree is built once all the members of the level are known, so that it doesn't depend
+		// on the order of the responses
+		var next []*CategoryNode
+		for i, parent := range level {
+			for _, m := range members[i] {
+				n := &CategoryNode{Pageid: m.Pageid, Title: m.Title, Category: m.Ns == categoryNamespace}
+				if n.Category {
+					if visited[n.Title] {
+						n.Seen = true
+					} else {
+						visited[n.Title] = true
+						next = append(next, n)
+					}
+				}
+				parent.Members = append(parent.Members, n)
+			}
+		}
+		level = next
+	}
+
+	return root, nil
+}
+
+// attachExtracts will attach to the articles of the given tree the first sentence of their introduction.
+// The introductions are requested by batches of maxExtractsBatch articles, with at most concurrency
+// requests at the same time.
+// It returns any error encountered.
+func attachExtracts(w *WikiClient, tree *CategoryNode, concurrency int) error {
+	var ids []uint64
+	listed := map[uint64]bool{}
+	tree.walk(func(n *CategoryNode, _ int) {
+		if !n.Category && n.Pageid != 0 && !listed[n.Pageid] {
+			listed[n.Pageid] = true
+			ids = append(ids, n.Pageid)
+		}
+	})
+
+	batches := slices.Collect(slices.Chunk(ids, maxExtractsBatch))
+	extracts := make([]map[uint64]string, len(batches))
+	err := forEachConcurrently(len(batches), concurrency, func(i int) error {
+		var err error
+		extracts[i], err = w.GetExtracts(batches[i])
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	summaries := map[uint64]string{}
+	for _, e := range extracts {
+		for id, text := range e {
+			summaries[id] = firstSentences(lang, 1, text)
+		}
+	}
+
+	tree.walk(func(n *CategoryNode, _ int) {
+		if !n.Category {
+			n.Extract = summaries[n.Pageid]
+		}
+	})
+
+	return nil
+}
+
+// walk will call f with each node of the tree and its depth, the root being at depth 0, in depth-first order.
+func (n *CategoryNode) walk(f func(n *CategoryNode, depth int)) {
+	var visit func(n *CategoryNode, depth int)
+	visit = func(n *CategoryNode, depth int) {
+		f(n, depth)
+		for _, m := range n.Members {
+			visit(m, depth+1)
+		}
+	}
+	visit(n, 0)
+}
+
+// writeCategoryTree will write the given tree to w in the given output format.
+// The plain output is the tree indented by level, the markdown output a nested list of links
+// and the dot output a directed graph of the categories and their members for Graphviz.
+// The csv and tsv outputs have a row per page with its depth, starting with a row of the column names
+// when header is true.
+func writeCategoryTree(w io.Writer, tree *CategoryNode, format string, header bool) error {
+	if format == "dot" {
+		return writeCategoryTreeDot(w, tree)
+	}
+
+	t := Table{Columns: []string{"depth", "pageid", "title", "category", "seen", "extract"}}
+	tree.walk(func(n *CategoryNode, depth int) {
+		pageid := ""
+		if n.Pageid != 0 {
+			pageid = strconv.FormatUint(n.Pageid, 10)
+		}
+		t.Rows = append(t.Rows, []string{strconv.Itoa(depth), pageid, n.Title, boolField(n.Category), boolField(n.Seen), n.Extract})
+	})
+
+	// write writes a line per page, indented by level
+	write := func(w io.Writer, markdown bool) error {
+		var b strings.Builder
+		tree.walk(func(n *CategoryNode, depth int) {
+			indent := strings.Repeat("  ", depth)
+
+			text := n.Title
+			if markdown {
+				text = "- " + markdownLink(n.Title, articleURL(lang, n.Title))
+			}
+			if n.Seen {
+				text += " (already listed)"
+			}
+			if n.Extract != "" {
+				if markdown {
+					text += ": " + n.Extract
+				} else {
+					text += "\n" + indent + "  " + n.Extract
+				}
+			}
+
+			b.WriteString(indent + text + "\n")
+		})
+
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	return writeList(w, List{
+		Title:    tree.Title,
+		Value:    tree,
+		Table:    t,
+		Plain:    func(w io.Writer) error { return write(w, false) },
+		Markdown: func(w io.Writer) error { return write(w, true) },
+	}, format, header)
+}
+
+// writeCategoryTreeDot will write the given tree to w as a Graphviz directed graph.
+// The categories are boxes, the articles ellipses with their extract as tooltip.
+// A category listed several times is a single node, so the cycles are kept.
+func writeCategoryTreeDot(w io.Writer, tree *CategoryNode) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(tree.Title))
+	b.WriteString("\trankdir=LR;\n")
+
+	declared := map[string]bool{}
+	var visit func(n *CategoryNode)
+	visit = func(n *CategoryNode) {
+		if !declared[n.Title] {
+			declared[n.Title] = true
+
+			attrs := []string{"URL=" + dotQuote(articleURL(lang, n.Title))}
+			if n.Category {
+				attrs = append(attrs, "shape=box")
+			}
+			if n.Extract != "" {
+				attrs = append(attrs, "tooltip="+dotQuote(n.Extract))
+			}
+			fmt.Fprintf(&b, "\t%s [%s];\n", dotQuote(n.Title), strings.Join(attrs, ", "))
+		}
+
+		for _, m := range n.Members {
+			visit(m)
+			fmt.Fprintf(&b, "\t%s -> %s;\n", dotQuote(n.Title), dotQuote(m.Title))
+		}
+	}
+	visit(tree)
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote returns s as a Graphviz quoted string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
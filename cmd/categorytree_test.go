@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStubCategoryAPI starts a http server serving a synthetic category graph, with a cycle between
+// "Category:Programming languages" and "Category:Concurrent programming languages", and the extracts of its articles.
+// The members of "Category:Programming languages" are paginated.
+func newStubCategoryAPI(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		q := r.URL.Query()
+
+		if q.Get("prop") == "extracts" {
+			pages := []string{}
+			for _, id := range strings.Split(q.Get("pageids"), "|") {
+				pages = append(pages, fmt.Sprintf(`"%[1]s":{"pageid":%[1]s,"ns":0,"title":"Page %[1]s","extract":"Page %[1]s is a language. It is used."}`, id))
+			}
+			fmt.Fprintf(w, `{"batchcomplete":"","query":{"pages":{%s}}}`, strings.Join(pages, ","))
+			return
+		}
+
+		assert.Equal(t, "categorymembers", q.Get("list"))
+
+		switch q.Get("cmtitle") + q.Get("cmcontinue") {
+		case "Category:Programming languages":
+			fmt.Fprint(w, `{"continue":{"cmcontinue":"page|474f|2","continue":"-||"},"query":{"categorymembers":[{"pageid":1,"ns":0,"title":"Go"},{"pageid":100,"ns":14,"title":"Category:Concurrent programming languages"}]}}`)
+		case "Category:Programming languagespage|474f|2":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"categorymembers":[{"pageid":2,"ns":0,"title":"Python"}]}}`)
+		case "Category:Concurrent programming languages":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"categorymembers":[{"pageid":1,"ns":0,"title":"Go"},{"pageid":101,"ns":14,"title":"Category:Erlang"},{"pageid":102,"ns":14,"title":"Category:Programming languages"}]}}`)
+		case "Category:Erlang":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"categorymembers":[{"pageid":3,"ns":0,"title":"Erlang"}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"categorymembers":[]}}`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestCrawlCategoryTree(t *testing.T) {
+	var requests atomic.Int32
+	ts := newStubCategoryAPI(t, &requests)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	concurrent := &CategoryNode{Pageid: 100, Title: "Category:Concurrent programming languages", Category: true}
+
+	tests := []struct {
+		name     string
+		depth    int
+		members  func() []*CategoryNode
+		requests int32
+	}{
+		{
+			name:  "Depth 1",
+			depth: 1,
+			members: func() []*CategoryNode {
+				return []*CategoryNode{{Pageid: 1, Title: "Go"}, concurrent, {Pageid: 2, Title: "Python"}}
+			},
+			requests: 2,
+		},
+		{
+			name:  "Depth 2 with a cycle",
+			depth: 2,
+			members: func() []*CategoryNode {
+				c := *concurrent
+				c.Members = []*CategoryNode{
+					{Pageid: 1, Title: "Go"},
+					{Pageid: 101, Title: "Category:Erlang", Category: true},
+					{Pageid: 102, Title: "Category:Programming languages", Category: true, Seen: true},
+				}
+				return []*CategoryNode{{Pageid: 1, Title: "Go"}, &c, {Pageid: 2, Title: "Python"}}
+			},
+			requests: 3,
+		},
+		{
+			name:  "Depth beyond the tree",
+			depth: 5,
+			members: func() []*CategoryNode {
+				c := *concurrent
+				c.Members = []*CategoryNode{
+					{Pageid: 1, Title: "Go"},
+					{Pageid: 101, Title: "Category:Erlang", Category: true, Members: []*CategoryNode{{Pageid: 3, Title: "Erlang"}}},
+					{Pageid: 102, Title: "Category:Programming languages", Category: true, Seen: true},
+				}
+				return []*CategoryNode{{Pageid: 1, Title: "Go"}, &c, {Pageid: 2, Title: "Python"}}
+			},
+			requests: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+
+			got, err := crawlCategoryTree(w, "Category:Programming languages", tt.depth, 0, 2)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &CategoryNode{Title: "Category:Programming languages", Category: true, Members: tt.members()}, got)
+			assert.Equal(t, tt.requests, requests.Load())
+		})
+	}
+}
+
+func TestAttachExtracts(t *testing.T) {
+	var requests atomic.Int32
+	ts := newStubCategoryAPI(t, &requests)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+	lang = "en"
+
+	tree := &CategoryNode{Title: "Category:Languages", Category: true}
+	for i := range maxExtractsBatch + 5 {
+		tree.Members = append(tree.Members, &CategoryNode{Pageid: uint64(i + 1), Title: fmt.Sprintf("Page %d", i+1)})
+	}
+	// The articles listed several times are requested once
+	tree.Members = append(tree.Members, &CategoryNode{Title: "Category:Go", Category: true, Members: []*CategoryNode{{Pageid: 1, Title: "Page 1"}}})
+
+	assert.NoError(t, attachExtracts(w, tree, 2))
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, "Page 1 is a language.", tree.Members[0].Extract)
+	assert.Equal(t, "Page 25 is a language.", tree.Members[24].Extract)
+	assert.Equal(t, "Page 1 is a language.", tree.Members[25].Members[0].Extract)
+	assert.Empty(t, tree.Members[25].Extract)
+}
+
+func TestWriteCategoryTree(t *testing.T) {
+	lang = "en"
+
+	tree := &CategoryNode{Title: "Category:Programming languages", Category: true, Members: []*CategoryNode{
+		{Pageid: 1, Title: "Go", Extract: "Go is a language."},
+		{Pageid: 100, Title: "Category:Concurrent programming languages", Category: true, Members: []*CategoryNode{
+			{Pageid: 3, Title: "Erlang"},
+			{Pageid: 102, Title: "Category:Programming languages", Category: true, Seen: true},
+		}},
+	}}
+
+	tests := []struct {
+		name   string
+		tree   *CategoryNode
+		format string
+		want   string
+	}{
+		{
+			name:   "Plain",
+			tree:   tree,
+			format: "plain",
+			want: `Category:Programming languages
+  Go
+    Go is a language.
+  Category:Concurrent programming languages
+    Erlang
+    Category:Programming languages (already listed)
+`,
+		},
+		{
+			name:   "Markdown",
+			tree:   tree,
+			format: "markdown",
+			want: `- [Category:Programming languages](https://en.wikipedia.org/wiki/Category:Programming_languages)
+  - [Go](https://en.wikipedia.org/wiki/Go): Go is a language.
+  - [Category:Concurrent programming languages](https://en.wikipedia.org/wiki/Category:Concurrent_programming_languages)
+    - [Erlang](https://en.wikipedia.org/wiki/Erlang)
+    - [Category:Programming languages](https://en.wikipedia.org/wiki/Category:Programming_languages) (already listed)
+`,
+		},
+		{
+			name:   "JSON",
+			tree:   tree.Members[1].Members[1],
+			format: "json",
+			want:   "{\n    \"pageid\": 102,\n    \"title\": \"Category:Programming languages\",\n    \"category\": true,\n    \"seen\": true\n}\n",
+		},
+		{
+			name:   "YAML",
+			tree:   &CategoryNode{Title: "Category:Go", Category: true, Members: []*CategoryNode{{Pageid: 1, Title: "Go"}}},
+			format: "yaml",
+			want:   "title: Category:Go\ncategory: true\nmembers:\n- pageid: 1\n  title: Go\n",
+		},
+		{
+			name:   "DOT",
+			tree:   tree,
+			format: "dot",
+			want: `digraph "Category:Programming languages" {
+	rankdir=LR;
+	"Category:Programming languages" [URL="https://en.wikipedia.org/wiki/Category:Programming_languages", shape=box];
+	"Go" [URL="https://en.wikipedia.org/wiki/Go", tooltip="Go is a language."];
+	"Category:Programming languages" -> "Go";
+	"Category:Concurrent programming languages" [URL="https://en.wikipedia.org/wiki/Category:Concurrent_programming_languages", shape=box];
+	"Erlang" [URL="https://en.wikipedia.org/wiki/Erlang"];
+	"Category:Concurrent programming languages" -> "Erlang";
+	"Category:Concurrent programming languages" -> "Category:Programming languages";
+	"Category:Programming languages" -> "Category:Concurrent programming languages";
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writeCategoryTree(w, tt.tree, tt.format, false)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+}
+
+func TestCategoryTitle(t *testing.T) {
+	assert.Equal(t, "Category:Programming languages", categoryTitle("Programming languages"))
+	assert.Equal(t, "Category:Programming languages", categoryTitle(" Category:Programming_languages "))
+	assert.Equal(t, "Catégorie:Langage de programmation", categoryTitle("Catégorie:Langage de programmation"))
+}
+
+func TestDotQuote(t *testing.T) {
+	assert.Equal(t, `"Go"`, dotQuote("Go"))
+	assert.Equal(t, `"The \"Go\" \\ language\nby Google"`, dotQuote("The \"Go\" \\ language\nby Google"))
+}
+
+func TestValidateCategoryTreeFlags(t *testing.T) {
+	logLevel, logFormat = "error", "text"
+	t.Cleanup(func() {
+		output, templateText, treeDepth, treeLimit, concurrency = "plain", "", 1, defaultRefsLimit, 4
+	})
+
+	treeDepth, treeLimit, concurrency = 1, defaultRefsLimit, 4
+	templateText = "{{.}}"
+	for _, o := range slices.Concat(listOutputs, []string{"dot"}) {
+		output = o
+		assert.NoError(t, validateCategoryTreeFlags(categoryTreeCmd, nil), o)
+	}
+
+	output = "graphml"
+	assert.Error(t, validateCategoryTreeFlags(categoryTreeCmd, nil))
+
+	output, treeDepth = "plain", maxTreeDepth+1
+	assert.Error(t, validateCategoryTreeFlags(categoryTreeCmd, nil))
+
+	treeDepth, treeLimit = 2, -1
+	assert.Error(t, validateCategoryTreeFlags(categoryTreeCmd, nil))
+
+	treeLimit, concurrency = 0, 0
+	assert.Error(t, validateCategoryTreeFlags(categoryTreeCmd, nil))
+}
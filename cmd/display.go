@@ -1,26 +1,65 @@
 package cmd
 
 import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	htmltemplate "html/template"
 	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/charmbracelet/glamour"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/termenv"
 	"gopkg.in/yaml.v2"
 )
 
 // Displayer offers function to display a page
 // using different formatters.
 type Displayer interface {
-	// Write will write the content of a page
-	// to the given io.Writer
-	Write(w io.Writer, p *Page, full bool) error
+	// Write will write the selected fields of the view of a page
+	// to the given io.Writer.
+	// Implementations must not modify the view, so that it can be
+	// rendered by several Displayers.
+	Write(w io.Writer, v *PageView) error
 }
 
-type plainFormat struct{}
+type plainFormat struct {
+	wrap int
+}
 
 type prettyFormat struct {
 	wordWrap int
+	theme    string
+	noColor  bool
+	pager    string
+
+	// terminal returns the size of the terminal the output is written to,
+	// ok being false when it isn't written to a terminal
+	terminal func(w io.Writer) (width, height int, ok bool)
+}
+
+// PrettyOptions represents the options of the 'pretty' output.
+type PrettyOptions struct {
+	// Width is the width the output is wrapped at.
+	// When <= 0, it is the width of the terminal, or 100 when not writing to a terminal.
+	Width int
+
+	// Theme is either one of validThemes or the path to a glamour JSON style file.
+	// Defaults to "auto", which picks the dark or light theme depending on the terminal background.
+	Theme string
+
+	// NoColor disables the colors, ie. when the NO_COLOR environment variable is set
+	NoColor bool
+
+	// Pager is the command the output longer than the terminal is written through.
+	// The output is never paged when empty, or when not writing to a terminal.
+	Pager string
 }
 
 type jsonFormat struct {
@@ -30,106 +69,312 @@ type jsonFormat struct {
 
 type yamlFormat struct{}
 
-func NewPlainFormat() *plainFormat {
-	return &plainFormat{}
+type markdownFormat struct {
+	frontMatter bool
+}
+
+type templateFormat struct {
+	tmpl *template.Template
+}
+
+type ndjsonFormat struct{}
+
+// delimitedFormat is a formatter writing one record per page,
+// with the values separated by a delimiter, ie. CSV or TSV.
+type delimitedFormat struct {
+	comma  rune
+	header bool
+
+	// headerWritten is true once the header row has been written,
+	// so it is written only once when rendering several pages
+	headerWritten bool
+}
+
+type htmlFormat struct{}
+
+// htmlPageTemplate is the template of the standalone HTML document of the 'html' output,
+// with its light, dark and print stylesheets
+//
+//go:embed assets/page.html.tmpl
+var htmlPageTemplate string
+
+// htmlPage is the parsed template of the 'html' output
+var htmlPage = htmltemplate.Must(htmltemplate.New("page").Funcs(htmltemplate.FuncMap{
+	"heading": htmlHeading,
+}).Parse(htmlPageTemplate))
+
+// viewLabels represents the labels of the fields of a view, in the order they are displayed
+// by the plain and pretty outputs. The title and the extract are displayed apart.
+var viewLabels = []struct {
+	field string
+	label string
+}{
+	{"ns", "Ns"},
+	{"pageid", "Pageid"},
+	{"short_description", "WikiBase Short Description"},
+	{"wikidata_item", "WikiBase Item"},
+	{"url", "URL"},
+	{"coordinates", "Coordinates"},
+	{"lang", "Lang"},
+	{"disambiguation", "Disambiguation"},
+	{"display_title", "Display Title"},
+	{"length", "Length"},
+	{"touched", "Touched"},
+	{"last_revid", "Last Revision ID"},
+	{"last_rev_timestamp", "Last Revision Timestamp"},
+	{"last_rev_user", "Last Revision User"},
+	{"protection", "Protection"},
+	{"watchers", "Watchers"},
+}
+
+// markdownFrontMatter represents the YAML front matter of the markdown output
+type markdownFrontMatter struct {
+	Title       string    `yaml:"title"`
+	Pageid      *int      `yaml:"pageid,omitempty"`
+	Wikidata    string    `yaml:"wikidata,omitempty"`
+	Lang        string    `yaml:"lang"`
+	Source      string    `yaml:"source"`
+	Coordinates []float64 `yaml:"coordinates,omitempty,flow"`
+	Revision    int       `yaml:"revision,omitempty"`
+	LastEdited  string    `yaml:"last_edited,omitempty"`
+	FetchedAt   string    `yaml:"fetched_at,omitempty"`
+	License     string    `yaml:"license"`
+	LicenseURL  string    `yaml:"license_url"`
 }
 
-func (d *plainFormat) Write(w io.Writer, p *Page, full bool) error {
-	_, err := fmt.Fprintf(w, "Title:\n  %s\n\n", p.Title)
+// NewPlainFormat creates a formatter writing each field under its label,
+// every line of the values being indented and wrapped at the given width.
+// The values aren't wrapped when wrap <= 0.
+func NewPlainFormat(wrap int) *plainFormat {
+	return &plainFormat{wrap: wrap}
+}
+
+func (d *plainFormat) Write(w io.Writer, v *PageView) error {
+	prefix := "  "
+	// The lines of right-to-left languages start with a right-to-left mark,
+	// so that the terminals implementing the bidirectional algorithm display them right-to-left
+	// even when they start with a left-to-right word, ie. a latin name
+	if isRTL(v.Lang) {
+		prefix += rlm
+	}
+
+	_, err := fmt.Fprintf(w, "Title:\n%s\n\n", indent(prefix, d.wrap, v.Title))
 	if err != nil {
 		return err
 	}
 
-	if full {
-		_, err := fmt.Fprintf(w, "Ns:\n  %d\n\n", *p.Ns)
+	for _, l := range viewLabels {
+		if !v.hasValue(l.field) {
+			continue
+		}
+
+		_, err = fmt.Fprintf(w, "%s:\n%s\n\n", l.label, indent(prefix, d.wrap, fmt.Sprint(v.Field(l.field))))
 		if err != nil {
 			return err
 		}
+	}
 
-		_, err = fmt.Fprintf(w, "Pageid:\n  %d\n\n", *p.Pageid)
+	if v.Wikidata != nil {
+		_, err = fmt.Fprintf(w, "Wikidata:\n%s\n\n", indent(prefix, d.wrap, wikidataText(v.Wikidata)))
 		if err != nil {
 			return err
 		}
+	}
 
-		_, err = fmt.Fprintf(w, "WikiBase Short Description:\n  %s\n\n", p.PageProps.WikiBaseShortDesc)
+	if v.Has("infobox") && v.Infobox != nil {
+		_, err = fmt.Fprintf(w, "Infobox:\n%s\n\n", indent(prefix, d.wrap, infoboxText(v.Infobox)))
 		if err != nil {
 			return err
 		}
+	}
 
-		_, err = fmt.Fprintf(w, "WikiBase Item:\n  %s\n\n", p.PageProps.WikiBaseItem)
+	if v.Has("extract") {
+		_, err = fmt.Fprintf(w, "Extract:\n%s", indent(prefix, d.wrap, v.Extract))
 		if err != nil {
 			return err
 		}
-
 	}
 
-	_, err = fmt.Fprintf(w, "Extract:\n  %s", p.Extract)
-	if err != nil {
-		return err
+	if v.Has("attribution") {
+		// The extract is the only block not followed by an empty line, nor ended by a newline
+		if v.Has("extract") {
+			fmt.Fprint(w, "\n\n")
+		}
+
+		_, err = fmt.Fprintf(w, "Attribution:\n%s\n", indent(prefix, d.wrap, strings.Join(v.Attribution.Lines(), "\n")))
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func NewPrettyFormat(wordWrap int) *prettyFormat {
-	// set wordWrap to 100 by default
-	if wordWrap <= 0 {
-		wordWrap = 100
+func NewPrettyFormat(opts PrettyOptions) *prettyFormat {
+	// wordWrap <= 0 means the width of the terminal
+	theme := opts.Theme
+	if theme == "" {
+		theme = "auto"
 	}
 
-	return &prettyFormat{wordWrap: wordWrap}
+	return &prettyFormat{
+		wordWrap: opts.Width,
+		theme:    theme,
+		noColor:  opts.NoColor,
+		pager:    opts.Pager,
+		terminal: terminalSize,
+	}
 }
 
-func (d *prettyFormat) Write(w io.Writer, p *Page, full bool) error {
-	r, err := glamour.NewTermRenderer(
-		// detect background color and pick either the default dark or light theme
-		glamour.WithAutoStyle(),
-		// wrap output at specific width
-		glamour.WithWordWrap(d.wordWrap),
-	)
+func (d *prettyFormat) Write(w io.Writer, v *PageView) error {
+	r, tty, height, err := d.renderer(w)
 	if err != nil {
 		return err
 	}
 
-	out, err := r.Render("## " + p.Title)
+	var b bytes.Buffer
+
+	// The title and the "read more" link are hyperlinks to the article in the terminals
+	out, err := renderLink(r, "## ", v.Title, v.URL, tty)
 	if err != nil {
 		return err
 	}
-	fmt.Fprint(w, out)
+	b.WriteString(out)
 
-	if full {
-		out, err := r.Render("### Namespace" + fmt.Sprintf("\nNs: %d", *p.Ns))
+	for _, l := range viewLabels {
+		if !v.hasValue(l.field) {
+			continue
+		}
+
+		out, err := r.Render(fmt.Sprintf("### %s\n%v", l.label, v.Field(l.field)))
 		if err != nil {
 			return err
 		}
-		fmt.Fprint(w, out)
+		b.WriteString(out)
+	}
 
-		out, err = r.Render("### Page ID " + fmt.Sprintf("\nPageid: %d", *p.Pageid))
+	if v.Wikidata != nil {
+		out, err = r.Render("### Wikidata\n" + wikidataMarkdown(v.Wikidata))
 		if err != nil {
 			return err
 		}
-		fmt.Fprint(w, out)
+		b.WriteString(out)
+	}
 
-		out, err = r.Render("### WikiBase Short Description" + fmt.Sprintf("\nNs: %s", p.PageProps.WikiBaseShortDesc))
+	if v.Has("infobox") && v.Infobox != nil {
+		out, err = r.Render("### Infobox\n" + infoboxMarkdown(v.Infobox))
 		if err != nil {
 			return err
 		}
-		fmt.Fprint(w, out)
+		b.WriteString(out)
+	}
+
+	if v.Has("extract") {
+		out, err = r.Render(fmt.Sprintf("### Extract\n%s", v.Extract))
+		if err != nil {
+			return err
+		}
+		b.WriteString(out)
+	}
 
-		out, err = r.Render("### WikiBase Item" + fmt.Sprintf("\nNs: %s", p.PageProps.WikiBaseItem))
+	if v.Has("attribution") {
+		out, err = r.Render("### Attribution\n" + v.Attribution.Markdown())
 		if err != nil {
 			return err
 		}
-		fmt.Fprint(w, out)
+		b.WriteString(out)
 	}
 
-	out, err = r.Render(fmt.Sprintf("### Extract\n%s", p.Extract))
+	if v.URL != "" {
+		out, err = renderLink(r, "Read more: ", v.URL, v.URL, tty)
+		if err != nil {
+			return err
+		}
+		b.WriteString(out)
+	}
+
+	return d.flush(w, b.Bytes(), tty, height)
+}
+
+// writeMarkdown will render the given markdown document to w.
+func (d *prettyFormat) writeMarkdown(w io.Writer, markdown string) error {
+	r, tty, height, err := d.renderer(w)
 	if err != nil {
 		return err
 	}
-	fmt.Fprint(w, out)
 
-	return nil
+	out, err := r.Render(markdown)
+	if err != nil {
+		return err
+	}
+
+	return d.flush(w, []byte(out), tty, height)
+}
+
+// renderer returns the renderer of the output written to w,
+// along with whether w is a terminal and the height of the terminal.
+func (d *prettyFormat) renderer(w io.Writer) (*glamour.TermRenderer, bool, int, error) {
+	width, height, tty := d.terminal(w)
+
+	wordWrap := d.wordWrap
+	if wordWrap <= 0 {
+		wordWrap = 100
+		if tty && width > 0 {
+			wordWrap = width
+		}
+	}
+
+	opts := []glamour.TermRendererOption{
+		// either a builtin theme, "auto" detecting the background color, or a JSON style file
+		glamour.WithStylePath(d.theme),
+		// wrap output at specific width
+		glamour.WithWordWrap(wordWrap),
+	}
+	if d.noColor {
+		opts = append(opts, glamour.WithColorProfile(termenv.Ascii))
+	}
+
+	r, err := glamour.NewTermRenderer(opts...)
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	return r, tty, height, nil
+}
+
+// flush will write the rendered output b to w.
+// Long outputs go through the pager, only in terminals.
+func (d *prettyFormat) flush(w io.Writer, b []byte, tty bool, height int) error {
+	if tty && d.pager != "" && height > 0 && bytes.Count(b, []byte("\n")) > height {
+		return writePaged(d.pager, w, b)
+	}
+
+	_, err := w.Write(b)
+	return err
+}
+
+// renderLink will render the markdown prefix followed by text,
+// text being a hyperlink to url when hyperlinks is true.
+// As the escape sequences of the hyperlinks would be broken by the word wrapping,
+// a placeholder of the same width is rendered and then replaced with the hyperlink.
+// The text isn't a hyperlink if the placeholder has been wrapped.
+func renderLink(r *glamour.TermRenderer, prefix, text, url string, hyperlinks bool) (string, error) {
+	if !hyperlinks || url == "" {
+		return r.Render(prefix + text)
+	}
+
+	placeholder := strings.Repeat("x", runewidth.StringWidth(text))
+
+	out, err := r.Render(prefix + placeholder)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Count(out, placeholder) != 1 {
+		return r.Render(prefix + text)
+	}
+
+	return strings.Replace(out, placeholder, hyperlink(url, text), 1), nil
 }
 
 func NewJsonFormat(prefix, indent string) *jsonFormat {
@@ -139,18 +384,8 @@ func NewJsonFormat(prefix, indent string) *jsonFormat {
 	}
 }
 
-func (d *jsonFormat) Write(w io.Writer, p *Page, full bool) error {
-	// Nullify these fields if not requesting the full output
-	if !full {
-		var n *int
-		var i *int
-		p.Ns = n
-		p.Pageid = i
-
-		p.PageProps = nil
-	}
-
-	b, err := json.MarshalIndent(p, d.prefix, d.indent)
+func (d *jsonFormat) Write(w io.Writer, v *PageView) error {
+	b, err := json.MarshalIndent(v.Document(), d.prefix, d.indent)
 	if err != nil {
 		return err
 	}
@@ -164,22 +399,399 @@ func NewYamlFormat() *yamlFormat {
 	return &yamlFormat{}
 }
 
-func (d *yamlFormat) Write(w io.Writer, p *Page, full bool) error {
-	// Nullify these fields if not requesting the full output
-	if !full {
-		var n *int
-		var i *int
-		p.Ns = n
-		p.Pageid = i
+func (d *yamlFormat) Write(w io.Writer, v *PageView) error {
+	out, err := yaml.Marshal(v.Document())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s\n", string(out))
 
-		p.PageProps = nil
+	return nil
+}
+
+func NewMarkdownFormat(frontMatter bool) *markdownFormat {
+	return &markdownFormat{frontMatter: frontMatter}
+}
+
+func (d *markdownFormat) Write(w io.Writer, v *PageView) error {
+	if d.frontMatter {
+		fm := markdownFrontMatter{
+			Title:      v.Title,
+			Wikidata:   v.WikidataItem,
+			Lang:       v.Lang,
+			Source:     v.URL,
+			Revision:   v.LastRevID,
+			LastEdited: formatTimestamp(v.LastRevTimestamp),
+			License:    contentLicense,
+			LicenseURL: contentLicenseURL,
+		}
+		if v.Pageid != 0 {
+			fm.Pageid = &v.Pageid
+		}
+		if v.Has("coordinates") && v.Coordinates != nil {
+			fm.Coordinates = []float64{v.Coordinates.Lat, v.Coordinates.Lon}
+		}
+		if !v.FetchedAt.IsZero() {
+			fm.FetchedAt = v.FetchedAt.Format(time.RFC3339)
+		}
+
+		out, err := yaml.Marshal(&fm)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "---\n%s---\n\n", out)
+		if err != nil {
+			return err
+		}
 	}
 
-	out, err := yaml.Marshal(&p)
+	_, err := fmt.Fprintf(w, "# %s\n\n", v.Title)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(w, "%s\n", string(out))
+
+	// Unless the fields have been selected, the document has a short description and a source
+	layout := func(field string) bool {
+		return v.Has(field) || !v.selected
+	}
+
+	if layout("short_description") && v.ShortDescription != "" {
+		_, err = fmt.Fprintf(w, "> %s\n\n", v.ShortDescription)
+		if err != nil {
+			return err
+		}
+	}
+
+	if extract := extractToMarkdown(v.rawExtract()); v.Has("extract") && extract != "" {
+		_, err = fmt.Fprintf(w, "%s\n\n", extract)
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.Wikidata != nil {
+		_, err = fmt.Fprintf(w, "## Wikidata\n\n%s\n", wikidataMarkdown(v.Wikidata))
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.Has("infobox") && v.Infobox != nil {
+		_, err = fmt.Fprintf(w, "## Infobox\n\n%s\n", infoboxMarkdown(v.Infobox))
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.Has("coordinates") && v.Coordinates != nil {
+		_, err = fmt.Fprintf(w, "Coordinates: %s\n\n", coordinatesText(v.Coordinates))
+		if err != nil {
+			return err
+		}
+	}
+
+	if layout("url") {
+		_, err = fmt.Fprintf(w, "Source: [%s](%s)\n", v.Title, v.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	if text := lastEditText(v); text != "" {
+		_, err = fmt.Fprintf(w, "\n%s\n", text)
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.Has("attribution") {
+		_, err = fmt.Fprintf(w, "\n%s\n", v.Attribution.Markdown())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lastEditText returns when and by whom the page has been last edited, ie.
+// "Last edited on 2024-03-05T10:00:00Z by Gopher (revision 1211970426)".
+// It returns an empty string when the last revision isn't a selected field or is unknown.
+func lastEditText(v *PageView) string {
+	if !v.Has("last_rev_timestamp") || v.LastRevTimestamp.IsZero() {
+		return ""
+	}
+
+	text := "Last edited on " + formatTimestamp(v.LastRevTimestamp)
+	if v.LastRevUser != "" {
+		text += " by " + v.LastRevUser
+	}
+	if v.LastRevID != 0 {
+		text += fmt.Sprintf(" (revision %d)", v.LastRevID)
+	}
+
+	return text
+}
+
+// wikidataText returns the Wikidata entity as text: its label and id,
+// followed by a "label: values" line for each of its claims.
+func wikidataText(d *Wikidata) string {
+	lines := []string{d.ID}
+	if d.Label != "" {
+		lines[0] = fmt.Sprintf("%s (%s)", d.Label, d.ID)
+	}
+
+	for _, c := range d.Claims {
+		lines = append(lines, fmt.Sprintf("%s: %s", c.Label, strings.Join(c.Values, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// wikidataMarkdown returns the Wikidata entity as a markdown table,
+// with a link to the entity in the header and a row for each of its claims.
+func wikidataMarkdown(d *Wikidata) string {
+	escape := strings.NewReplacer("|", "\\|", "\n", " ").Replace
+
+	label := d.ID
+	if d.Label != "" {
+		label = d.Label
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "| %s | [%s](%s) |\n| --- | --- |\n", escape(label), d.ID, d.URL)
+	for _, c := range d.Claims {
+		fmt.Fprintf(&b, "| %s | %s |\n", escape(c.Label), escape(strings.Join(c.Values, ", ")))
+	}
+
+	return b.String()
+}
+
+// infoboxText returns the infobox as text, with a "key: value" line for each of its fields.
+func infoboxText(i *Infobox) string {
+	lines := make([]string, 0, len(i.Fields))
+	for _, f := range i.Fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", f.Key, f.Value))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// infoboxMarkdown returns the infobox as a markdown table, with a row for each of its fields.
+func infoboxMarkdown(i *Infobox) string {
+	escape := strings.NewReplacer("|", "\\|", "\n", " ").Replace
+
+	var b strings.Builder
+	b.WriteString("| Field | Value |\n| --- | --- |\n")
+	for _, f := range i.Fields {
+		fmt.Fprintf(&b, "| %s | %s |\n", escape(f.Key), escape(f.Value))
+	}
+
+	return b.String()
+}
+
+// extractToMarkdown will convert a text extract to markdown:
+// each line of the extract is a paragraph and the wikitext-style
+// section headings ("== History ==") become markdown headings ("## History").
+func extractToMarkdown(extract string) string {
+	var paragraphs []string
+
+	for _, b := range extractBlocks(extract) {
+		if b.Level > 0 {
+			paragraphs = append(paragraphs, strings.Repeat("#", b.Level)+" "+b.Text)
+		} else {
+			paragraphs = append(paragraphs, b.Text)
+		}
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// NewTemplateFormat creates a formatter rendering the given user-defined Go template
+// against the view model of the page.
+// It returns an error if the template can't be parsed.
+func NewTemplateFormat(text string) (*templateFormat, error) {
+	tmpl, err := newTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &templateFormat{tmpl: tmpl}, nil
+}
+
+func (d *templateFormat) Write(w io.Writer, v *PageView) error {
+	return d.execute(w, v, v.Lang)
+}
+
+// execute will render the template against data to w, splitting the sentences in the given language.
+func (d *templateFormat) execute(w io.Writer, data any, lang string) error {
+	tmpl, err := d.tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(templateFuncs(lang))
+
+	var b bytes.Buffer
+
+	err = tmpl.Execute(&b, data)
+	if err != nil {
+		return err
+	}
+
+	// Always end the output with a new line
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteString("\n")
+	}
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// NewNdjsonFormat creates a formatter writing each page as a single line JSON object.
+// The object is the same as the one of the 'json' output, unless the fields have been selected
+// by the user: it is then a flat object of the fields, in their order.
+func NewNdjsonFormat() *ndjsonFormat {
+	return &ndjsonFormat{}
+}
+
+func (d *ndjsonFormat) Write(w io.Writer, v *PageView) error {
+	var b []byte
+	var err error
+
+	if v.selected {
+		b, err = marshalFields(v, v.Fields)
+	} else {
+		b, err = json.Marshal(v.Document())
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// marshalFields will encode the given fields of the view as a JSON object,
+// keeping the keys in the order of the fields.
+func marshalFields(v *PageView, fields []string) ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteString("{")
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteString(",")
+		}
+
+		key, err := json.Marshal(field)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(v.Field(field))
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(value)
+	}
+	b.WriteString("}")
+
+	return b.Bytes(), nil
+}
+
+// NewCsvFormat creates a formatter writing each page as a CSV record,
+// optionally preceded by a header row with the field names.
+func NewCsvFormat(header bool) *delimitedFormat {
+	return &delimitedFormat{
+		comma:  ',',
+		header: header,
+	}
+}
+
+// NewTsvFormat creates a formatter writing each page as a TSV record,
+// optionally preceded by a header row with the field names.
+func NewTsvFormat(header bool) *delimitedFormat {
+	return &delimitedFormat{
+		comma:  '\t',
+		header: header,
+	}
+}
+
+func (d *delimitedFormat) Write(w io.Writer, v *PageView) error {
+	var records [][]string
+	if d.header && !d.headerWritten {
+		records = append(records, slices.Clone(v.Fields))
+	}
+
+	record := make([]string, 0, len(v.Fields))
+	for _, field := range v.Fields {
+		record = append(record, fmt.Sprint(v.Field(field)))
+	}
+	records = append(records, record)
+
+	if err := writeDelimited(w, records, d.comma); err != nil {
+		return err
+	}
+
+	d.headerWritten = true
 
 	return nil
 }
+
+// writeDelimited will write the given records to w, with the values separated by comma.
+func writeDelimited(w io.Writer, records [][]string, comma rune) error {
+	if comma != '\t' {
+		cw := csv.NewWriter(w)
+		cw.Comma = comma
+		return cw.WriteAll(records)
+	}
+
+	// TSV doesn't have any quoting mechanism,
+	// tabs and new lines are escaped instead
+	for _, r := range records {
+		fields := make([]string, len(r))
+		for i := range r {
+			fields[i] = tsvEscaper.Replace(r[i])
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tsvEscaper escapes the characters which can't be part of a TSV field
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+func NewHtmlFormat() *htmlFormat {
+	return &htmlFormat{}
+}
+
+func (d *htmlFormat) Write(w io.Writer, v *PageView) error {
+	return htmlPage.Execute(w, struct {
+		Page       *PageView
+		Blocks     []extractBlock
+		LastEdit   string
+		Version    string
+		License    string
+		LicenseURL string
+	}{
+		Page:       v,
+		Blocks:     extractBlocks(v.rawExtract()),
+		LastEdit:   lastEditText(v),
+		Version:    version,
+		License:    contentLicense,
+		LicenseURL: contentLicenseURL,
+	})
+}
+
+// htmlHeading returns the HTML heading of the given level with the given escaped text.
+// The level is bounded between h2 and h6, h1 being the title of the page.
+func htmlHeading(level int, text string) htmltemplate.HTML {
+	level = max(2, min(level, 6))
+
+	return htmltemplate.HTML(fmt.Sprintf("<h%[1]d>%[2]s</h%[1]d>", level, htmltemplate.HTMLEscapeString(text)))
+}
@@ -2,57 +2,74 @@ package cmd
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mattn/go-runewidth"
 	"github.com/stretchr/testify/assert"
 )
 
+// update is used to rewrite the golden files of the testdata directory
+var update = flag.Bool("update", false, "update the golden files")
+
 func TestNewPlainFormat(t *testing.T) {
 	tests := []struct {
 		desc string
+		wrap int
 		want *plainFormat
 	}{
 		{
-			desc: "NewPlainFormat",
+			desc: "Without wrapping",
+			wrap: 0,
 			want: &plainFormat{},
 		},
+		{
+			desc: "Wrap = 80",
+			wrap: 80,
+			want: &plainFormat{wrap: 80},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			assert.Equal(t, tt.want, NewPlainFormat())
+			assert.Equal(t, tt.want, NewPlainFormat(tt.wrap))
 		})
 	}
 }
 
 func TestNewPrettyFormat(t *testing.T) {
-	type args struct {
-		wordWrap int
-	}
 	tests := []struct {
-		desc string
-		args args
-		want *prettyFormat
+		desc         string
+		opts         PrettyOptions
+		wantWordWrap int
+		wantTheme    string
 	}{
 		{
-			desc: "wordWrap = 100",
-			args: args{wordWrap: 100},
-			want: &prettyFormat{wordWrap: 100},
+			desc:         "Width = 100",
+			opts:         PrettyOptions{Width: 100, Theme: "dark"},
+			wantWordWrap: 100,
+			wantTheme:    "dark",
 		},
 		{
-			desc: "wordWrap = 0",
-			args: args{wordWrap: 0},
-			want: &prettyFormat{wordWrap: 100},
-		},
-		{
-			desc: "wordWrap = -1",
-			args: args{wordWrap: -1},
-			want: &prettyFormat{wordWrap: 100},
+			desc:         "Default options",
+			opts:         PrettyOptions{},
+			wantWordWrap: 0,
+			wantTheme:    "auto",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			assert.Equal(t, tt.want, NewPrettyFormat(tt.args.wordWrap))
+			got := NewPrettyFormat(tt.opts)
+
+			assert.Equal(t, tt.wantWordWrap, got.wordWrap)
+			assert.Equal(t, tt.wantTheme, got.theme)
+			assert.NotNil(t, got.terminal)
 		})
 	}
 }
@@ -103,9 +120,17 @@ func TestNewYamlFormat(t *testing.T) {
 }
 
 func TestPlainFormatWrite(t *testing.T) {
+	paragraphs := Page{
+		Title:   "Go (programming language)",
+		Extract: "Go is a statically typed, compiled high-level programming language designed at Google.\nIt is syntactically similar to C.\n\n\nHistory\nGo was designed at Google in 2007.",
+	}
+	rtl := Page{
+		Title:   "غو (لغة برمجة)",
+		Extract: "Go هي لغة برمجة.",
+	}
+
 	type args struct {
-		p    *Page
-		full bool
+		v *PageView
 	}
 	tests := []struct {
 		name    string
@@ -116,16 +141,16 @@ func TestPlainFormatWrite(t *testing.T) {
 	}{
 		{
 			name:    "Without full output",
-			d:       NewPlainFormat(),
-			args:    args{p: &page, full: false},
+			d:       NewPlainFormat(0),
+			args:    args{v: newTestView(&page, false)},
 			wantW:   fmt.Sprintf("Title:\n  %s\n\nExtract:\n  %s", page.Title, page.Extract),
 			wantErr: false,
 		},
 		{
 			name: "With full output",
-			d:    NewPlainFormat(),
-			args: args{p: &page, full: true},
-			wantW: fmt.Sprintf("Title:\n  %s\n\nNs:\n  %d\n\nPageid:\n  %d\n\nWikiBase Short Description:\n  %s\n\nWikiBase Item:\n  %s\n\nExtract:\n  %s",
+			d:    NewPlainFormat(0),
+			args: args{v: newTestView(&page, true)},
+			wantW: fmt.Sprintf("Title:\n  %s\n\nNs:\n  %d\n\nPageid:\n  %d\n\nWikiBase Short Description:\n  %s\n\nWikiBase Item:\n  %s\n\nDisplay Title:\n  Golang\n\nLength:\n  81519\n\nTouched:\n  2024-03-06T08:00:00Z\n\nLast Revision ID:\n  1211970426\n\nLast Revision Timestamp:\n  2024-03-05T10:00:00Z\n\nLast Revision User:\n  Gopher\n\nProtection:\n  edit=autoconfirmed\n\nExtract:\n  %s",
 				page.Title,
 				*page.Ns,
 				*page.Pageid,
@@ -134,65 +159,158 @@ func TestPlainFormatWrite(t *testing.T) {
 				page.Extract),
 			wantErr: false,
 		},
+		{
+			name:    "Indented paragraphs",
+			d:       NewPlainFormat(0),
+			args:    args{v: newTestView(&paragraphs, false)},
+			wantW:   "Title:\n  Go (programming language)\n\nExtract:\n  Go is a statically typed, compiled high-level programming language designed at Google.\n  It is syntactically similar to C.\n\n\n  History\n  Go was designed at Google in 2007.",
+			wantErr: false,
+		},
+		{
+			name:    "Wrapped paragraphs",
+			d:       NewPlainFormat(40),
+			args:    args{v: newTestView(&paragraphs, false)},
+			wantW:   "Title:\n  Go (programming language)\n\nExtract:\n  Go is a statically typed, compiled\n  high-level programming language\n  designed at Google.\n  It is syntactically similar to C.\n\n\n  History\n  Go was designed at Google in 2007.",
+			wantErr: false,
+		},
+		{
+			name:    "Right-to-left language",
+			d:       NewPlainFormat(0),
+			args:    args{v: NewPageView(&rtl, ViewOptions{Lang: "ar"})},
+			wantW:   "Title:\n  \u200fغو (لغة برمجة)\n\nExtract:\n  \u200fGo هي لغة برمجة.",
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			d := &plainFormat{}
 			w := &bytes.Buffer{}
-			if err := d.Write(w, tt.args.p, tt.args.full); (err != nil) != tt.wantErr {
-				t.Errorf("plainFormat.Write() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if gotW := w.String(); gotW != tt.wantW {
-				t.Errorf("plainFormat.Write() = %v, want %v", gotW, tt.wantW)
+			err := tt.d.Write(w, tt.args.v)
+
+			assert.Equal(t, tt.wantW, w.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
 			}
 		})
 	}
 }
 
 func TestPrettyFormatWrite(t *testing.T) {
-	type fields struct {
-		wordWrap int
-	}
-	type args struct {
-		p    *Page
-		full bool
-	}
+	noTerminal := func(w io.Writer) (int, int, bool) { return 0, 0, false }
+	smallTerminal := func(w io.Writer) (int, int, bool) { return 80, 5, true }
+	largeTerminal := func(w io.Writer) (int, int, bool) { return 120, 1000, true }
+
+	url := "https://en.wikipedia.org/wiki/Golang"
+
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantW   string
-		wantErr bool
+		name         string
+		d            *prettyFormat
+		full         bool
+		wantContains []string
+		wantExcludes []string
+		wantErr      bool
 	}{
 		{
-			fields:  fields{wordWrap: 100},
-			args:    args{p: &page, full: true},
-			wantErr: false,
+			name:         "Not a terminal",
+			d:            &prettyFormat{theme: "notty", terminal: noTerminal},
+			full:         true,
+			wantContains: []string{"## Golang", "### WikiBase Item", "Read more: " + url},
+			wantExcludes: []string{"\x1b"},
 		},
 		{
-			fields:  fields{wordWrap: 100},
-			args:    args{p: &page, full: false},
-			wantErr: false,
+			name:         "Terminal with hyperlinks",
+			d:            &prettyFormat{theme: "notty", terminal: largeTerminal},
+			wantContains: []string{hyperlink(url, "Golang"), hyperlink(url, url)},
+		},
+		{
+			name:         "No color",
+			d:            &prettyFormat{theme: "dark", noColor: true, terminal: noTerminal},
+			wantContains: []string{"Golang"},
+			wantExcludes: []string{"\x1b[38;5;"},
+		},
+		{
+			name:         "Colors",
+			d:            &prettyFormat{theme: "dark", terminal: noTerminal},
+			wantContains: []string{"\x1b[38;5;"},
+		},
+		{
+			name:         "Long output in a small terminal without pager",
+			d:            &prettyFormat{theme: "notty", terminal: smallTerminal},
+			wantContains: []string{"Golang"},
+		},
+		{
+			name:    "Unknown theme",
+			d:       &prettyFormat{theme: "unknown", terminal: noTerminal},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
-		t.Run("", func(t *testing.T) {
-			d := &prettyFormat{
-				wordWrap: tt.fields.wordWrap,
-			}
+		t.Run(tt.name, func(t *testing.T) {
 			w := &bytes.Buffer{}
-			err := d.Write(w, tt.args.p, tt.args.full)
+			err := tt.d.Write(w, newTestView(&page, tt.full))
 
 			if tt.wantErr {
 				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			for _, s := range tt.wantContains {
+				assert.Contains(t, w.String(), s)
+			}
+			for _, s := range tt.wantExcludes {
+				assert.NotContains(t, w.String(), s)
 			}
 		})
 	}
 }
 
+func TestPrettyFormatWriteWidth(t *testing.T) {
+	p := page
+	p.Extract = strings.Repeat("Go is a programming language. ", 20)
+
+	for _, width := range []int{40, 80} {
+		d := &prettyFormat{wordWrap: width, theme: "notty", terminal: func(w io.Writer) (int, int, bool) { return 0, 0, false }}
+		w := &bytes.Buffer{}
+
+		assert.NoError(t, d.Write(w, newTestView(&p, false)))
+		for _, line := range strings.Split(w.String(), "\n") {
+			assert.LessOrEqual(t, runewidth.StringWidth(line), width)
+		}
+	}
+
+	// The width of the terminal is used by default
+	d := &prettyFormat{theme: "notty", terminal: func(w io.Writer) (int, int, bool) { return 60, 1000, true }}
+	w := &bytes.Buffer{}
+
+	assert.NoError(t, d.Write(w, newTestView(&p, false)))
+	for _, line := range strings.Split(w.String(), "\n") {
+		// The hyperlinks escape sequences are not part of the width
+		for _, text := range []string{"Golang", "https://en.wikipedia.org/wiki/Golang"} {
+			line = strings.ReplaceAll(line, hyperlink("https://en.wikipedia.org/wiki/Golang", text), text)
+		}
+		assert.LessOrEqual(t, runewidth.StringWidth(line), 60)
+	}
+}
+
+func TestPrettyFormatWritePager(t *testing.T) {
+	pager := newExternalFormatter(t, "pager", "while IFS= read -r line; do echo \"| $line\"; done\n")
+
+	d := &prettyFormat{theme: "notty", pager: pager, terminal: func(w io.Writer) (int, int, bool) { return 80, 5, true }}
+	w := &bytes.Buffer{}
+
+	assert.NoError(t, d.Write(w, newTestView(&page, true)))
+	assert.Contains(t, w.String(), "|   ## "+hyperlink("https://en.wikipedia.org/wiki/Golang", "Golang"))
+
+	// The output fits in the terminal: no pager
+	d.terminal = func(w io.Writer) (int, int, bool) { return 80, 1000, true }
+	w.Reset()
+
+	assert.NoError(t, d.Write(w, newTestView(&page, true)))
+	assert.NotContains(t, w.String(), "| ")
+}
+
 func TestJsonFormatWrite(t *testing.T) {
 	type fields struct {
 		prefix string
@@ -243,7 +361,25 @@ func TestJsonFormatWrite(t *testing.T) {
     "pageprops": {
         "wikibase-shortdesc": "%s",
         "wikibase_item": "%s"
-    }
+    },
+    "displaytitle": "Golang",
+    "length": 81519,
+    "touched": "2024-03-06T08:00:00Z",
+    "lastrevid": 1211970426,
+    "protection": [
+        {
+            "type": "edit",
+            "level": "autoconfirmed",
+            "expiry": "infinity"
+        }
+    ],
+    "revisions": [
+        {
+            "revid": 1211970426,
+            "user": "Gopher",
+            "timestamp": "2024-03-05T10:00:00Z"
+        }
+    ]
 }
 `, *page.Pageid, *page.Ns, page.Title, page.Extract, page.PageProps.WikiBaseShortDesc, page.PageProps.WikiBaseItem),
 			wantErr: false,
@@ -260,7 +396,25 @@ func TestJsonFormatWrite(t *testing.T) {
  "pageprops": {
  "wikibase-shortdesc": "%s",
  "wikibase_item": "%s"
+ },
+ "displaytitle": "Golang",
+ "length": 81519,
+ "touched": "2024-03-06T08:00:00Z",
+ "lastrevid": 1211970426,
+ "protection": [
+ {
+ "type": "edit",
+ "level": "autoconfirmed",
+ "expiry": "infinity"
  }
+ ],
+ "revisions": [
+ {
+ "revid": 1211970426,
+ "user": "Gopher",
+ "timestamp": "2024-03-05T10:00:00Z"
+ }
+ ]
  }
 `, *page.Pageid, *page.Ns, page.Title, page.Extract, page.PageProps.WikiBaseShortDesc, page.PageProps.WikiBaseItem),
 			wantErr: false,
@@ -276,7 +430,7 @@ func TestJsonFormatWrite(t *testing.T) {
 
 			newPage := page
 			tt.args.p = &newPage
-			err := d.Write(w, tt.args.p, tt.args.full)
+			err := d.Write(w, newTestView(tt.args.p, tt.args.full))
 
 			assert.Equal(t, tt.wantW, w.String())
 			if tt.wantErr {
@@ -323,6 +477,18 @@ extract: Go is a statically typed, compiled programming language designed at Goo
 pageprops:
   wikibase-shortdesc: %s
   wikibase_item: %s
+displaytitle: Golang
+length: 81519
+touched: 2024-03-06T08:00:00Z
+lastrevid: 1211970426
+protection:
+- type: edit
+  level: autoconfirmed
+  expiry: infinity
+revisions:
+- revid: 1211970426
+  user: Gopher
+  timestamp: 2024-03-05T10:00:00Z
 
 `, *page.Pageid, *page.Ns, page.Title, page.PageProps.WikiBaseShortDesc, page.PageProps.WikiBaseItem),
 			wantErr: false,
@@ -334,14 +500,672 @@ pageprops:
 
 			newPage := page
 			tt.args.p = &newPage
-			err := tt.d.Write(w, tt.args.p, tt.args.full)
+			err := tt.d.Write(w, newTestView(tt.args.p, tt.args.full))
+
+			assert.Equal(t, tt.wantW, w.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewMarkdownFormat(t *testing.T) {
+	type args struct {
+		frontMatter bool
+	}
+	tests := []struct {
+		desc string
+		args args
+		want *markdownFormat
+	}{
+		{
+			desc: "Without front matter",
+			args: args{frontMatter: false},
+			want: &markdownFormat{frontMatter: false},
+		},
+		{
+			desc: "With front matter",
+			args: args{frontMatter: true},
+			want: &markdownFormat{frontMatter: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewMarkdownFormat(tt.args.frontMatter))
+		})
+	}
+}
+
+func TestMarkdownFormatWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       *markdownFormat
+		extract string
+		wantW   string
+		wantErr bool
+	}{
+		{
+			name:    "Without front matter",
+			d:       NewMarkdownFormat(false),
+			extract: page.Extract,
+			wantW: fmt.Sprintf(`# %s
+
+> %s
+
+%s
+
+Source: [%s](https://en.wikipedia.org/wiki/Golang)
+`, page.Title, page.PageProps.WikiBaseShortDesc, page.Extract, page.Title),
+			wantErr: false,
+		},
+		{
+			name:    "With front matter and sections",
+			d:       NewMarkdownFormat(true),
+			extract: "Go is a programming language.\nIt is compiled.\n\n\n== History ==\nGo was designed at Google.\n\n\n=== Naming ===\nThe name.",
+			wantW: fmt.Sprintf(`---
+title: %s
+pageid: %d
+wikidata: %s
+lang: en
+source: https://en.wikipedia.org/wiki/Golang
+revision: 1211970426
+last_edited: "2024-03-05T10:00:00Z"
+fetched_at: "2025-01-21T11:29:03Z"
+license: CC BY-SA 4.0
+license_url: https://creativecommons.org/licenses/by-sa/4.0/
+---
+
+# %s
+
+> %s
+
+Go is a programming language.
+
+It is compiled.
+
+## History
+
+Go was designed at Google.
+
+### Naming
+
+The name.
+
+Source: [%s](https://en.wikipedia.org/wiki/Golang)
+`, page.Title, *page.Pageid, page.PageProps.WikiBaseItem, page.Title, page.PageProps.WikiBaseShortDesc, page.Title),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			newPage := page
+			newPage.Extract = tt.extract
+			newPage.FetchedAt = time.Date(2025, 1, 21, 11, 29, 3, 0, time.UTC)
+			err := tt.d.Write(w, newTestView(&newPage, false))
+
+			assert.Equal(t, tt.wantW, w.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMarkdownFormatWriteFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		wantW  string
+	}{
+		{
+			name:   "Link only",
+			fields: []string{"title", "url"},
+			wantW:  fmt.Sprintf("# %s\n\nSource: [%s](https://en.wikipedia.org/wiki/Golang)\n", page.Title, page.Title),
+		},
+		{
+			name:   "Short description only",
+			fields: []string{"short_description"},
+			wantW:  fmt.Sprintf("# %s\n\n> %s\n\n", page.Title, page.PageProps.WikiBaseShortDesc),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := NewMarkdownFormat(false).Write(w, NewPageView(&page, ViewOptions{Lang: "en", Fields: tt.fields, Selected: true}))
 
+			assert.NoError(t, err)
 			assert.Equal(t, tt.wantW, w.String())
+			assert.NotContains(t, w.String(), page.Extract)
+		})
+	}
+}
+
+func TestNewTemplateFormat(t *testing.T) {
+	tests := []struct {
+		desc    string
+		text    string
+		wantErr bool
+	}{
+		{
+			desc:    "Valid template",
+			text:    "{{.Title}}: {{.ShortDescription}}",
+			wantErr: false,
+		},
+		{
+			desc:    "Invalid template",
+			text:    "{{.Title",
+			wantErr: true,
+		},
+		{
+			desc:    "Unknown function",
+			text:    "{{ unknown .Title }}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := NewTemplateFormat(tt.text)
+
 			if tt.wantErr {
 				assert.Error(t, err)
+				assert.Nil(t, got)
 			} else {
 				assert.NoError(t, err)
+				assert.NotNil(t, got.tmpl)
 			}
 		})
 	}
 }
+
+func TestTemplateFormatWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantW   string
+		wantErr bool
+	}{
+		{
+			name:    "Title and short description",
+			text:    "{{.Title}}: {{.ShortDescription}}",
+			wantW:   fmt.Sprintf("%s: %s\n", page.Title, page.PageProps.WikiBaseShortDesc),
+			wantErr: false,
+		},
+		{
+			name:    "Helpers",
+			text:    "{{ json .Title }} {{ .Extract | sentences 1 | truncate 20 }}\n{{ .URL }} {{ .WikidataItem }}\n",
+			wantW:   "\"Golang\" Go is a statically…\nhttps://en.wikipedia.org/wiki/Golang WikiBaseItem\n",
+			wantErr: false,
+		},
+		{
+			name:    "Unknown field",
+			text:    "{{.Unknown}}",
+			wantW:   "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewTemplateFormat(tt.text)
+			assert.NoError(t, err)
+
+			w := &bytes.Buffer{}
+			err = d.Write(w, newTestView(&page, false))
+
+			assert.Equal(t, tt.wantW, w.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTemplateFormatWriteLang(t *testing.T) {
+	d, err := NewTemplateFormat("{{ .Extract | sentences 1 }}")
+	assert.NoError(t, err)
+
+	p := &Page{Title: "Zürich", Extract: "Er wohnt in Zürich, vgl. Abb. 5. Sie nicht."}
+
+	// The sentences are split in the language of the page
+	w := &bytes.Buffer{}
+	assert.NoError(t, d.Write(w, NewPageView(p, ViewOptions{Lang: "de"})))
+	assert.Equal(t, "Er wohnt in Zürich, vgl. Abb. 5.\n", w.String())
+
+	w.Reset()
+	assert.NoError(t, d.Write(w, NewPageView(p, ViewOptions{Lang: "en"})))
+	assert.Equal(t, "Er wohnt in Zürich, vgl.\n", w.String())
+}
+
+func TestNdjsonFormatWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       *PageView
+		wantW   string
+		wantErr bool
+	}{
+		{
+			name:    "Without full output",
+			v:       newTestView(&page, false),
+			wantW:   fmt.Sprintf("{\"title\":\"%s\",\"extract\":\"%s\"}\n", page.Title, page.Extract),
+			wantErr: false,
+		},
+		{
+			name:    "With full output",
+			v:       newTestView(&page, true),
+			wantW:   fmt.Sprintf("{\"pageid\":%d,\"ns\":%d,\"title\":\"%s\",\"extract\":\"%s\",\"pageprops\":{\"wikibase-shortdesc\":\"%s\",\"wikibase_item\":\"%s\"},\"displaytitle\":\"Golang\",\"length\":81519,\"touched\":\"2024-03-06T08:00:00Z\",\"lastrevid\":1211970426,\"protection\":[{\"type\":\"edit\",\"level\":\"autoconfirmed\",\"expiry\":\"infinity\"}],\"revisions\":[{\"revid\":1211970426,\"user\":\"Gopher\",\"timestamp\":\"2024-03-05T10:00:00Z\"}]}\n", *page.Pageid, *page.Ns, page.Title, page.Extract, page.PageProps.WikiBaseShortDesc, page.PageProps.WikiBaseItem),
+			wantErr: false,
+		},
+		{
+			// The same fields as the full output, selected by the user, are a flat object
+			name:    "With the fields of the full output",
+			v:       NewPageView(&page, ViewOptions{Lang: "en", Fields: defaultFullFields, Selected: true}),
+			wantW:   fmt.Sprintf("{\"pageid\":%d,\"ns\":%d,\"title\":\"%s\",\"short_description\":\"%s\",\"extract\":\"%s\",\"wikidata_item\":\"%s\",\"display_title\":\"Golang\",\"length\":81519,\"touched\":\"2024-03-06T08:00:00Z\",\"last_revid\":1211970426,\"last_rev_timestamp\":\"2024-03-05T10:00:00Z\",\"last_rev_user\":\"Gopher\",\"protection\":\"edit=autoconfirmed\",\"watchers\":\"\"}\n", *page.Pageid, *page.Ns, page.Title, page.PageProps.WikiBaseShortDesc, page.Extract, page.PageProps.WikiBaseItem),
+			wantErr: false,
+		},
+		{
+			name:    "With fields",
+			v:       NewPageView(&page, ViewOptions{Lang: "en", Fields: []string{"url", "pageid", "title"}, Selected: true}),
+			wantW:   fmt.Sprintf("{\"url\":\"https://en.wikipedia.org/wiki/Golang\",\"pageid\":%d,\"title\":\"%s\"}\n", *page.Pageid, page.Title),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := NewNdjsonFormat().Write(w, tt.v)
+
+			assert.Equal(t, tt.wantW, w.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDelimitedFormatWrite(t *testing.T) {
+	multiline := page
+	multiline.Extract = "Go is a \"language\".\nIt is\tcompiled."
+
+	tests := []struct {
+		name    string
+		d       *delimitedFormat
+		views   []*PageView
+		wantW   string
+		wantErr bool
+	}{
+		{
+			name:  "CSV with header",
+			d:     NewCsvFormat(true),
+			views: []*PageView{newTestView(&page, false)},
+			wantW: fmt.Sprintf("title,extract\n%s,\"%s\"\n", page.Title, page.Extract),
+		},
+		{
+			name:  "CSV without header, with full output",
+			d:     NewCsvFormat(false),
+			views: []*PageView{newTestView(&page, true)},
+			wantW: fmt.Sprintf("%d,%d,%s,%s,\"%s\",%s,Golang,81519,2024-03-06T08:00:00Z,1211970426,2024-03-05T10:00:00Z,Gopher,edit=autoconfirmed,\n", *page.Pageid, *page.Ns, page.Title, page.PageProps.WikiBaseShortDesc, page.Extract, page.PageProps.WikiBaseItem),
+		},
+		{
+			name: "CSV with multi-line extract and several pages",
+			d:    NewCsvFormat(true),
+			views: []*PageView{
+				NewPageView(&multiline, ViewOptions{Lang: "en", Fields: []string{"pageid", "extract"}}),
+				NewPageView(&multiline, ViewOptions{Lang: "en", Fields: []string{"pageid", "extract"}}),
+			},
+			wantW: fmt.Sprintf("pageid,extract\n%[1]d,\"Go is a \"\"language\"\".\nIt is\tcompiled.\"\n%[1]d,\"Go is a \"\"language\"\".\nIt is\tcompiled.\"\n", *page.Pageid),
+		},
+		{
+			name:  "TSV with multi-line extract",
+			d:     NewTsvFormat(true),
+			views: []*PageView{NewPageView(&multiline, ViewOptions{Lang: "en", Fields: []string{"title", "extract"}})},
+			wantW: fmt.Sprintf("title\textract\n%s\tGo is a \"language\".\\nIt is\\tcompiled.\n", page.Title),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			for _, v := range tt.views {
+				err := tt.d.Write(w, v)
+				if tt.wantErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			}
+
+			assert.Equal(t, tt.wantW, w.String())
+		})
+	}
+}
+
+func TestHtmlFormatWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      Page
+		golden string
+	}{
+		{
+			name:   "Simple page",
+			p:      page,
+			golden: "html_simple.golden",
+		},
+		{
+			name: "Sections and escaping",
+			p: Page{
+				Title:   `<script>alert("Go")</script>`,
+				Extract: "Go is a \"statically\" typed language & more.\nIt is <b>compiled</b>.\n\n\n== History ==\nGo was designed at Google.\n\n\n=== Naming & <i>logo</i> ===\nThe name.",
+				PageProps: &WikiPageProps{
+					WikiBaseShortDesc: "Programming <language>",
+				},
+			},
+			golden: "html_sections.golden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			err := NewHtmlFormat().Write(w, newTestView(&tt.p, false))
+			assert.NoError(t, err)
+
+			assertGolden(t, tt.golden, w.Bytes())
+		})
+	}
+}
+
+func TestHtmlHeading(t *testing.T) {
+	assert.Equal(t, htmltemplate.HTML("<h2>History</h2>"), htmlHeading(1, "History"))
+	assert.Equal(t, htmltemplate.HTML("<h3>A &amp; B</h3>"), htmlHeading(3, "A & B"))
+	assert.Equal(t, htmltemplate.HTML("<h6>&lt;i&gt;</h6>"), htmlHeading(7, "<i>"))
+}
+
+// assertGolden compares got with the content of the given golden file of the testdata directory.
+// The golden file is rewritten instead when the tests are run with the '-update' flag.
+func assertGolden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", golden)
+	if *update {
+		assert.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestWikidataFormatWrite(t *testing.T) {
+	p := copyPage(&page)
+	p.Wikidata = &Wikidata{
+		ID:    "Q37227",
+		Label: "Go",
+		URL:   "https://www.wikidata.org/wiki/Q37227",
+		Claims: []WikidataClaim{
+			{Property: "P31", Label: "instance of", Values: []string{"programming language", "free software"}},
+			{Property: "P571", Label: "inception", Values: []string{"2009-11-10"}},
+		},
+	}
+	v := newTestView(&p, false)
+
+	tests := []struct {
+		name  string
+		d     Displayer
+		wantW string
+	}{
+		{
+			name:  "Plain",
+			d:     NewPlainFormat(0),
+			wantW: fmt.Sprintf("Title:\n  %s\n\nWikidata:\n  Go (Q37227)\n  instance of: programming language, free software\n  inception: 2009-11-10\n\nExtract:\n  %s", page.Title, page.Extract),
+		},
+		{
+			name:  "Markdown",
+			d:     NewMarkdownFormat(false),
+			wantW: fmt.Sprintf("# %s\n\n> %s\n\n%s\n\n## Wikidata\n\n| Go | [Q37227](https://www.wikidata.org/wiki/Q37227) |\n| --- | --- |\n| instance of | programming language, free software |\n| inception | 2009-11-10 |\n\nSource: [%s](https://en.wikipedia.org/wiki/Golang)\n", page.Title, page.PageProps.WikiBaseShortDesc, page.Extract, page.Title),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			assert.NoError(t, tt.d.Write(w, v))
+			assert.Equal(t, tt.wantW, w.String())
+		})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewJsonFormat("", "    ").Write(w, v))
+		assert.JSONEq(t, fmt.Sprintf(`{"title":"%s","extract":"%s","wikidata":{"id":"Q37227","label":"Go","url":"https://www.wikidata.org/wiki/Q37227","claims":[{"property":"P31","label":"instance of","values":["programming language","free software"]},{"property":"P571","label":"inception","values":["2009-11-10"]}]}}`, page.Title, page.Extract), w.String())
+	})
+
+	t.Run("Pretty", func(t *testing.T) {
+		d := NewPrettyFormat(PrettyOptions{Theme: "notty"})
+		d.terminal = func(w io.Writer) (int, int, bool) { return 0, 0, false }
+
+		w := &bytes.Buffer{}
+		assert.NoError(t, d.Write(w, v))
+		assert.Contains(t, w.String(), "Wikidata")
+		assert.Contains(t, w.String(), "instance of")
+		assert.Contains(t, w.String(), "programming language, free software")
+	})
+}
+
+func TestWikidataMarkdown(t *testing.T) {
+	d := &Wikidata{
+		ID:     "Q1",
+		URL:    "https://www.wikidata.org/wiki/Q1",
+		Claims: []WikidataClaim{{Property: "P31", Label: "a | b", Values: []string{"c\nd"}}},
+	}
+
+	assert.Equal(t, "| Q1 | [Q1](https://www.wikidata.org/wiki/Q1) |\n| --- | --- |\n| a \\| b | c d |\n", wikidataMarkdown(d))
+}
+
+// newTestView creates the english view of the given page, with the default fields
+// or all of them when requesting the full output.
+func newTestView(p *Page, full bool) *PageView {
+	return NewPageView(p, ViewOptions{Lang: "en", Fields: selectFields(nil, full)})
+}
+
+func TestInfoboxFormatWrite(t *testing.T) {
+	p := copyPage(&page)
+	p.Infobox = &Infobox{
+		Name: "Infobox programming language",
+		Fields: []InfoboxField{
+			{Key: "developer", Value: "Google"},
+			{Key: "released", Value: "2009-11-10"},
+		},
+	}
+	v := NewPageView(&p, ViewOptions{Lang: "en", Fields: []string{"title", "extract", "infobox"}})
+
+	tests := []struct {
+		name  string
+		d     Displayer
+		wantW string
+	}{
+		{
+			name:  "Plain",
+			d:     NewPlainFormat(0),
+			wantW: fmt.Sprintf("Title:\n  %s\n\nInfobox:\n  developer: Google\n  released: 2009-11-10\n\nExtract:\n  %s", page.Title, page.Extract),
+		},
+		{
+			name:  "Markdown",
+			d:     NewMarkdownFormat(false),
+			wantW: fmt.Sprintf("# %s\n\n> %s\n\n%s\n\n## Infobox\n\n| Field | Value |\n| --- | --- |\n| developer | Google |\n| released | 2009-11-10 |\n\nSource: [%s](https://en.wikipedia.org/wiki/Golang)\n", page.Title, page.PageProps.WikiBaseShortDesc, page.Extract, page.Title),
+		},
+		{
+			name:  "CSV",
+			d:     NewCsvFormat(false),
+			wantW: fmt.Sprintf("%s,%q,developer: Google; released: 2009-11-10\n", page.Title, page.Extract),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			assert.NoError(t, tt.d.Write(w, v))
+			assert.Equal(t, tt.wantW, w.String())
+		})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewJsonFormat("", "    ").Write(w, v))
+		assert.JSONEq(t, fmt.Sprintf(`{"title":"%s","extract":"%s","infobox":{"name":"Infobox programming language","fields":[{"key":"developer","value":"Google"},{"key":"released","value":"2009-11-10"}]}}`, page.Title, page.Extract), w.String())
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewHtmlFormat().Write(w, v))
+		assert.Contains(t, w.String(), "<table class=\"infobox\">\n<tr><th scope=\"row\">developer</th><td>Google</td></tr>\n<tr><th scope=\"row\">released</th><td>2009-11-10</td></tr>\n</table>")
+	})
+
+	t.Run("Not requested", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewPlainFormat(0).Write(w, newTestView(&p, false)))
+		assert.NotContains(t, w.String(), "Infobox")
+	})
+}
+
+func TestCoordinatesFormatWrite(t *testing.T) {
+	p := copyPage(&page)
+	p.Coordinates = []WikiCoordinates{{Lat: 48.693611, Lon: 6.183333, Globe: "earth"}}
+	v := NewPageView(&p, ViewOptions{Lang: "en", Fields: []string{"title", "coordinates"}})
+
+	tests := []struct {
+		name  string
+		d     Displayer
+		wantW string
+	}{
+		{
+			name:  "Plain",
+			d:     NewPlainFormat(0),
+			wantW: fmt.Sprintf("Title:\n  %s\n\nCoordinates:\n  48.693611, 6.183333\n\n", page.Title),
+		},
+		{
+			name:  "Markdown",
+			d:     NewMarkdownFormat(false),
+			wantW: fmt.Sprintf("# %s\n\n> %s\n\nCoordinates: 48.693611, 6.183333\n\nSource: [%s](https://en.wikipedia.org/wiki/Golang)\n", page.Title, page.PageProps.WikiBaseShortDesc, page.Title),
+		},
+		{
+			name:  "CSV",
+			d:     NewCsvFormat(false),
+			wantW: fmt.Sprintf("%s,\"48.693611, 6.183333\"\n", page.Title),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			assert.NoError(t, tt.d.Write(w, v))
+			assert.Equal(t, tt.wantW, w.String())
+		})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewJsonFormat("", "    ").Write(w, v))
+		assert.JSONEq(t, fmt.Sprintf(`{"title":"%s","coordinates":[{"lat":48.693611,"lon":6.183333,"globe":"earth"}]}`, page.Title), w.String())
+	})
+
+	t.Run("Front matter", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewMarkdownFormat(true).Write(w, v))
+		assert.Contains(t, w.String(), "\ncoordinates: [48.693611, 6.183333]\n")
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewHtmlFormat().Write(w, v))
+		assert.Contains(t, w.String(), `<p class="geo"><span class="latitude">48.693611</span>, <span class="longitude">6.183333</span></p>`)
+	})
+}
+
+func TestInfoboxMarkdown(t *testing.T) {
+	i := &Infobox{Fields: []InfoboxField{{Key: "a | b", Value: "c\nd"}}}
+
+	assert.Equal(t, "| Field | Value |\n| --- | --- |\n| a \\| b | c d |\n", infoboxMarkdown(i))
+}
+
+func TestLastEditText(t *testing.T) {
+	assert.Equal(t, "Last edited on 2024-03-05T10:00:00Z by Gopher (revision 1211970426)", lastEditText(newTestView(&page, true)))
+	assert.Equal(t, "", lastEditText(newTestView(&page, false)))
+	assert.Equal(t, "", lastEditText(NewPageView(&Page{Title: "Go"}, ViewOptions{Lang: "en", Fields: defaultFullFields})))
+
+	w := &bytes.Buffer{}
+	assert.NoError(t, NewMarkdownFormat(false).Write(w, newTestView(&page, true)))
+	assert.True(t, strings.HasSuffix(w.String(), "\nLast edited on 2024-03-05T10:00:00Z by Gopher (revision 1211970426)\n"))
+
+	w.Reset()
+	assert.NoError(t, NewHtmlFormat().Write(w, newTestView(&page, true)))
+	assert.Contains(t, w.String(), "<p>Last edited on 2024-03-05T10:00:00Z by Gopher (revision 1211970426).</p>")
+}
+
+func TestAttributionFormatWrite(t *testing.T) {
+	v := NewPageView(&page, ViewOptions{Lang: "en", Fields: []string{"title", "extract", "attribution"}})
+
+	tests := []struct {
+		name  string
+		d     Displayer
+		wantW string
+	}{
+		{
+			name:  "Plain",
+			d:     NewPlainFormat(0),
+			wantW: fmt.Sprintf("Title:\n  %s\n\nExtract:\n  %s\n\nAttribution:\n  Source: Golang (https://en.wikipedia.org/wiki/Golang)\n  Permalink: https://en.wikipedia.org/w/index.php?oldid=1211970426&title=Golang\n  Contributors: https://en.wikipedia.org/w/index.php?action=history&title=Golang\n  License: CC BY-SA 4.0 (https://creativecommons.org/licenses/by-sa/4.0/)\n", page.Title, page.Extract),
+		},
+		{
+			name:  "Markdown",
+			d:     NewMarkdownFormat(false),
+			wantW: fmt.Sprintf("# %s\n\n> %s\n\n%s\n\nSource: [%s](https://en.wikipedia.org/wiki/Golang)\n\nText from the Wikipedia article [Golang](https://en.wikipedia.org/wiki/Golang) ([permalink](https://en.wikipedia.org/w/index.php?oldid=1211970426&title=Golang)), by [its contributors](https://en.wikipedia.org/w/index.php?action=history&title=Golang), available under the [CC BY-SA 4.0](https://creativecommons.org/licenses/by-sa/4.0/) license.\n", page.Title, page.PageProps.WikiBaseShortDesc, page.Extract, page.Title),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			assert.NoError(t, tt.d.Write(w, v))
+			assert.Equal(t, tt.wantW, w.String())
+		})
+	}
+
+	t.Run("Plain without extract", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewPlainFormat(0).Write(w, NewPageView(&page, ViewOptions{Lang: "en", Fields: []string{"attribution"}})))
+		assert.True(t, strings.HasPrefix(w.String(), fmt.Sprintf("Title:\n  %s\n\nAttribution:\n  Source: ", page.Title)))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewJsonFormat("", "    ").Write(w, v))
+		assert.JSONEq(t, fmt.Sprintf(`{"title":"%s","extract":"%s","attribution":{"title":"Golang","url":"https://en.wikipedia.org/wiki/Golang","permalink":"https://en.wikipedia.org/w/index.php?oldid=1211970426&title=Golang","history":"https://en.wikipedia.org/w/index.php?action=history&title=Golang","license":"CC BY-SA 4.0","license_url":"https://creativecommons.org/licenses/by-sa/4.0/"}}`, page.Title, page.Extract), w.String())
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewHtmlFormat().Write(w, v))
+		assert.Contains(t, w.String(), `<p class="attribution"><a href="https://en.wikipedia.org/w/index.php?oldid=1211970426&amp;title=Golang">Permanent link</a> to the revision of the text. <a href="https://en.wikipedia.org/w/index.php?action=history&amp;title=Golang">History</a> of the article and its contributors.</p>`)
+	})
+
+	t.Run("Pretty", func(t *testing.T) {
+		d := NewPrettyFormat(PrettyOptions{Theme: "notty"})
+		d.terminal = func(w io.Writer) (int, int, bool) { return 0, 0, false }
+
+		w := &bytes.Buffer{}
+		assert.NoError(t, d.Write(w, v))
+		assert.Contains(t, w.String(), "Attribution")
+		assert.Contains(t, w.String(), "its contributors")
+	})
+
+	t.Run("Not requested", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, NewHtmlFormat().Write(w, newTestView(&page, false)))
+		assert.NotContains(t, w.String(), "attribution")
+	})
+}
@@ -0,0 +1,426 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// maxGraphDepth is the maximum value of the 'depth' flag of the 'graph' command
+const maxGraphDepth = 2
+
+var (
+	graphDepth   int  // number of links followed from the article
+	graphLimit   int  // maximum number of links followed per page, not limited when 0
+	withClusters bool // whether or not to cluster the pages by category
+
+	// graphCmd represents the 'graph' command
+	graphCmd = &cobra.Command{
+		Use:   "graph <title>",
+		Short: "Build the graph of the links around an article",
+		Long: `Build the graph of the links around the Wikipedia article best matching the given title:
+the nodes are the articles with their short description, the edges the links between them.
+
+With '--depth 1', the graph is made of the article and the articles it links to,
+with '--depth 2' of the articles they link to too. At most '--limit' links are followed per article.
+With '--clusters', the articles are grouped by their most common category in the graph, ie.
+  wpdia-go graph --output dot golang | dot -Tsvg > golang.svg
+  wpdia-go graph --depth 2 --limit 10 --clusters --output graphml golang > golang.graphml
+  wpdia-go graph --output json golang`,
+
+		PreRunE: validateGraphFlags,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			title := args[0]
+
+			w, err := NewWikiClient(APIBaseURL, "")
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+
+			title = searchPage(w, title).Title
+
+			logger.Info("Building graph...", slog.String("title", title), slog.Int("depth", graphDepth))
+
+			g, err := buildLinkGraph(w, title, graphDepth, graphLimit, concurrency)
+			if err != nil {
+				logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.String("title", title))
+				os.Exit(1)
+			}
+
+			logger.Info("Getting short descriptions...", slog.Int("nodes", len(g.Nodes)))
+
+			if err := g.describe(w, concurrency); err != nil {
+				logger.Error(err.Error(), slog.String("url", APIBaseURL))
+				os.Exit(1)
+			}
+
+			if withClusters {
+				logger.Info("Getting categories...", slog.Int("nodes", len(g.Nodes)))
+
+				if err := g.cluster(w, concurrency); err != nil {
+					logger.Error(err.Error(), slog.String("url", APIBaseURL))
+					os.Exit(1)
+				}
+			}
+
+			if err := writeGraph(os.Stdout, g, output, header); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+// Graph represents the graph of the links around an article.
+type Graph struct {
+	Nodes []*GraphNode `json:"nodes" yaml:"nodes"`
+	Edges []GraphEdge  `json:"edges" yaml:"edges"`
+
+	// index is the nodes by title
+	index map[string]*GraphNode
+}
+
+// GraphNode represents an article of a graph.
+type GraphNode struct {
+	// ID is the title of the article, which identifies it in the edges
+	ID          string `json:"id" yaml:"id"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	URL         string `json:"url" yaml:"url"`
+
+	// Depth is the number of links between the article at the center of the graph and this one
+	Depth int `json:"depth" yaml:"depth"`
+
+	// Category is the category the article is clustered in, with '--clusters'
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+}
+
+// GraphEdge represents a link from an article to another one.
+type GraphEdge struct {
+	Source string `json:"source" yaml:"source"`
+	Target string `json:"target" yaml:"target"`
+}
+
+func init() {
+	graphCmd.Flags().IntVar(&graphDepth, "depth", 1, fmt.Sprintf("Number of links followed from the article. Must be between 1 and %d.", maxGraphDepth))
+	graphCmd.Flags().IntVar(&graphLimit, "limit", 50, "Maximum number of links requested and followed per article. Not limited when 0.")
+	graphCmd.Flags().IntVar(&concurrency, "concurrency", 4, fmt.Sprintf("Maximum number of requests sent at the same time. Must be between 1 and %d.", maxConcurrency))
+	graphCmd.Flags().BoolVar(&withClusters, "clusters", false, "Cluster the articles by their most common category in the graph.")
+
+	rootCmd.AddCommand(graphCmd)
+}
+
+// validateGraphFlags will determine whether the flags of the 'graph' command are valid.
+func validateGraphFlags(cmd *cobra.Command, args []string) error {
+	if graphDepth < 1 || graphDepth > maxGraphDepth {
+		return fmt.Errorf("error: invalid value for flag 'depth': %d. Must be between 1 and %d", graphDepth, maxGraphDepth)
+	}
+	if graphLimit < 0 {
+		return fmt.Errorf("error: invalid value for flag 'limit': %d. Must be positive", graphLimit)
+	}
+	if concurrency < 1 || concurrency > maxConcurrency {
+		return fmt.Errorf("error: invalid value for flag 'concurrency': %d. Must be between 1 and %d", concurrency, maxConcurrency)
+	}
+
+	return validateListFlags(cmd, args, "dot", "graphml")
+}
+
+// buildLinkGraph will build the graph of the links around the article with the given title, following the links
+// of the articles depth times, at most limit links per article. The links of the articles of a level are
+// requested by batches of maxTitlesBatch titles, with at most concurrency requests at the same time.
+// The links between the articles of the last level aren't requested.
+// It returns the graph or any error encountered.
+func buildLinkGraph(w *WikiClient, title string, depth, limit, concurrency int) (*Graph, error) {
+	g := &Graph{Edges: []GraphEdge{}, index: map[string]*GraphNode{}}
+	g.add(title, 0)
+
+	edges := map[GraphEdge]bool{}
+	level := []string{title}
+	for d := 1; d <= depth && len(level) > 0; d++ {
+		logger.Debug("Getting links...", slog.Int("level", d), slog.Int("pages", len(level)))
+
+		links, err := getBatched(level, concurrency, func(batch []string) (map[string]PageLinks, error) {
+			return w.GetPagesLinks(batch, []int{articleNamespace}, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var next []string
+		for _, source := range level {
+			// The links returned for a redirect are the ones of its target, which isn't linked from the source of the redirect
+			if links[source].Title != source {
+				continue
+			}
+
+			for _, l := range links[source].Links {
+				if g.index[l.Title] == nil {
+					g.add(l.Title, d)
+					next = append(next, l.Title)
+				}
+
+				e := GraphEdge{Source: source, Target: l.Title}
+				if !edges[e] && e.Source != e.Target {
+					edges[e] = true
+					g.Edges = append(g.Edges, e)
+				}
+			}
+		}
+		level = next
+	}
+
+	return g, nil
+}
+
+// add adds the article with the given title to the graph, at the given depth.
+func (g *Graph) add(title string, depth int) {
+	n := &GraphNode{ID: title, URL: articleURL(lang, title), Depth: depth}
+	g.Nodes = append(g.Nodes, n)
+	g.index[title] = n
+}
+
+// titles returns the titles of the articles of the graph.
+func (g *Graph) titles() []string {
+	titles := make([]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		titles[i] = n.ID
+	}
+
+	return titles
+}
+
+// describe will set the short description of the articles of the graph, requested by batches of maxTitlesBatch
+// titles with at most concurrency requests at the same time.
+// It returns any error encountered.
+func (g *Graph) describe(w *WikiClient, concurrency int) error {
+	descriptions, err := getBatched(g.titles(), concurrency, w.GetShortDescriptions)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range g.Nodes {
+		n.Description = descriptions[n.ID]
+	}
+
+	return nil
+}
+
+// cluster will set the category of the articles of the graph to the one of their categories shared by the most articles,
+// when at least two articles share it. The categories are requested by batches of maxTitlesBatch titles
+// with at most concurrency requests at the same time.
+// It returns any error encountered.
+func (g *Graph) cluster(w *WikiClient, concurrency int) error {
+	categories, err := getBatched(g.titles(), concurrency, w.GetPagesCategories)
+	if err != nil {
+		return err
+	}
+
+	counts := map[string]int{}
+	for _, c := range categories {
+		for _, r := range c.Links {
+			counts[r.Title]++
+		}
+	}
+
+	for _, n := range g.Nodes {
+		var shared []string
+		for _, r := range categories[n.ID].Links {
+			if counts[r.Title] > 1 {
+				shared = append(shared, r.Title)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+
+		// The most common category first, the first by title on ties
+		n.Category = slices.MinFunc(shared, func(a, b string) int {
+			return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+		})
+	}
+
+	return nil
+}
+
+// writeGraph will write the given graph to w in the given output format.
+// The plain output lists the articles with their description and their links, the json output the nodes and the edges,
+// the dot output is a Graphviz graph and the graphml output a GraphML document.
+// The csv and tsv outputs are the list of the edges, starting with a row of the column names when header is true.
+func writeGraph(w io.Writer, g *Graph, format string, header bool) error {
+	switch format {
+	case "dot":
+		return writeGraphDot(w, g)
+
+	case "graphml":
+		return writeGraphML(w, g)
+	}
+
+	t := Table{Columns: []string{"source", "target"}}
+	for _, e := range g.Edges {
+		t.Rows = append(t.Rows, []string{e.Source, e.Target})
+	}
+
+	return writeList(w, List{
+		Title: g.Nodes[0].ID + ": links",
+		Value: g,
+		Table: t,
+		Plain: func(w io.Writer) error {
+			targets := map[string][]string{}
+			for _, e := range g.Edges {
+				targets[e.Source] = append(targets[e.Source], e.Target)
+			}
+
+			var b strings.Builder
+			for _, n := range g.Nodes {
+				b.WriteString(n.ID)
+				if n.Description != "" {
+					b.WriteString(": " + n.Description)
+				}
+				if n.Category != "" {
+					b.WriteString(" [" + n.Category + "]")
+				}
+				b.WriteString("\n")
+
+				for _, t := range targets[n.ID] {
+					b.WriteString("  -> " + t + "\n")
+				}
+			}
+
+			_, err := io.WriteString(w, b.String())
+			return err
+		},
+	}, format, header)
+}
+
+// writeGraphDot will write the given graph to w as a Graphviz directed graph.
+// The article at the center of the graph is bold, the articles of a category are grouped in a cluster.
+func writeGraphDot(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Nodes[0].ID))
+
+	node := func(indent string, n *GraphNode) {
+		attrs := []string{"URL=" + dotQuote(n.URL)}
+		if n.Depth == 0 {
+			attrs = append(attrs, "style=bold")
+		}
+		if n.Description != "" {
+			attrs = append(attrs, "tooltip="+dotQuote(n.Description))
+		}
+		fmt.Fprintf(&b, "%s%s [%s];\n", indent, dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+
+	var clusters []string
+	members := map[string][]*GraphNode{}
+	for _, n := range g.Nodes {
+		if n.Category == "" {
+			node("\t", n)
+			continue
+		}
+		if members[n.Category] == nil {
+			clusters = append(clusters, n.Category)
+		}
+		members[n.Category] = append(members[n.Category], n)
+	}
+
+	for i, c := range clusters {
+		fmt.Fprintf(&b, "\tsubgraph cluster_%d {\n\t\tlabel=%s;\n", i, dotQuote(c))
+		for _, n := range members[c] {
+			node("\t\t", n)
+		}
+		b.WriteString("\t}\n")
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", dotQuote(e.Source), dotQuote(e.Target))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// graphML represents a GraphML document.
+// Documentation is found here: http://graphml.graphdrawing.org/primer/graphml-primer.html
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string           `xml:"id,attr"`
+		EdgeDefault string           `xml:"edgedefault,attr"`
+		Nodes       []graphMLElement `xml:"node"`
+		Edges       []graphMLElement `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// graphMLKey represents the declaration of an attribute of the nodes.
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+// graphMLElement represents a node or an edge with its attributes.
+type graphMLElement struct {
+	ID     string        `xml:"id,attr,omitempty"`
+	Source string        `xml:"source,attr,omitempty"`
+	Target string        `xml:"target,attr,omitempty"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// graphMLData represents the value of an attribute.
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeGraphML will write the given graph to w as a GraphML document.
+// The nodes are identified by their index, with the title of the article as attribute.
+func writeGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{XMLNS: "http://graphml.graphdrawing.org/xmlns"}
+	for _, k := range []string{"title", "description", "url", "depth", "category"} {
+		typ := "string"
+		if k == "depth" {
+			typ = "int"
+		}
+		doc.Keys = append(doc.Keys, graphMLKey{ID: k, For: "node", Name: k, Type: typ})
+	}
+	doc.Graph.ID, doc.Graph.EdgeDefault = "G", "directed"
+
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+
+		data := []graphMLData{{Key: "title", Value: n.ID}}
+		if n.Description != "" {
+			data = append(data, graphMLData{Key: "description", Value: n.Description})
+		}
+		data = append(data, graphMLData{Key: "url", Value: n.URL}, graphMLData{Key: "depth", Value: fmt.Sprint(n.Depth)})
+		if n.Category != "" {
+			data = append(data, graphMLData{Key: "category", Value: n.Category})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLElement{ID: ids[n.ID], Data: data})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLElement{Source: ids[e.Source], Target: ids[e.Target]})
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, b)
+	return err
+}
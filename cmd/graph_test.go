@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildLinkGraph(t *testing.T) {
+	lang = "en"
+
+	node := func(title string, depth int) *GraphNode {
+		return &GraphNode{ID: title, URL: articleURL("en", title), Depth: depth}
+	}
+
+	tests := []struct {
+		name      string
+		title     string
+		depth     int
+		limit     int
+		wantNodes []*GraphNode
+		wantEdges []GraphEdge
+	}{
+		{
+			name:      "Depth 1",
+			title:     "Go",
+			depth:     1,
+			wantNodes: []*GraphNode{node("Go", 0), node("C", 1), node("Google", 1), node("Rob Pike", 1)},
+			wantEdges: []GraphEdge{{"Go", "C"}, {"Go", "Google"}, {"Go", "Rob Pike"}},
+		},
+		{
+			name:      "Depth 1 limited",
+			title:     "Go",
+			depth:     1,
+			limit:     2,
+			wantNodes: []*GraphNode{node("Go", 0), node("C", 1), node("Google", 1)},
+			wantEdges: []GraphEdge{{"Go", "C"}, {"Go", "Google"}},
+		},
+		{
+			name:      "Depth 2",
+			title:     "Go",
+			depth:     2,
+			wantNodes: []*GraphNode{node("Go", 0), node("C", 1), node("Google", 1), node("Rob Pike", 1), node("Unix", 2), node("Bell Labs", 2)},
+			wantEdges: []GraphEdge{{"Go", "C"}, {"Go", "Google"}, {"Go", "Rob Pike"}, {"C", "Unix"}, {"Rob Pike", "Bell Labs"}, {"Rob Pike", "Go"}},
+		},
+		{
+			name:      "Redirect not followed",
+			title:     "Ken Thompson",
+			depth:     2,
+			wantNodes: []*GraphNode{node("Ken Thompson", 0), node("Golang", 1), node("Unix", 1)},
+			wantEdges: []GraphEdge{{"Ken Thompson", "Golang"}, {"Ken Thompson", "Unix"}, {"Unix", "Ken Thompson"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			ts := newStubLinkGraphAPI(t, linkGraph, &requests)
+			w, err := NewWikiClient(ts.URL, "")
+			assert.NoError(t, err)
+
+			got, err := buildLinkGraph(w, tt.title, tt.depth, tt.limit, 2)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNodes, got.Nodes)
+			assert.Equal(t, tt.wantEdges, got.Edges)
+			assert.Equal(t, int32(tt.depth), requests.Load())
+		})
+	}
+}
+
+func TestGraphDescribeAndCluster(t *testing.T) {
+	var requests atomic.Int32
+	ts := newStubLinkGraphAPI(t, linkGraph, &requests)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+	lang = "en"
+
+	g, err := buildLinkGraph(w, "Go", 2, 0, 2)
+	assert.NoError(t, err)
+
+	assert.NoError(t, g.describe(w, 2))
+	assert.NoError(t, g.cluster(w, 2))
+
+	got := map[string][2]string{}
+	for _, n := range g.Nodes {
+		got[n.ID] = [2]string{n.Description, n.Category}
+	}
+	assert.Equal(t, map[string][2]string{
+		// The categories shared by the most articles first, by title on ties
+		"Go":        {"About Go", "Category:Google software"},
+		"C":         {"About C", "Category:Programming languages"},
+		"Google":    {"About Google", "Category:Google software"},
+		"Rob Pike":  {"About Rob Pike", ""},
+		"Unix":      {"About Unix", ""},
+		"Bell Labs": {"About Bell Labs", ""},
+	}, got)
+}
+
+func TestWriteGraph(t *testing.T) {
+	g := &Graph{
+		Nodes: []*GraphNode{
+			{ID: "Go", Description: "Programming language", URL: "https://en.wikipedia.org/wiki/Go", Category: "Category:Programming languages"},
+			{ID: "C", URL: "https://en.wikipedia.org/wiki/C", Depth: 1, Category: "Category:Programming languages"},
+			{ID: `Rob "Commander" Pike`, Description: "Programmer & author", URL: "https://en.wikipedia.org/wiki/Rob_Pike", Depth: 1},
+		},
+		Edges: []GraphEdge{{"Go", "C"}, {"Go", `Rob "Commander" Pike`}, {`Rob "Commander" Pike`, "Go"}},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		golden string
+	}{
+		{name: "Plain", format: "plain", golden: "graph_plain.golden"},
+		{name: "JSON", format: "json", golden: "graph_json.golden"},
+		{name: "DOT", format: "dot", golden: "graph_dot.golden"},
+		{name: "GraphML", format: "graphml", golden: "graph_graphml.golden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writeGraph(w, g, tt.format, false)
+
+			assert.NoError(t, err)
+			assertGolden(t, tt.golden, w.Bytes())
+		})
+	}
+}
+
+func TestValidateGraphFlags(t *testing.T) {
+	logLevel, logFormat = "error", "text"
+	t.Cleanup(func() {
+		output, templateText, graphDepth, graphLimit, concurrency = "plain", "", 1, 50, 4
+	})
+
+	graphDepth, graphLimit, concurrency = 1, 50, 4
+	templateText = "{{.}}"
+	for _, o := range slices.Concat(listOutputs, []string{"dot", "graphml"}) {
+		output = o
+		assert.NoError(t, validateGraphFlags(graphCmd, nil), o)
+	}
+
+	output = "geojson"
+	assert.Error(t, validateGraphFlags(graphCmd, nil))
+
+	output, graphDepth = "dot", 3
+	assert.Error(t, validateGraphFlags(graphCmd, nil))
+
+	graphDepth, graphLimit = 2, -1
+	assert.Error(t, validateGraphFlags(graphCmd, nil))
+}
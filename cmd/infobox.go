@@ -0,0 +1,457 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// commentRegexp matches the HTML comments of the wikitext
+	commentRegexp = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	// refRegexp matches the references of the wikitext, ie. "<ref name="a">...</ref>" or "<ref name="a" />"
+	refRegexp = regexp.MustCompile(`(?is)<ref[^>/]*/>|<ref[^>]*>.*?</ref>`)
+
+	// brRegexp matches the line breaks of the wikitext, ie. "<br>" or "<br />"
+	brRegexp = regexp.MustCompile(`(?i)<br\s*/?>`)
+
+	// tagRegexp matches the other HTML tags of the wikitext, whose content is kept
+	tagRegexp = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+	// fileLinkRegexp matches the links to images and files, which aren't text, ie. "[[File:Go Logo Blue.svg|80px]]"
+	fileLinkRegexp = regexp.MustCompile(`(?i)\[\[(?:file|image|fichier|datei|archivo):[^\[\]]*(?:\[\[[^\]]*\]\][^\[\]]*)*\]\]`)
+
+	// linkRegexp matches the internal links, ie. "[[Google]]" or "[[Google LLC|Google]]"
+	linkRegexp = regexp.MustCompile(`\[\[([^\[\]|]*)(?:\|([^\[\]]*))?\]\]`)
+
+	// externalLinkRegexp matches the external links, ie. "[https://go.dev]" or "[https://go.dev Go]"
+	externalLinkRegexp = regexp.MustCompile(`\[((?:https?:)?//[^\s\]]+)(?:\s+([^\]]*))?\]`)
+
+	// innerTemplateRegexp matches the templates without any template inside
+	innerTemplateRegexp = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+	// namedParamRegexp matches a named template parameter, ie. "df=yes"
+	namedParamRegexp = regexp.MustCompile(`^\s*[\w -]+\s*=`)
+
+	// generic infoboxes pair the labelN and dataN parameters
+	labelParamRegexp = regexp.MustCompile(`^label(\d+)$`)
+	dataParamRegexp  = regexp.MustCompile(`^data(\d+)$`)
+)
+
+// infoboxSkippedKeys represents the words of the infobox parameters which aren't facts, ie. "image_size" or "bodystyle"
+var infoboxSkippedKeys = []string{"image", "imagesize", "logo", "caption", "alt", "size", "upright", "signature", "screenshot", "map", "pushpin", "embed", "module", "child", "style", "bodystyle", "class", "bodyclass", "width"}
+
+// Infobox represents the infobox of a page, with its parameters as ordered key/value pairs.
+type Infobox struct {
+	// Name is the name of the infobox template, ie. "Infobox programming language"
+	Name   string         `json:"name" yaml:"name"`
+	Fields []InfoboxField `json:"fields" yaml:"fields"`
+}
+
+// InfoboxField represents a parameter of an infobox, ie. "developer: Google".
+type InfoboxField struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// String returns the fields of the infobox as "key: value" pairs separated by semicolons.
+func (i *Infobox) String() string {
+	pairs := make([]string, 0, len(i.Fields))
+	for _, f := range i.Fields {
+		pairs = append(pairs, f.Key+": "+f.Value)
+	}
+
+	return strings.Join(pairs, "; ")
+}
+
+// parseInfobox will look for the first infobox template of the given wikitext,
+// ie. "{{Infobox programming language | developer = [[Google]] ...}}".
+// The values are cleaned from their links, references and markup,
+// the common templates such as dates, units and lists being rendered as text.
+// The parameters without value, and the ones which aren't facts such as images, are left out.
+// It returns nil if the wikitext has no infobox.
+func parseInfobox(wikitext string) *Infobox {
+	text := commentRegexp.ReplaceAllString(wikitext, "")
+
+	body, ok := findInfobox(text)
+	if !ok {
+		return nil
+	}
+
+	params := splitTemplateParams(body)
+	infobox := &Infobox{Name: strings.TrimSpace(params[0])}
+
+	labels := map[string]string{}
+	for _, p := range params[1:] {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok {
+			continue
+		}
+		if m := labelParamRegexp.FindStringSubmatch(strings.TrimSpace(key)); m != nil {
+			labels[m[1]] = cleanWikitext(value)
+		}
+	}
+
+	for _, p := range params[1:] {
+		key, value, ok := strings.Cut(p, "=")
+		// Positional parameters aren't part of the facts,
+		// the equal sign being then part of an inner template or link
+		if !ok || strings.ContainsAny(key, "{[") {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		if labelParamRegexp.MatchString(key) || isSkippedInfoboxKey(key) {
+			continue
+		}
+		if m := dataParamRegexp.FindStringSubmatch(key); m != nil && labels[m[1]] != "" {
+			key = labels[m[1]]
+		}
+
+		value = cleanWikitext(value)
+		if key == "" || value == "" {
+			continue
+		}
+
+		infobox.Fields = append(infobox.Fields, InfoboxField{Key: key, Value: value})
+	}
+
+	return infobox
+}
+
+// findInfobox returns the content of the first template of text whose name starts with "infobox",
+// without its braces.
+func findInfobox(text string) (string, bool) {
+	for i := 0; ; {
+		start := strings.Index(text[i:], "{{")
+		if start < 0 {
+			return "", false
+		}
+		start += i
+
+		name, _, _ := strings.Cut(text[start+2:], "|")
+		name, _, _ = strings.Cut(name, "}}")
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(name)), "infobox") {
+			end, ok := matchingBraces(text, start)
+			if !ok {
+				return "", false
+			}
+			return text[start+2 : end-2], true
+		}
+
+		i = start + 2
+	}
+}
+
+// matchingBraces returns the offset following the "}}" closing the template starting at the given offset of text.
+func matchingBraces(text string, start int) (int, bool) {
+	depth := 0
+	for i := start; i < len(text)-1; {
+		switch text[i : i+2] {
+		case "{{":
+			depth++
+			i += 2
+		case "}}":
+			depth--
+			i += 2
+			if depth == 0 {
+				return i, true
+			}
+		default:
+			i++
+		}
+	}
+
+	return 0, false
+}
+
+// splitTemplateParams will split the content of a template on the pipes
+// which aren't part of an inner template or link. The first element is the name of the template.
+func splitTemplateParams(body string) []string {
+	var params []string
+
+	depth, start := 0, 0
+	for i := 0; i < len(body); i++ {
+		switch {
+		case strings.HasPrefix(body[i:], "{{") || strings.HasPrefix(body[i:], "[["):
+			depth++
+			i++
+		case strings.HasPrefix(body[i:], "}}") || strings.HasPrefix(body[i:], "]]"):
+			depth = max(depth-1, 0)
+			i++
+		case body[i] == '|' && depth == 0:
+			params = append(params, body[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(params, body[start:])
+}
+
+// isSkippedInfoboxKey will verify whether the given infobox parameter isn't a fact, ie. "image_size".
+func isSkippedInfoboxKey(key string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToLower(key), func(r rune) bool { return r == '_' || r == ' ' || r == '-' }) {
+		if isPresent(infoboxSkippedKeys, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cleanWikitext will turn the given wikitext value into text: the references, the files and the markup are removed,
+// the links are replaced with their label and the templates with their text, ie. the dates, the units or the lists.
+// The items of lists and the lines are separated with commas.
+func cleanWikitext(s string) string {
+	s = commentRegexp.ReplaceAllString(s, "")
+	s = refRegexp.ReplaceAllString(s, "")
+	s = brRegexp.ReplaceAllString(s, "\n")
+	s = fileLinkRegexp.ReplaceAllString(s, "")
+
+	// The links are replaced before the templates, as their pipes would split the parameters
+	s = linkRegexp.ReplaceAllStringFunc(s, func(link string) string {
+		m := linkRegexp.FindStringSubmatch(link)
+		if m[2] != "" {
+			return m[2]
+		}
+		return strings.TrimPrefix(m[1], ":")
+	})
+
+	// The innermost templates are rendered first
+	for range 10 {
+		rendered := innerTemplateRegexp.ReplaceAllStringFunc(s, func(t string) string {
+			return renderTemplate(innerTemplateRegexp.FindStringSubmatch(t)[1])
+		})
+		if rendered == s {
+			break
+		}
+		s = rendered
+	}
+
+	s = externalLinkRegexp.ReplaceAllStringFunc(s, func(link string) string {
+		m := externalLinkRegexp.FindStringSubmatch(link)
+		if m[2] != "" {
+			return m[2]
+		}
+		return m[1]
+	})
+
+	s = strings.NewReplacer("'''", "", "''", "").Replace(s)
+	s = tagRegexp.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(html.UnescapeString(s), "\u00a0", " ")
+
+	var items []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(strings.TrimLeft(line, "*#:; \t")), " ")
+		if line != "" {
+			items = append(items, line)
+		}
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// templateRenderers represents the templates of the infobox values rendered as text, by lowercased name.
+// They take the positional parameters of the template. The other templates are removed.
+var templateRenderers = map[string]func(params []string) string{
+	"start date":         renderDate,
+	"start date and age": renderDate,
+	"end date":           renderDate,
+	"end date and age":   renderDate,
+	"birth date":         renderDate,
+	"birth date and age": renderDate,
+	"death date":         renderDate,
+	"death date and age": renderDate,
+	"film date":          renderDate,
+	"release date":       renderDate,
+	"dts":                renderDate,
+	"birth year and age": renderDate,
+	"death year and age": renderDate,
+	"convert":            renderConvert,
+	"cvt":                renderConvert,
+	"coord":              renderCoord,
+	"url":                firstParam,
+	"official url":       firstParam,
+	"official website":   firstParam,
+	"nowrap":             firstParam,
+	"nobr":               firstParam,
+	"small":              firstParam,
+	"big":                firstParam,
+	"abbr":               firstParam,
+	"flag":               firstParam,
+	"flagcountry":        firstParam,
+	"flag country":       firstParam,
+	"marriage":           firstParam,
+	"lang":               lastParam,
+	"langx":              lastParam,
+	"native name":        lastParam,
+	"ubl":                listParams,
+	"ublist":             listParams,
+	"unbulleted list":    listParams,
+	"plainlist":          listParams,
+	"plain list":         listParams,
+	"flatlist":           listParams,
+	"flat list":          listParams,
+	"hlist":              listParams,
+	"bulleted list":      listParams,
+	"collapsible list":   listParams,
+	"indented plainlist": listParams,
+}
+
+// unitTemplates represents the templates of the infobox values whose named parameters are units,
+// by lowercased name, ie. "{{height|m=1.80}}". The units are rendered in the order of the parameters.
+var unitTemplates = map[string][]string{
+	"height": {"m", "cm", "ft", "in"},
+	"weight": {"kg", "g", "lb", "st", "oz"},
+}
+
+// renderTemplate returns the text of the template with the given content, ie. "start date|2009|11|10".
+func renderTemplate(content string) string {
+	params := splitTemplateParams(content)
+	name := strings.ToLower(strings.TrimSpace(params[0]))
+
+	// Parser functions, ie. "{{formatnum:1234}}"
+	if fn, arg, ok := strings.Cut(name, ":"); ok {
+		if fn == "formatnum" {
+			_, arg, _ = strings.Cut(params[0], ":")
+			return strings.TrimSpace(arg)
+		}
+		return ""
+	}
+
+	name = strings.ReplaceAll(name, "_", " ")
+	if units, ok := unitTemplates[name]; ok {
+		return renderUnits(params[1:], units)
+	}
+
+	render, ok := templateRenderers[name]
+	if !ok {
+		return ""
+	}
+
+	var positional []string
+	for _, p := range params[1:] {
+		if !namedParamRegexp.MatchString(p) {
+			positional = append(positional, strings.TrimSpace(p))
+		}
+	}
+
+	return render(positional)
+}
+
+// firstParam returns the first parameter of a template, ie. "go.dev" for "{{URL|go.dev}}".
+func firstParam(params []string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return params[0]
+}
+
+// lastParam returns the last parameter of a template, ie. "Paris" for "{{lang|fr|Paris}}".
+func lastParam(params []string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return params[len(params)-1]
+}
+
+// listParams returns the items of a list template, one per line.
+func listParams(params []string) string {
+	return strings.Join(params, "\n")
+}
+
+// renderDate returns the date of a date template as "2009-11-10", "2009-11" or "2009",
+// ie. "{{start date and age|2009|11|10}}".
+// The templates with two dates, ie. "{{death date and age|1977|8|16|1935|1|8}}", return the first one.
+func renderDate(params []string) string {
+	var parts []int
+	for _, p := range params {
+		n, err := strconv.Atoi(p)
+		if err != nil || len(parts) == 3 {
+			break
+		}
+		parts = append(parts, n)
+	}
+
+	switch len(parts) {
+	case 0:
+		return firstParam(params)
+	case 1:
+		return strconv.Itoa(parts[0])
+	case 2:
+		return fmt.Sprintf("%d-%02d", parts[0], parts[1])
+	default:
+		return fmt.Sprintf("%d-%02d-%02d", parts[0], parts[1], parts[2])
+	}
+}
+
+// renderConvert returns the value and unit of a convert template, without the conversion,
+// ie. "105.4 km2" for "{{convert|105.4|km2|sqmi}}" or "10–20 km" for "{{convert|10|-|20|km}}".
+func renderConvert(params []string) string {
+	if len(params) >= 4 && isPresent([]string{"-", "–", "to", "and", "or"}, params[1]) {
+		sep := "–"
+		if params[1] != "-" && params[1] != "–" {
+			sep = " " + params[1] + " "
+		}
+		return params[0] + sep + params[2] + " " + params[3]
+	}
+
+	if len(params) >= 2 {
+		return params[0] + " " + params[1]
+	}
+
+	return firstParam(params)
+}
+
+// renderUnits returns the values of the given unit parameters of a template followed by their unit,
+// ie. "1.80 m" for "{{height|m=1.80}}" or "5 ft 11 in" for "{{height|ft=5|in=11}}".
+// The other parameters, ie. the precision, are left out.
+func renderUnits(params []string, units []string) string {
+	var values []string
+	for _, p := range params {
+		unit, value, ok := strings.Cut(p, "=")
+		unit, value = strings.ToLower(strings.TrimSpace(unit)), strings.TrimSpace(value)
+		if ok && value != "" && isPresent(units, unit) {
+			values = append(values, value+" "+unit)
+		}
+	}
+
+	return strings.Join(values, " ")
+}
+
+// renderCoord returns the coordinates of a coord template in decimal degrees, ie. "45.76, 4.84" for "{{coord|45.76|4.84}}",
+// or in degrees, minutes and seconds, ie. "45°46′N 4°50′E" for "{{coord|45|46|N|4|50|E}}".
+func renderCoord(params []string) string {
+	var numbers []string
+	var parts []string
+	for _, p := range params {
+		if _, err := strconv.ParseFloat(p, 64); err == nil {
+			numbers = append(numbers, p)
+			continue
+		}
+
+		if !isPresent([]string{"N", "S", "E", "W"}, p) {
+			break
+		}
+
+		// The hemisphere ends a degrees, minutes and seconds group
+		var b strings.Builder
+		for i, n := range numbers {
+			b.WriteString(n + []string{"°", "′", "″"}[min(i, 2)])
+		}
+		parts = append(parts, b.String()+p)
+		numbers = nil
+	}
+
+	if len(parts) == 2 {
+		return strings.Join(parts, " ")
+	}
+	if len(parts) == 0 && len(numbers) == 2 {
+		return numbers[0] + ", " + numbers[1]
+	}
+
+	return ""
+}
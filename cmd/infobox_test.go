@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInfobox(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+	}{
+		{name: "Programming language", fixture: "infobox_programming_language"},
+		{name: "Settlement", fixture: "infobox_settlement"},
+		{name: "Person", fixture: "infobox_person"},
+		{name: "Generic infobox with labels", fixture: "infobox_generic"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wikitext, err := os.ReadFile(filepath.Join("testdata", tt.fixture+".wikitext"))
+			assert.NoError(t, err)
+
+			infobox := parseInfobox(string(wikitext))
+			assert.NotNil(t, infobox)
+
+			b, err := json.MarshalIndent(infobox, "", "    ")
+			assert.NoError(t, err)
+
+			assertGolden(t, tt.fixture+".golden", append(b, '\n'))
+		})
+	}
+
+	t.Run("Without infobox", func(t *testing.T) {
+		assert.Nil(t, parseInfobox("{{Short description|A page}}\n'''Go''' is a [[board game]]."))
+	})
+
+	t.Run("Unclosed infobox", func(t *testing.T) {
+		assert.Nil(t, parseInfobox("{{Infobox game\n| players = 2"))
+	})
+}
+
+func TestCleanWikitext(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "Text", s: " Google ", want: "Google"},
+		{name: "Links", s: "[[Google]] and [[Alphabet Inc.|Alphabet]]", want: "Google and Alphabet"},
+		{name: "External links", s: "[https://go.dev go.dev] [https://golang.org]", want: "go.dev https://golang.org"},
+		{name: "References", s: `BSD<ref name="license">{{cite web|url=https://go.dev/LICENSE}}</ref><ref name="a" />`, want: "BSD"},
+		{name: "Comments and markup", s: "<!-- comment -->'''Go''' ''language'' <small>(gc)</small>", want: "Go language (gc)"},
+		{name: "Line breaks", s: "[[Rob Pike]]<br />[[Ken Thompson]]<br>", want: "Rob Pike, Ken Thompson"},
+		{name: "Bulleted list", s: "\n* [[Unix]]\n* [[UTF-8]]\n", want: "Unix, UTF-8"},
+		{name: "Files", s: "[[File:Go.svg|thumb|The [[gopher]]]] Go", want: "Go"},
+		{name: "Entities", s: "1&nbsp;km &amp; 2&nbsp;km", want: "1 km & 2 km"},
+		{name: "Nested templates", s: "{{nowrap|{{convert|1|km|mi}}}}", want: "1 km"},
+		{name: "Unknown template", s: "{{flagicon|FRA}} France", want: "France"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cleanWikitext(tt.s))
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "Date", content: "start date and age|2009|11|10|df=yes", want: "2009-11-10"},
+		{name: "Month", content: "Start date|2009|11", want: "2009-11"},
+		{name: "Year", content: "start_date|2009", want: "2009"},
+		{name: "Text date", content: "start date|43 BC", want: "43 BC"},
+		{name: "Two dates", content: "death date and age|1977|8|16|1935|1|8", want: "1977-08-16"},
+		{name: "Convert", content: "convert|105.4|km2|sqmi|abbr=on", want: "105.4 km2"},
+		{name: "Convert range", content: "cvt|10|-|20|km", want: "10–20 km"},
+		{name: "Convert range with words", content: "convert|10|to|20|km", want: "10 to 20 km"},
+		{name: "Height", content: "height|m=1.80", want: "1.80 m"},
+		{name: "Height in feet and inches", content: "height|ft=5|in=11|precision=0", want: "5 ft 11 in"},
+		{name: "Weight", content: "Weight|kg=75|lb=", want: "75 kg"},
+		{name: "Decimal coordinates", content: "coord|45.76|4.84|display=inline", want: "45.76, 4.84"},
+		{name: "Degrees coordinates", content: "coord|45|46|12|N|4|50|E|region:FR", want: "45°46′12″N 4°50′E"},
+		{name: "URL", content: "URL|https://go.dev|go.dev", want: "https://go.dev"},
+		{name: "Language", content: "lang|fr|Lyon", want: "Lyon"},
+		{name: "List", content: "ubl|Unix|UTF-8", want: "Unix\nUTF-8"},
+		{name: "Parser function", content: "formatnum:1234567", want: "1234567"},
+		{name: "Other parser function", content: "#if:a|b", want: ""},
+		{name: "Unknown template", content: "cite web|url=https://go.dev", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderTemplate(tt.content))
+		})
+	}
+}
+
+func TestInfoboxString(t *testing.T) {
+	i := &Infobox{Name: "Infobox software", Fields: []InfoboxField{{Key: "developer", Value: "Google"}, {Key: "license", Value: "BSD"}}}
+
+	assert.Equal(t, "developer: Google; license: BSD", i.String())
+}
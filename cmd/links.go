@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultRefsLimit is the default value of the 'limit' flag of the 'categories', 'links' and 'backlinks' commands
+const defaultRefsLimit = 500
+
+var (
+	refsLimit       int   // maximum number of pages listed by the 'categories', 'links' and 'backlinks' commands, not limited when 0
+	refsNamespaces  []int // namespaces of the pages listed by the 'links' and 'backlinks' commands, any namespace when empty
+	hiddenRefs      bool  // whether or not to list the hidden categories
+	followRedirects bool  // whether or not to list the pages linking to the redirects of the page
+
+	// categoriesCmd represents the 'categories' command
+	categoriesCmd = &cobra.Command{
+		Use:   "categories <title>",
+		Short: "List the categories of an article",
+		Long: `List the categories of the Wikipedia article best matching the given title.
+
+The hidden categories, used to maintain the articles, are only listed with the '--hidden' flag, ie.
+  wpdia-go categories golang
+  wpdia-go categories --hidden --output json golang`,
+
+		PreRunE: validateRefsFlags,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			runRefs(args[0], "categories", []string{"title", "hidden"}, func(w *WikiClient, id uint64) ([]PageRef, error) {
+				return w.GetCategories(id, hiddenRefs, refsLimit)
+			})
+		},
+	}
+
+	// linksCmd represents the 'links' command
+	linksCmd = &cobra.Command{
+		Use:   "links <title>",
+		Short: "List the pages an article links to",
+		Long: `List the pages the Wikipedia article best matching the given title links to.
+
+The links can be restricted to some namespaces with the '--namespace' flag, ie. the articles only:
+  wpdia-go links --namespace 0 golang`,
+
+		PreRunE: validateRefsFlags,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			runRefs(args[0], "links", []string{"ns", "title"}, func(w *WikiClient, id uint64) ([]PageRef, error) {
+				return w.GetLinks(id, refsNamespaces, refsLimit)
+			})
+		},
+	}
+
+	// backlinksCmd represents the 'backlinks' command
+	backlinksCmd = &cobra.Command{
+		Use:   "backlinks <title>",
+		Short: "List the pages linking to an article",
+		Long: `List the pages linking to the Wikipedia article best matching the given title.
+
+The redirects to the article are listed as such, followed by the pages linking to the article
+through them, unless the '--redirects=false' flag is given.
+The pages can be restricted to some namespaces with the '--namespace' flag, ie. the articles only:
+  wpdia-go backlinks --namespace 0 golang`,
+
+		PreRunE: validateRefsFlags,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			runRefs(args[0], "backlinks", []string{"pageid", "ns", "title", "redirect", "via"}, func(w *WikiClient, id uint64) ([]PageRef, error) {
+				return w.GetBacklinks(id, refsNamespaces, followRedirects, refsLimit)
+			})
+		},
+	}
+)
+
+func init() {
+	for _, c := range []*cobra.Command{categoriesCmd, linksCmd, backlinksCmd} {
+		c.Flags().IntVar(&refsLimit, "limit", defaultRefsLimit, "Maximum number of pages to list, following the continuation of the results. Not limited when 0.")
+		rootCmd.AddCommand(c)
+	}
+
+	categoriesCmd.Flags().BoolVar(&hiddenRefs, "hidden", false, "List the hidden categories too.")
+
+	for _, c := range []*cobra.Command{linksCmd, backlinksCmd} {
+		c.Flags().IntSliceVar(&refsNamespaces, "namespace", nil, "Namespaces of the pages to list, ie. '0' for the articles or '0,14' for the articles and the categories. Any namespace when empty.")
+	}
+
+	backlinksCmd.Flags().BoolVar(&followRedirects, "redirects", true, "List the pages linking to the article through its redirects.")
+}
+
+// validateRefsFlags will determine whether the flags of the 'categories', 'links' and 'backlinks' commands are valid.
+func validateRefsFlags(cmd *cobra.Command, args []string) error {
+	if refsLimit < 0 {
+		return fmt.Errorf("error: invalid value for flag 'limit': %d. Must be positive", refsLimit)
+	}
+	for _, ns := range refsNamespaces {
+		if ns < 0 {
+			return fmt.Errorf("error: invalid value for flag 'namespace': %d. Must be positive", ns)
+		}
+	}
+
+	return validateListFlags(cmd, args)
+}
+
+// runRefs will search for the given title, list the pages it refers to with get
+// and write them to the standard output with the given columns in the 'csv', 'tsv' and 'html' outputs.
+// It exits the program on error.
+func runRefs(title, what string, columns []string, get func(w *WikiClient, id uint64) ([]PageRef, error)) {
+	w, err := NewWikiClient(APIBaseURL, "")
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	p := searchPage(w, title)
+
+	logger.Info("Getting "+what+"...", slog.Uint64("pageid", p.Pageid))
+
+	refs, err := get(w, p.Pageid)
+	if err != nil {
+		logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.Uint64("pageid", p.Pageid))
+		os.Exit(1)
+	}
+
+	if err := writePageRefs(os.Stdout, p.Title+": "+what, refs, output, columns, header); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// writePageRefs will write the given pages to w in the given output format, the list being described by title.
+// The csv, tsv and html outputs have the given columns, the csv and tsv outputs starting with a row of their names
+// when header is true. The plain output is a page title per line, the markdown output a list of links to the pages.
+func writePageRefs(w io.Writer, title string, refs []PageRef, format string, columns []string, header bool) error {
+	t := Table{Columns: columns}
+	for _, r := range refs {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = r.Field(c)
+		}
+		t.Rows = append(t.Rows, record)
+	}
+
+	// write writes a line per page, the pages linking through a redirect being nested below it
+	write := func(w io.Writer, line func(r PageRef) string) error {
+		for _, r := range refs {
+			indent := ""
+			if r.Via != "" {
+				indent = "  "
+			}
+			if _, err := fmt.Fprintf(w, "%s%s%s\n", indent, line(r), r.annotation()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return writeList(w, List{
+		Title: title,
+		Value: refs,
+		Table: t,
+		Plain: func(w io.Writer) error {
+			return write(w, func(r PageRef) string { return r.Title })
+		},
+		Markdown: func(w io.Writer) error {
+			return write(w, func(r PageRef) string { return "- " + markdownLink(r.Title, articleURL(lang, r.Title)) })
+		},
+	}, format, header)
+}
+
+// Field returns the value of the given field of the page as text, as written in the 'csv' and 'tsv' outputs.
+// The boolean fields are empty when false.
+func (r PageRef) Field(name string) string {
+	switch name {
+	case "pageid":
+		if r.Pageid == 0 {
+			return ""
+		}
+		return strconv.FormatUint(r.Pageid, 10)
+	case "ns":
+		return strconv.Itoa(r.Ns)
+	case "title":
+		return r.Title
+	case "hidden":
+		return boolField(r.Hidden)
+	case "redirect":
+		return boolField(r.Redirect)
+	case "via":
+		return r.Via
+	}
+
+	return ""
+}
+
+// annotation returns the properties of the page worth noting after its title in the plain and markdown outputs,
+// ie. " (hidden)" for a hidden category.
+func (r PageRef) annotation() string {
+	switch {
+	case r.Hidden:
+		return " (hidden)"
+	case r.Redirect:
+		return " (redirect)"
+	}
+
+	return ""
+}
+
+// boolField returns "true" when b is true, an empty string otherwise.
+func boolField(b bool) string {
+	if b {
+		return "true"
+	}
+
+	return ""
+}
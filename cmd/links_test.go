@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePageRefs(t *testing.T) {
+	lang = "en"
+
+	backlinks := []PageRef{
+		{Pageid: 1, Ns: 0, Title: "Gopher"},
+		{Pageid: 2, Ns: 0, Title: "Golang", Redirect: true},
+		{Pageid: 3, Ns: 0, Title: "Rob Pike", Via: "Golang"},
+	}
+	categories := []PageRef{
+		{Ns: 14, Title: "Category:Programming languages"},
+		{Ns: 14, Title: "Category:Articles with short description", Hidden: true},
+	}
+
+	tests := []struct {
+		name    string
+		refs    []PageRef
+		format  string
+		columns []string
+		header  bool
+		want    string
+	}{
+		{
+			name:   "Plain",
+			refs:   backlinks,
+			format: "plain",
+			want:   "Gopher\nGolang (redirect)\n  Rob Pike\n",
+		},
+		{
+			name:   "Plain hidden category",
+			refs:   categories,
+			format: "plain",
+			want:   "Category:Programming languages\nCategory:Articles with short description (hidden)\n",
+		},
+		{
+			name:   "Plain without page",
+			refs:   nil,
+			format: "plain",
+			want:   "",
+		},
+		{
+			name:   "JSON",
+			refs:   backlinks[1:],
+			format: "json",
+			want:   "[\n    {\n        \"pageid\": 2,\n        \"ns\": 0,\n        \"title\": \"Golang\",\n        \"redirect\": true\n    },\n    {\n        \"pageid\": 3,\n        \"ns\": 0,\n        \"title\": \"Rob Pike\",\n        \"via\": \"Golang\"\n    }\n]\n",
+		},
+		{
+			name:   "JSON without page",
+			refs:   nil,
+			format: "json",
+			want:   "[]\n",
+		},
+		{
+			name:   "YAML",
+			refs:   categories[1:],
+			format: "yaml",
+			want:   "- ns: 14\n  title: Category:Articles with short description\n  hidden: true\n",
+		},
+		{
+			name:   "NDJSON",
+			refs:   categories,
+			format: "ndjson",
+			want:   "{\"ns\":14,\"title\":\"Category:Programming languages\"}\n{\"ns\":14,\"title\":\"Category:Articles with short description\",\"hidden\":true}\n",
+		},
+		{
+			name:    "CSV",
+			refs:    backlinks,
+			format:  "csv",
+			columns: []string{"pageid", "ns", "title", "redirect", "via"},
+			header:  true,
+			want:    "pageid,ns,title,redirect,via\n1,0,Gopher,,\n2,0,Golang,true,\n3,0,Rob Pike,,Golang\n",
+		},
+		{
+			name:    "TSV without header",
+			refs:    categories,
+			format:  "tsv",
+			columns: []string{"title", "hidden"},
+			want:    "Category:Programming languages\t\nCategory:Articles with short description\ttrue\n",
+		},
+		{
+			name:   "Template",
+			refs:   backlinks,
+			format: "template",
+			want:   "Gopher, Golang, Rob Pike\n",
+		},
+		{
+			name:   "Markdown",
+			refs:   backlinks,
+			format: "markdown",
+			want:   "- [Gopher](https://en.wikipedia.org/wiki/Gopher)\n- [Golang](https://en.wikipedia.org/wiki/Golang) (redirect)\n  - [Rob Pike](https://en.wikipedia.org/wiki/Rob_Pike)\n",
+		},
+		{
+			name:   "Markdown with brackets",
+			refs:   []PageRef{{Title: "[Citation needed]"}},
+			format: "markdown",
+			want:   "- [\\[Citation needed\\]](" + articleURL("en", "[Citation needed]") + ")\n",
+		},
+	}
+	templateText = `{{range $i, $r := .}}{{if $i}}, {{end}}{{$r.Title}}{{end}}`
+	t.Cleanup(func() { templateText = "" })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writePageRefs(w, "Golang: backlinks", tt.refs, tt.format, tt.columns, tt.header)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+}
+
+func TestValidateRefsFlags(t *testing.T) {
+	logLevel, logFormat = "error", "text"
+	t.Cleanup(func() {
+		output, templateText, refsLimit, refsNamespaces = "plain", "", defaultRefsLimit, nil
+	})
+
+	templateText = "{{range .}}{{.Title}}\n{{end}}"
+	for _, o := range listOutputs {
+		output = o
+		assert.NoError(t, validateRefsFlags(linksCmd, nil), o)
+	}
+
+	output = "dot"
+	assert.ErrorContains(t, validateRefsFlags(backlinksCmd, nil), "'backlinks' command")
+
+	output, refsLimit = "plain", -1
+	assert.Error(t, validateRefsFlags(categoriesCmd, nil))
+
+	refsLimit, refsNamespaces = 0, []int{0, -1}
+	assert.Error(t, validateRefsFlags(linksCmd, nil))
+}
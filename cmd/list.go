@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/rivo/uniseg"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// listOutputs represents the builtin output formats writing the lists of the commands listing items,
+	// ie. the 'links' or 'toc' commands. The external formatters write lists too.
+	listOutputs = []string{"plain", "pretty", "json", "yaml", "markdown", "template", "ndjson", "csv", "tsv", "html"}
+
+	// pageFlags represents the persistent flags which only apply to the pages,
+	// rejected by the commands listing items
+	pageFlags = []string{"exsentences", "exintro", "full", "front-matter", "fields", "wrap", "output-file", "overwrite", "random"}
+)
+
+// htmlListTemplate is the template of the standalone HTML document of the 'html' output of a list
+//
+//go:embed assets/list.html.tmpl
+var htmlListTemplate string
+
+// htmlList is the parsed template of the 'html' output of a list
+var htmlList = htmltemplate.Must(htmltemplate.New("list").Parse(htmlListTemplate))
+
+// List represents the output of a command listing items, ie. the links of an article,
+// written in any of the list output formats by writeList.
+type List struct {
+	// Title describes the list, ie. "Go (programming language): links". It is the title of the html output.
+	Title string
+
+	// Value is encoded by the json, yaml and ndjson outputs, rendered by the template output
+	// and given to the external formatters. The ndjson output has a line per item when it is a slice.
+	// A nil slice is written as an empty list.
+	Value any
+
+	// Table is the list as rows, written by the csv, tsv and html outputs,
+	// and by the plain and markdown outputs when Plain and Markdown are nil
+	Table Table
+
+	// Plain writes the list in the plain output, when not nil
+	Plain func(w io.Writer) error
+
+	// Markdown writes the list in the markdown output, and in the pretty output once rendered, when not nil
+	Markdown func(w io.Writer) error
+}
+
+// isListOutput returns whether the given value of the 'output' flag writes lists:
+// a builtin format of listOutputs, one of their aliases, or an external formatter.
+func isListOutput(name string) bool {
+	info, ok := displayers.lookup(name)
+
+	return ok && (info.path != "" || isPresent(listOutputs, info.Name))
+}
+
+// validateListFlags will determine whether the flags of the commands listing items are valid.
+// The 'output' flag is either a list output or one of the given formats of the command,
+// and the flags which only apply to the pages are rejected.
+func validateListFlags(cmd *cobra.Command, args []string, formats ...string) error {
+	if !isPresent(formats, output) && !isListOutput(output) {
+		return fmt.Errorf("error: invalid value for flag 'output'. Valid values for the '%s' command are %v, or the name of an external formatter '%s<name>' in $PATH", cmd.Name(), slices.Concat(listOutputs, formats), externalFormatterPrefix)
+	}
+
+	for _, name := range pageFlags {
+		if f := cmd.Flag(name); f != nil && f.Changed {
+			return fmt.Errorf("error: flag '%s' doesn't apply to the '%s' command", name, cmd.Name())
+		}
+	}
+
+	return validateCommonFlags(cmd, args)
+}
+
+// searchPage will search for the page best matching the given title with w.
+// It exits the program when the search fails or doesn't match anything.
+func searchPage(w *WikiClient, title string) *WikiSearchResult {
+	logger.Info("Searching title...", slog.String("title", title))
+
+	p, err := w.FindPage(title)
+	if errors.Is(err, ErrPageNotFound) {
+		logger.Error("Error: no page found on Wikipedia for the given query", slog.String("title", title))
+		os.Exit(1)
+	}
+	if err != nil {
+		logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.String("title", title))
+		os.Exit(1)
+	}
+
+	return p
+}
+
+// writeList will write the given list to w in the given output format.
+// The csv and tsv outputs start with a row of the column names when header is true and the table has some.
+func writeList(w io.Writer, l List, format string, header bool) error {
+	info, ok := displayers.lookup(format)
+	if !ok {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	// Always output a list in the structured formats
+	if v := reflect.ValueOf(l.Value); v.Kind() == reflect.Slice && v.IsNil() {
+		l.Value = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+
+	if info.path != "" {
+		return NewExternalFormat(info.path).run(w, l.Value, lang)
+	}
+
+	switch info.Name {
+	case "plain":
+		if l.Plain != nil {
+			return l.Plain(w)
+		}
+		return writeAlignedTable(w, l.Table)
+
+	case "markdown":
+		return l.markdown(w)
+
+	case "pretty":
+		var b strings.Builder
+		if err := l.markdown(&b); err != nil {
+			return err
+		}
+		return NewPrettyFormat(prettyOptions()).writeMarkdown(w, b.String())
+
+	case "json":
+		b, err := json.MarshalIndent(l.Value, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+
+	case "yaml":
+		b, err := yaml.Marshal(l.Value)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+
+	case "ndjson":
+		items := []any{l.Value}
+		if v := reflect.ValueOf(l.Value); v.Kind() == reflect.Slice {
+			items = make([]any, v.Len())
+			for i := range items {
+				items[i] = v.Index(i).Interface()
+			}
+		}
+
+		for _, item := range items {
+			b, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, string(b)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "csv", "tsv":
+		var records [][]string
+		if header && len(l.Table.Columns) > 0 {
+			records = append(records, l.Table.Columns)
+		}
+		records = append(records, l.Table.Rows...)
+
+		comma := ','
+		if info.Name == "tsv" {
+			comma = '\t'
+		}
+		return writeDelimited(w, records, comma)
+
+	case "template":
+		text, err := readTemplate()
+		if err != nil {
+			return err
+		}
+		d, err := NewTemplateFormat(text)
+		if err != nil {
+			return err
+		}
+		return d.execute(w, l.Value, lang)
+
+	case "html":
+		return htmlList.Execute(w, struct {
+			Lang    string
+			Title   string
+			Table   Table
+			Version string
+		}{
+			Lang:    lang,
+			Title:   l.Title,
+			Table:   l.Table,
+			Version: version,
+		})
+	}
+
+	return fmt.Errorf("the %q output doesn't support lists", format)
+}
+
+// markdown will write the list to w as markdown: with the Markdown function when set, as a table otherwise.
+func (l List) markdown(w io.Writer) error {
+	if l.Markdown != nil {
+		return l.Markdown(w)
+	}
+
+	return writeMarkdownTable(w, l.Table)
+}
+
+// writeAlignedTable will write the given table to w with its columns aligned,
+// preceded by its caption and the names of its columns when it has some.
+func writeAlignedTable(w io.Writer, t Table) error {
+	var records [][]string
+	if len(t.Columns) > 0 {
+		records = append(records, t.Columns)
+	}
+	records = append(records, t.Rows...)
+
+	var widths []int
+	for _, r := range records {
+		for i, f := range r {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], uniseg.StringWidth(f))
+		}
+	}
+
+	if t.Caption != "" {
+		_, err := fmt.Fprintf(w, "%s\n\n", t.Caption)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, r := range records {
+		fields := make([]string, len(r))
+		for i, f := range r {
+			fields[i] = f + strings.Repeat(" ", widths[i]-uniseg.StringWidth(f))
+		}
+
+		_, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(fields, "  "), " "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markdownLinkEscaper escapes the characters which can't be part of the text of a markdown link
+var markdownLinkEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// markdownLink returns a markdown link to url with the given text, ie. "[Go](https://en.wikipedia.org/wiki/Go)".
+func markdownLink(text, url string) string {
+	return "[" + markdownLinkEscaper.Replace(text) + "](" + url + ")"
+}
+
+// markdownCellEscaper escapes the characters which can't be part of the cell of a markdown table
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// writeMarkdownTable will write the given table to w as a markdown table, preceded by its caption.
+// A table without column names gets empty ones, as markdown tables require a header row.
+// Nothing is written for an empty table.
+func writeMarkdownTable(w io.Writer, t Table) error {
+	width := len(t.Columns)
+	for _, r := range t.Rows {
+		width = max(width, len(r))
+	}
+	if width == 0 {
+		return nil
+	}
+
+	row := func(cells []string) string {
+		fields := make([]string, width)
+		for i, c := range cells {
+			fields[i] = markdownCellEscaper.Replace(c)
+		}
+		return "| " + strings.Join(fields, " | ") + " |\n"
+	}
+
+	var b strings.Builder
+	if t.Caption != "" {
+		b.WriteString(t.Caption + "\n\n")
+	}
+	b.WriteString(row(t.Columns))
+	b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+	for _, r := range t.Rows {
+		b.WriteString(row(r))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countries is a list of countries with their capital
+var countries = List{
+	Title: "Countries",
+	Value: []struct {
+		Name    string `json:"name" yaml:"name"`
+		Capital string `json:"capital" yaml:"capital"`
+	}{
+		{Name: "France", Capital: "Paris"},
+		{Name: "Côte d'Ivoire", Capital: "Yamoussoukro"},
+	},
+	Table: Table{
+		Columns: []string{"name", "capital"},
+		Rows:    [][]string{{"France", "Paris"}, {"Côte d'Ivoire", "Yamoussoukro"}},
+	},
+}
+
+func TestWriteList(t *testing.T) {
+	lang = "en"
+	templateText = `{{range .}}{{.Capital}} is the capital of {{.Name}}{{"\n"}}{{end}}`
+	t.Cleanup(func() { templateText = "" })
+
+	custom := countries
+	custom.Plain = func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, "France, Côte d'Ivoire")
+		return err
+	}
+	custom.Markdown = func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, "- France\n- Côte d'Ivoire")
+		return err
+	}
+
+	tests := []struct {
+		name   string
+		list   List
+		format string
+		header bool
+		want   string
+	}{
+		{
+			name:   "Plain table",
+			list:   countries,
+			format: "plain",
+			want:   "name           capital\nFrance         Paris\nCôte d'Ivoire  Yamoussoukro\n",
+		},
+		{
+			name:   "Plain",
+			list:   custom,
+			format: "plain",
+			want:   "France, Côte d'Ivoire\n",
+		},
+		{
+			name:   "Markdown table",
+			list:   countries,
+			format: "md",
+			want:   "| name | capital |\n| --- | --- |\n| France | Paris |\n| Côte d'Ivoire | Yamoussoukro |\n",
+		},
+		{
+			name:   "Markdown",
+			list:   custom,
+			format: "markdown",
+			want:   "- France\n- Côte d'Ivoire\n",
+		},
+		{
+			name:   "Markdown empty table",
+			list:   List{},
+			format: "markdown",
+			want:   "",
+		},
+		{
+			name:   "JSON",
+			list:   countries,
+			format: "json",
+			want:   "[\n    {\n        \"name\": \"France\",\n        \"capital\": \"Paris\"\n    },\n    {\n        \"name\": \"Côte d'Ivoire\",\n        \"capital\": \"Yamoussoukro\"\n    }\n]\n",
+		},
+		{
+			name:   "JSON of an empty list",
+			list:   List{Value: []string(nil)},
+			format: "json",
+			want:   "[]\n",
+		},
+		{
+			name:   "YAML",
+			list:   countries,
+			format: "yaml",
+			want:   "- name: France\n  capital: Paris\n- name: Côte d'Ivoire\n  capital: Yamoussoukro\n",
+		},
+		{
+			name:   "NDJSON",
+			list:   countries,
+			format: "ndjson",
+			want:   "{\"name\":\"France\",\"capital\":\"Paris\"}\n{\"name\":\"Côte d'Ivoire\",\"capital\":\"Yamoussoukro\"}\n",
+		},
+		{
+			name:   "NDJSON of a single value",
+			list:   List{Value: map[string]int{"links": 2}},
+			format: "ndjson",
+			want:   "{\"links\":2}\n",
+		},
+		{
+			name:   "YAML of an empty list",
+			list:   List{Value: []string(nil)},
+			format: "yaml",
+			want:   "[]\n",
+		},
+		{
+			name:   "CSV",
+			list:   countries,
+			format: "csv",
+			header: true,
+			want:   "name,capital\nFrance,Paris\nCôte d'Ivoire,Yamoussoukro\n",
+		},
+		{
+			name:   "TSV without header",
+			list:   List{Table: Table{Columns: []string{"name"}, Rows: [][]string{{"Tab\there"}}}},
+			format: "tsv",
+			want:   "Tab\\there\n",
+		},
+		{
+			name:   "Template",
+			list:   countries,
+			format: "template",
+			want:   "Paris is the capital of France\nYamoussoukro is the capital of Côte d'Ivoire\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writeList(w, tt.list, tt.format, tt.header)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+
+	t.Run("Pretty", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		width, theme = 80, "notty"
+		t.Cleanup(func() { width, theme = 0, "auto" })
+
+		w := &bytes.Buffer{}
+		assert.NoError(t, writeList(w, custom, "pretty", false))
+		assert.Contains(t, w.String(), "• France")
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, writeList(w, countries, "html", false))
+		assertGolden(t, "list_html.golden", w.Bytes())
+	})
+}
+
+func TestMarkdownLink(t *testing.T) {
+	assert.Equal(t, "[Go](https://en.wikipedia.org/wiki/Go)", markdownLink("Go", "https://en.wikipedia.org/wiki/Go"))
+	assert.Equal(t, `[\[Citation needed\] \\o/](https://en.wikipedia.org/wiki/X)`, markdownLink(`[Citation needed] \o/`, "https://en.wikipedia.org/wiki/X"))
+}
+
+func TestWriteListExternalFormat(t *testing.T) {
+	newExternalFormatter(t, "test", "read -r line\necho \"$WPDIA_GO_LANG $line\"\n")
+	lang = "fr"
+	t.Cleanup(func() { lang = "en" })
+
+	w := &bytes.Buffer{}
+	assert.NoError(t, writeList(w, List{Value: []string{"Paris", "Lyon"}}, "test", false))
+	assert.Equal(t, "fr [\"Paris\",\"Lyon\"]\n", w.String())
+}
+
+func TestWriteListRegisteredDisplayer(t *testing.T) {
+	infos := displayers.infos
+	t.Cleanup(func() {
+		displayers.infos = infos
+	})
+
+	// A format registered by an importing package only writes pages
+	displayers.infos = slices.Clone(infos)
+	RegisterDisplayer(DisplayerInfo{Name: "page-only", New: func() (Displayer, error) { return NewPlainFormat(0), nil }})
+
+	assert.False(t, isListOutput("page-only"))
+	assert.Error(t, writeList(&bytes.Buffer{}, countries, "page-only", false))
+}
+
+func TestValidateListFlags(t *testing.T) {
+	logLevel, logFormat = "error", "text"
+	t.Cleanup(func() {
+		output = "plain"
+		for _, f := range pageFlags {
+			rootCmd.PersistentFlags().Lookup(f).Changed = false
+		}
+	})
+
+	output = "plain"
+	assert.NoError(t, validateListFlags(tocCmd, nil))
+
+	output = "dot"
+	assert.ErrorContains(t, validateListFlags(tocCmd, nil), "'toc' command")
+	assert.NoError(t, validateListFlags(categoryTreeCmd, nil, "dot"))
+
+	// The flags of the pages are rejected, rather than ignored
+	output = "plain"
+	for _, f := range pageFlags {
+		rootCmd.PersistentFlags().Lookup(f).Changed = true
+		assert.ErrorContains(t, validateListFlags(linksCmd, nil), "'"+f+"'", f)
+		rootCmd.PersistentFlags().Lookup(f).Changed = false
+	}
+}
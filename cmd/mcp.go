@@ -0,0 +1,544 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+
+	internallogger "github.com/lescactus/wpdia-go/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// mcpProtocolVersion is the revision of the Model Context Protocol implemented by the server.
+	// Ref: https://spec.modelcontextprotocol.io/specification/2024-11-05/
+	mcpProtocolVersion = "2024-11-05"
+
+	// JSON-RPC 2.0 error codes
+	// Ref: https://www.jsonrpc.org/specification#error_object
+	jsonRPCParseError     = -32700
+	jsonRPCInvalidRequest = -32600
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+
+	// mcpSearchDefaultLimit and mcpSearchMaxLimit bound the number of results of the 'wikipedia_search' tool
+	mcpSearchDefaultLimit = 10
+	mcpSearchMaxLimit     = 50
+)
+
+var (
+	// langRegexp represents the accepted values of a Wikipedia language code, ie. 'en', 'fr' or 'zh-yue'
+	langRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+	// mcpCmd represents the 'mcp' command
+	mcpCmd = &cobra.Command{
+		Use:   "mcp",
+		Short: "Start a Model Context Protocol server over stdio",
+		Long: `Start a Model Context Protocol (MCP) server speaking JSON-RPC 2.0 over stdio.
+
+AI assistants and editors can then query Wikipedia through wpdia-go using the following tools:
+wikipedia_search, wikipedia_extract, wikipedia_random and wikipedia_sections.
+
+Messages are read from the standard input and written to the standard output, one per line.
+Logs are written to the standard error.`,
+
+		PreRunE: validateFlags,
+
+		Args: cobra.NoArgs,
+
+		Run: func(cmd *cobra.Command, args []string) {
+			// The standard output is reserved for the protocol
+			var err error
+			logger, err = internallogger.NewWithWriter(os.Stderr, logLevel, logFormat)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+
+			logger.Info("Starting MCP server...", slog.String("lang", lang))
+
+			s := newMCPServer(func(l string) (*WikiClient, error) {
+				return NewWikiClient(apiBaseURL(l), "")
+			})
+
+			if err := s.Serve(os.Stdin, os.Stdout); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+// jsonRPCRequest represents a JSON-RPC 2.0 request or notification.
+// Notifications don't have an ID.
+type jsonRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// jsonRPCResponse represents a JSON-RPC 2.0 response.
+type jsonRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *jsonRPCError   `json:"error,omitempty"`
+}
+
+// jsonRPCError represents the error of a JSON-RPC 2.0 response.
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool represents a tool exposed by the MCP server.
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+
+	// call runs the tool with the given arguments
+	call func(args json.RawMessage) (any, error) `json:"-"`
+}
+
+// mcpContent represents a content block of a tool result.
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// mcpToolResult represents the result of a 'tools/call' request.
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// mcpPage represents a page returned by the 'wikipedia_extract' and 'wikipedia_random' tools,
+// with the attribution required to reuse its text.
+type mcpPage struct {
+	*Page
+	Attribution *Attribution `json:"attribution"`
+}
+
+// mcpSearchResult represents a single result of the 'wikipedia_search' tool.
+type mcpSearchResult struct {
+	Title   string `json:"title"`
+	Pageid  uint64 `json:"pageid"`
+	Snippet string `json:"snippet"`
+}
+
+// mcpSectionsResult represents the result of the 'wikipedia_sections' tool.
+type mcpSectionsResult struct {
+	Title    string    `json:"title"`
+	Pageid   int       `json:"pageid"`
+	Sections []Section `json:"sections"`
+}
+
+// mcpServer is a Model Context Protocol server exposing the WikiClient as a set of tools.
+type mcpServer struct {
+	// newClient creates the WikiClient used for a given language
+	newClient func(lang string) (*WikiClient, error)
+
+	tools []mcpTool
+}
+
+// newMCPServer creates a new MCP server using newClient to create
+// the WikiClient of the language requested by a tool call.
+func newMCPServer(newClient func(lang string) (*WikiClient, error)) *mcpServer {
+	s := &mcpServer{newClient: newClient}
+
+	langSchema := map[string]any{
+		"type":        "string",
+		"description": "Language of the Wikipedia to query, ie. 'en' or 'fr'. Defaults to the language the server was started with.",
+	}
+
+	titleSchema := map[string]any{
+		"type":        "string",
+		"description": "Title or text to search for. The first result of the search is used.",
+	}
+
+	s.tools = []mcpTool{
+		{
+			Name:        "wikipedia_search",
+			Description: "Search Wikipedia and return the matching page titles, page ids and snippets.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "Text to search for.",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": "Maximum number of results to return.",
+						"minimum":     1,
+						"maximum":     mcpSearchMaxLimit,
+						"default":     mcpSearchDefaultLimit,
+					},
+					"lang": langSchema,
+				},
+				"required": []string{"query"},
+			},
+			call: s.search,
+		},
+		{
+			Name:        "wikipedia_extract",
+			Description: "Return the text extract, short description and Wikidata item of the Wikipedia page best matching a title.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"title": titleSchema,
+					"lang":  langSchema,
+				},
+				"required": []string{"title"},
+			},
+			call: s.extract,
+		},
+		{
+			Name:        "wikipedia_random",
+			Description: "Return the text extract of a random Wikipedia article.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"lang": langSchema,
+				},
+			},
+			call: s.random,
+		},
+		{
+			Name:        "wikipedia_sections",
+			Description: "List the sections of the Wikipedia page best matching a title.",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"title": titleSchema,
+					"lang":  langSchema,
+				},
+				"required": []string{"title"},
+			},
+			call: s.sections,
+		},
+	}
+
+	return s
+}
+
+// Serve will read the JSON-RPC messages from in, one per line, and write the responses to out
+// until in is closed.
+// It returns any error encountered while reading or writing.
+func (s *mcpServer) Serve(in io.Reader, out io.Writer) error {
+	r := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(line); resp != nil {
+				if err := enc.Encode(resp); err != nil {
+					return err
+				}
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle will process a single JSON-RPC message.
+// It returns the response to send back, or nil if the message is a notification.
+func (s *mcpServer) handle(msg []byte) *jsonRPCResponse {
+	var req jsonRPCRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return newJSONRPCError(json.RawMessage("null"), jsonRPCParseError, "parse error: "+err.Error())
+	}
+
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return newJSONRPCError(req.ID, jsonRPCInvalidRequest, "invalid request")
+	}
+
+	logger.Debug("MCP message received", slog.String("method", req.Method))
+
+	// Notifications, ie. 'notifications/initialized', don't expect any response
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	var result any
+	switch req.Method {
+	case "initialize":
+		result = map[string]any{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities": map[string]any{
+				"tools": map[string]any{},
+			},
+			"serverInfo": map[string]any{
+				"name":    "wpdia-go",
+				"version": version,
+			},
+		}
+	case "ping":
+		result = map[string]any{}
+	case "tools/list":
+		result = map[string]any{"tools": s.tools}
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return newJSONRPCError(req.ID, jsonRPCInvalidParams, "invalid params: "+err.Error())
+		}
+
+		tool := s.tool(params.Name)
+		if tool == nil {
+			return newJSONRPCError(req.ID, jsonRPCInvalidParams, fmt.Sprintf("unknown tool: %s", params.Name))
+		}
+
+		result = s.callTool(tool, params.Arguments)
+	default:
+		return newJSONRPCError(req.ID, jsonRPCMethodNotFound, fmt.Sprintf("method not found: %s", req.Method))
+	}
+
+	return &jsonRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// tool returns the tool with the given name, or nil if it doesn't exist.
+func (s *mcpServer) tool(name string) *mcpTool {
+	for i := range s.tools {
+		if s.tools[i].Name == name {
+			return &s.tools[i]
+		}
+	}
+	return nil
+}
+
+// callTool will run the given tool and wrap its output in a tool result.
+// Errors of the tool are reported in the result so that the model can see them.
+func (s *mcpServer) callTool(tool *mcpTool, args json.RawMessage) *mcpToolResult {
+	logger.Info("Calling tool...", slog.String("tool", tool.Name))
+
+	// Tools without required arguments may be called without any
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	out, err := tool.call(args)
+	if err != nil {
+		logger.Error(err.Error(), slog.String("tool", tool.Name))
+		return &mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return &mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+
+	return &mcpToolResult{Content: []mcpContent{{Type: "text", Text: string(b)}}}
+}
+
+// client returns the WikiClient to use for the given language.
+// When empty, the language given by the 'lang' flag is used.
+func (s *mcpServer) client(l string) (*WikiClient, error) {
+	if l == "" {
+		l = lang
+	}
+
+	if !langRegexp.MatchString(l) {
+		return nil, fmt.Errorf("invalid language: %q", l)
+	}
+
+	return s.newClient(l)
+}
+
+// search is the handler of the 'wikipedia_search' tool.
+func (s *mcpServer) search(args json.RawMessage) (any, error) {
+	var in struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+		Lang  string `json:"lang"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, err
+	}
+
+	if in.Query == "" {
+		return nil, errors.New("the 'query' argument is required")
+	}
+
+	if in.Limit <= 0 {
+		in.Limit = mcpSearchDefaultLimit
+	}
+	if in.Limit > mcpSearchMaxLimit {
+		in.Limit = mcpSearchMaxLimit
+	}
+
+	w, err := s.client(in.Lang)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := w.Search(in.Query, in.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]mcpSearchResult, 0, len(results))
+	for _, r := range results {
+		out = append(out, mcpSearchResult{
+			Title:   r.Title,
+			Pageid:  r.Pageid,
+			Snippet: stripHTMLTags(r.Snippet),
+		})
+	}
+
+	return out, nil
+}
+
+// extract is the handler of the 'wikipedia_extract' tool.
+func (s *mcpServer) extract(args json.RawMessage) (any, error) {
+	var in struct {
+		Title string `json:"title"`
+		Lang  string `json:"lang"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, err
+	}
+
+	if in.Title == "" {
+		return nil, errors.New("the 'title' argument is required")
+	}
+
+	w, err := s.client(in.Lang)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := w.GetPage(in.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMCPPage(limitExtract(p, in.Lang), in.Lang), nil
+}
+
+// random is the handler of the 'wikipedia_random' tool.
+func (s *mcpServer) random(args json.RawMessage) (any, error) {
+	var in struct {
+		Lang string `json:"lang"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, err
+	}
+
+	w, err := s.client(in.Lang)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := w.GetPageRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	return newMCPPage(limitExtract(p, in.Lang), in.Lang), nil
+}
+
+// newMCPPage returns the given page with its attribution, the page being
+// in the given language, or in the one of the 'lang' flag when empty.
+func newMCPPage(p *Page, l string) *mcpPage {
+	if l == "" {
+		l = lang
+	}
+
+	u := p.URL
+	if u == "" {
+		u = articleURL(l, p.Title)
+	}
+
+	return &mcpPage{Page: p, Attribution: newAttribution(l, p.Title, u, p.LastRevID)}
+}
+
+// limitExtract will limit the extract of the page to the number of sentences of the 'exsentences' flag,
+// split in the language of the page: the given one, or the one of the 'lang' flag when empty.
+func limitExtract(p *Page, l string) *Page {
+	if l == "" {
+		l = lang
+	}
+
+	if n := sentencesLimit(); n > 0 {
+		p.Extract = firstSentences(l, n, p.Extract)
+	}
+
+	return p
+}
+
+// sections is the handler of the 'wikipedia_sections' tool.
+func (s *mcpServer) sections(args json.RawMessage) (any, error) {
+	var in struct {
+		Title string `json:"title"`
+		Lang  string `json:"lang"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, err
+	}
+
+	if in.Title == "" {
+		return nil, errors.New("the 'title' argument is required")
+	}
+
+	w, err := s.client(in.Lang)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := w.FindPage(in.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := w.GetSections(p.Pageid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Always return a list, even when the article doesn't have any section
+	if sections == nil {
+		sections = []Section{}
+	}
+
+	return &mcpSectionsResult{
+		Title:    p.Title,
+		Pageid:   int(p.Pageid),
+		Sections: sections,
+	}, nil
+}
+
+// newJSONRPCError creates a JSON-RPC 2.0 error response.
+func newJSONRPCError(id json.RawMessage, code int, message string) *jsonRPCResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return &jsonRPCResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &jsonRPCError{Code: code, Message: message},
+	}
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestMCPServer creates a MCP server querying the stub Wikipedia API, whatever the language.
+func newTestMCPServer(t *testing.T) *mcpServer {
+	ts := newStubWikiAPI(t)
+
+	return newMCPServer(func(l string) (*WikiClient, error) {
+		return NewWikiClient(ts.URL, "")
+	})
+}
+
+func TestMCPServerHandle(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       string
+		wantNil   bool
+		wantError int
+	}{
+		{
+			name: "initialize",
+			msg:  `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		},
+		{
+			name:    "notifications/initialized",
+			msg:     `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+			wantNil: true,
+		},
+		{
+			name: "ping",
+			msg:  `{"jsonrpc":"2.0","id":"abc","method":"ping"}`,
+		},
+		{
+			name:      "Parse error",
+			msg:       `{"jsonrpc":`,
+			wantError: jsonRPCParseError,
+		},
+		{
+			name:      "Invalid request",
+			msg:       `{"jsonrpc":"1.0","id":1,"method":"ping"}`,
+			wantError: jsonRPCInvalidRequest,
+		},
+		{
+			name:      "Method not found",
+			msg:       `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+			wantError: jsonRPCMethodNotFound,
+		},
+		{
+			name:      "Unknown tool",
+			msg:       `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"unknown"}}`,
+			wantError: jsonRPCInvalidParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestMCPServer(t)
+			got := s.handle([]byte(tt.msg))
+
+			if tt.wantNil {
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NotNil(t, got)
+			assert.Equal(t, "2.0", got.JSONRPC)
+			if tt.wantError != 0 {
+				assert.NotNil(t, got.Error)
+				assert.Equal(t, tt.wantError, got.Error.Code)
+			} else {
+				assert.Nil(t, got.Error)
+				assert.NotNil(t, got.Result)
+			}
+		})
+	}
+}
+
+func TestMCPServerToolsList(t *testing.T) {
+	s := newTestMCPServer(t)
+	got := s.handle([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+
+	b, err := json.Marshal(got.Result)
+	assert.NoError(t, err)
+
+	var result struct {
+		Tools []struct {
+			Name        string         `json:"name"`
+			InputSchema map[string]any `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	assert.NoError(t, json.Unmarshal(b, &result))
+
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+		assert.Equal(t, "object", tool.InputSchema["type"])
+	}
+	assert.Equal(t, []string{"wikipedia_search", "wikipedia_extract", "wikipedia_random", "wikipedia_sections"}, names)
+}
+
+func TestMCPServerToolsCall(t *testing.T) {
+	tests := []struct {
+		name         string
+		arguments    string
+		tool         string
+		wantIsError  bool
+		wantContains []string
+	}{
+		{
+			name:         "wikipedia_search",
+			tool:         "wikipedia_search",
+			arguments:    `{"query":"golang","limit":2}`,
+			wantContains: []string{`"title": "Golang"`, `"snippet": "Go is a language"`, `"title": "Go (game)"`},
+		},
+		{
+			name:         "wikipedia_search without query",
+			tool:         "wikipedia_search",
+			arguments:    `{}`,
+			wantIsError:  true,
+			wantContains: []string{"'query' argument is required"},
+		},
+		{
+			name:         "wikipedia_extract",
+			tool:         "wikipedia_extract",
+			arguments:    `{"title":"golang"}`,
+			wantContains: []string{`"title": "Golang"`, `"wikibase_item": "WikiBaseItem"`, `"history": "https://en.wikipedia.org/w/index.php?action=history\u0026title=Golang"`, `"license": "CC BY-SA 4.0"`},
+		},
+		{
+			name:         "wikipedia_extract not found",
+			tool:         "wikipedia_extract",
+			arguments:    `{"title":"nothing"}`,
+			wantIsError:  true,
+			wantContains: []string{ErrPageNotFound.Error()},
+		},
+		{
+			name:         "wikipedia_extract invalid language",
+			tool:         "wikipedia_extract",
+			arguments:    `{"title":"golang","lang":"evil.com/"}`,
+			wantIsError:  true,
+			wantContains: []string{"invalid language"},
+		},
+		{
+			name:         "wikipedia_random without arguments",
+			tool:         "wikipedia_random",
+			arguments:    ``,
+			wantContains: []string{`"title": "Random"`},
+		},
+		{
+			name:         "wikipedia_sections",
+			tool:         "wikipedia_sections",
+			arguments:    `{"title":"golang"}`,
+			wantContains: []string{`"title": "History"`, `"level": 3`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestMCPServer(t)
+
+			params := map[string]any{"name": tt.tool}
+			if tt.arguments != "" {
+				params["arguments"] = json.RawMessage(tt.arguments)
+			}
+			b, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": params})
+			assert.NoError(t, err)
+
+			got := s.handle(b)
+			assert.Nil(t, got.Error)
+
+			result, ok := got.Result.(*mcpToolResult)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantIsError, result.IsError)
+			assert.Len(t, result.Content, 1)
+			for _, c := range tt.wantContains {
+				assert.Contains(t, result.Content[0].Text, c)
+			}
+		})
+	}
+}
+
+func TestMCPServerServe(t *testing.T) {
+	s := newTestMCPServer(t)
+
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}
+{"jsonrpc":"2.0","method":"notifications/initialized"}
+
+{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	out := &bytes.Buffer{}
+
+	assert.NoError(t, s.Serve(in, out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"protocolVersion":"2024-11-05"`)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":2,"result":{}}`, lines[1])
+}
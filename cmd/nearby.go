@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// minNearbyRadius and maxNearbyRadius are the bounds of the 'radius' flag of the 'nearby' command, in meters,
+	// as allowed by the API
+	minNearbyRadius = 10
+	maxNearbyRadius = 10000
+)
+
+// ErrNoCoordinates is returned when searching around a page which has no coordinates
+var ErrNoCoordinates = errors.New("the page has no coordinates")
+
+var (
+	nearbyLat    float64 // latitude of the point to search around
+	nearbyLon    float64 // longitude of the point to search around
+	nearbyTitle  string  // title of the article to search around, instead of a point
+	nearbyRadius string  // distance around the point to search within, ie. "5km" or "500m"
+	nearbyLimit  int     // maximum number of articles listed
+
+	// nearbyCmd represents the 'nearby' command
+	nearbyCmd = &cobra.Command{
+		Use:   "nearby",
+		Short: "List the articles around a point, nearest first",
+		Long: `List the Wikipedia articles located within the given radius of a point, nearest first.
+The point is either given by its latitude and longitude, or is the location of the article best matching
+the '--near' title, which is then left out of the list, ie.
+  wpdia-go nearby --lat 48.69 --lon 6.18 --radius 5km
+  wpdia-go nearby --near "Place Stanislas" --radius 500m
+
+The 'geojson' output is a GeoJSON FeatureCollection of the articles, which mapping tools can load directly:
+  wpdia-go nearby --lat 48.69 --lon 6.18 --output geojson > nancy.geojson`,
+
+		PreRunE: validateNearbyFlags,
+
+		Args: cobra.NoArgs,
+
+		Run: func(cmd *cobra.Command, args []string) {
+			w, err := NewWikiClient(APIBaseURL, "")
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+
+			// The radius has already been validated
+			radius, _ := parseRadius(nearbyRadius)
+
+			lat, lon := nearbyLat, nearbyLon
+			title := fmt.Sprintf("Articles near %g, %g", lat, lon)
+			var center uint64
+			if nearbyTitle != "" {
+				p := searchPage(w, nearbyTitle)
+				center, title = p.Pageid, "Articles near "+p.Title
+
+				c, err := w.GetCoordinates(center)
+				if err != nil {
+					logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.Uint64("pageid", center))
+					os.Exit(1)
+				}
+				if c == nil {
+					logger.Error(ErrNoCoordinates.Error(), slog.String("title", nearbyTitle), slog.Uint64("pageid", center))
+					os.Exit(1)
+				}
+				lat, lon = c.Lat, c.Lon
+			}
+
+			logger.Info("Searching articles...", slog.Float64("lat", lat), slog.Float64("lon", lon), slog.Int("radius", radius))
+
+			pages, err := searchNearby(w, lat, lon, radius, nearbyLimit, center)
+			if err != nil {
+				logger.Error(err.Error(), slog.String("url", APIBaseURL))
+				os.Exit(1)
+			}
+
+			if err := writeNearby(os.Stdout, title, pages, output, header); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	nearbyCmd.Flags().Float64Var(&nearbyLat, "lat", 0, "Latitude of the point to search around, in decimal degrees. Requires 'lon'.")
+	nearbyCmd.Flags().Float64Var(&nearbyLon, "lon", 0, "Longitude of the point to search around, in decimal degrees. Requires 'lat'.")
+	nearbyCmd.Flags().StringVar(&nearbyTitle, "near", "", "Search around the article best matching the given title instead of a point. Mutually exclusive with 'lat' and 'lon'.")
+	nearbyCmd.Flags().StringVar(&nearbyRadius, "radius", "1km", fmt.Sprintf("Distance around the point to search within, in meters or kilometers, ie. '500m' or '5km'. Must be between %dm and %dkm.", minNearbyRadius, maxNearbyRadius/1000))
+	nearbyCmd.Flags().IntVar(&nearbyLimit, "limit", 10, fmt.Sprintf("Maximum number of articles to list. Must be between 1 and %d.", maxQueryLimit))
+
+	rootCmd.AddCommand(nearbyCmd)
+}
+
+// validateNearbyFlags will determine whether the flags of the 'nearby' command are valid.
+func validateNearbyFlags(cmd *cobra.Command, args []string) error {
+	point := cmd.Flags().Changed("lat") || cmd.Flags().Changed("lon")
+	switch {
+	case nearbyTitle != "" && point:
+		return fmt.Errorf("error: flag 'near' and flags 'lat' and 'lon' are mutually exclusive")
+	case nearbyTitle == "" && !(cmd.Flags().Changed("lat") && cmd.Flags().Changed("lon")):
+		return fmt.Errorf("error: either flags 'lat' and 'lon' or flag 'near' are required")
+	}
+	if nearbyLat < -90 || nearbyLat > 90 {
+		return fmt.Errorf("error: invalid value for flag 'lat': %g. Must be between -90 and 90", nearbyLat)
+	}
+	if nearbyLon < -180 || nearbyLon > 180 {
+		return fmt.Errorf("error: invalid value for flag 'lon': %g. Must be between -180 and 180", nearbyLon)
+	}
+
+	if _, err := parseRadius(nearbyRadius); err != nil {
+		return fmt.Errorf("error: invalid value for flag 'radius': %w", err)
+	}
+	if nearbyLimit < 1 || nearbyLimit > maxQueryLimit {
+		return fmt.Errorf("error: invalid value for flag 'limit': %d. Must be between 1 and %d", nearbyLimit, maxQueryLimit)
+	}
+
+	return validateListFlags(cmd, args, "geojson")
+}
+
+// parseRadius returns the given distance in meters, ie. 5000 for "5km", 500 for "500m" or "500".
+// It returns an error when the distance isn't between minNearbyRadius and maxNearbyRadius.
+func parseRadius(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	unit := 1.0
+	switch {
+	case strings.HasSuffix(s, "km"):
+		s, unit = strings.TrimSuffix(s, "km"), 1000
+	case strings.HasSuffix(s, "m"):
+		s = strings.TrimSuffix(s, "m")
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q isn't a distance, ie. '500m' or '5km'", s)
+	}
+
+	meters := int(n * unit)
+	if meters < minNearbyRadius || meters > maxNearbyRadius {
+		return 0, fmt.Errorf("%dm must be between %dm and %dm", meters, minNearbyRadius, maxNearbyRadius)
+	}
+
+	return meters, nil
+}
+
+// searchNearby will list the articles within radius meters of the given latitude and longitude, nearest first,
+// leaving out the article with the given page id when not 0, ie. the one the search is around.
+// It returns at most limit articles with their URL, or any error encountered.
+func searchNearby(w *WikiClient, lat, lon float64, radius, limit int, exclude uint64) ([]NearbyPage, error) {
+	// The excluded article is likely the nearest one
+	n := limit
+	if exclude != 0 {
+		n++
+	}
+
+	pages, err := w.GeoSearch(lat, lon, radius, n)
+	if err != nil {
+		return nil, err
+	}
+
+	pages = slices.DeleteFunc(pages, func(p NearbyPage) bool { return exclude != 0 && p.Pageid == exclude })
+	if len(pages) > limit {
+		pages = pages[:limit]
+	}
+	for i := range pages {
+		pages[i].URL = articleURL(lang, pages[i].Title)
+	}
+
+	return pages, nil
+}
+
+// geoJSONFeatureCollection represents a GeoJSON FeatureCollection.
+// The specification is found here: https://datatracker.ietf.org/doc/html/rfc7946
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// geoJSONFeature represents a GeoJSON Feature of an article, located by a Point
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJSONPoint      `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+// geoJSONPoint represents a GeoJSON Point geometry
+type geoJSONPoint struct {
+	Type string `json:"type"`
+
+	// Coordinates are the longitude and the latitude of the point, in that order
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// geoJSONProperties represents the properties of the Feature of an article
+type geoJSONProperties struct {
+	Pageid uint64  `json:"pageid"`
+	Title  string  `json:"title"`
+	Dist   float64 `json:"dist"`
+	URL    string  `json:"url"`
+}
+
+// writeNearby will write the given articles to w in the given output format, the list being described by title.
+// The plain output is a table of the articles with their distance, the csv and tsv outputs start
+// with a row of the column names when header is true, and the geojson output is a FeatureCollection
+// with a Point for each article.
+func writeNearby(w io.Writer, title string, pages []NearbyPage, format string, header bool) error {
+	if format == "geojson" {
+		fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+		for _, p := range pages {
+			fc.Features = append(fc.Features, geoJSONFeature{
+				Type:       "Feature",
+				Geometry:   geoJSONPoint{Type: "Point", Coordinates: [2]float64{p.Lon, p.Lat}},
+				Properties: geoJSONProperties{Pageid: p.Pageid, Title: p.Title, Dist: p.Dist, URL: p.URL},
+			})
+		}
+
+		b, err := json.MarshalIndent(fc, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	t := Table{Columns: []string{"pageid", "title", "lat", "lon", "dist", "url"}}
+	for _, p := range pages {
+		t.Rows = append(t.Rows, []string{
+			strconv.FormatUint(p.Pageid, 10),
+			p.Title,
+			strconv.FormatFloat(p.Lat, 'f', -1, 64),
+			strconv.FormatFloat(p.Lon, 'f', -1, 64),
+			strconv.FormatFloat(p.Dist, 'f', -1, 64),
+			p.URL,
+		})
+	}
+
+	return writeList(w, List{
+		Title: title,
+		Value: pages,
+		Table: t,
+		Plain: func(w io.Writer) error {
+			t := Table{Columns: []string{"Distance", "Title", "Coordinates"}}
+			for _, p := range pages {
+				t.Rows = append(t.Rows, []string{formatDistance(p.Dist), p.Title, coordinatesText(&WikiCoordinates{Lat: p.Lat, Lon: p.Lon})})
+			}
+			return writeAlignedTable(w, t)
+		},
+		Markdown: func(w io.Writer) error {
+			for _, p := range pages {
+				if _, err := fmt.Fprintf(w, "- %s: %s\n", markdownLink(p.Title, p.URL), formatDistance(p.Dist)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, format, header)
+}
+
+// formatDistance returns the given distance in meters as text, ie. "350 m" or "1.2 km".
+func formatDistance(meters float64) string {
+	if meters < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nearbyPages are the articles around Place Stanislas, in Nancy
+var nearbyPages = []NearbyPage{
+	{Pageid: 1, Title: "Place Stanislas", Lat: 48.693611, Lon: 6.183333, Dist: 0, URL: "https://en.wikipedia.org/wiki/Place_Stanislas"},
+	{Pageid: 2, Title: "Nancy Cathedral", Lat: 48.691, Lon: 6.1867, Dist: 379.2, URL: "https://en.wikipedia.org/wiki/Nancy_Cathedral"},
+	{Pageid: 3, Title: "Parc de la Pépinière", Lat: 48.6972, Lon: 6.1847, Dist: 1234.5, URL: "https://en.wikipedia.org/wiki/Parc_de_la_P%C3%A9pini%C3%A8re"},
+}
+
+func TestSearchNearby(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "geosearch", q.Get("list"))
+
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"geosearch":[`+
+			`{"pageid":1,"ns":0,"title":"Place Stanislas","lat":48.693611,"lon":6.183333,"dist":0,"primary":true},`+
+			`{"pageid":2,"ns":0,"title":"Nancy Cathedral","lat":48.691,"lon":6.1867,"dist":379.2,"primary":true},`+
+			`{"pageid":3,"ns":0,"title":"Parc de la Pépinière","lat":48.6972,"lon":6.1847,"dist":1234.5,"primary":true}]}}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+	lang = "en"
+
+	tests := []struct {
+		name    string
+		limit   int
+		exclude uint64
+		want    []NearbyPage
+	}{
+		{
+			name:  "Around a point",
+			limit: 2,
+			want:  nearbyPages[:2],
+		},
+		{
+			name:    "Around an article",
+			limit:   2,
+			exclude: 1,
+			want:    nearbyPages[1:],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := searchNearby(w, 48.693611, 6.183333, 1000, tt.limit, tt.exclude)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteNearby(t *testing.T) {
+	tests := []struct {
+		name   string
+		pages  []NearbyPage
+		format string
+		header bool
+		want   string
+	}{
+		{
+			name:   "Plain",
+			pages:  nearbyPages,
+			format: "plain",
+			want: `Distance  Title                 Coordinates
+0 m       Place Stanislas       48.693611, 6.183333
+379 m     Nancy Cathedral       48.691, 6.1867
+1.2 km    Parc de la Pépinière  48.6972, 6.1847
+`,
+		},
+		{
+			name:   "CSV",
+			pages:  nearbyPages[1:2],
+			format: "csv",
+			header: true,
+			want:   "pageid,title,lat,lon,dist,url\n2,Nancy Cathedral,48.691,6.1867,379.2,https://en.wikipedia.org/wiki/Nancy_Cathedral\n",
+		},
+		{
+			name:   "TSV without header",
+			pages:  nearbyPages[1:2],
+			format: "tsv",
+			want:   "2\tNancy Cathedral\t48.691\t6.1867\t379.2\thttps://en.wikipedia.org/wiki/Nancy_Cathedral\n",
+		},
+		{
+			name:   "JSON",
+			pages:  nearbyPages[1:2],
+			format: "json",
+			want:   "[\n    {\n        \"pageid\": 2,\n        \"title\": \"Nancy Cathedral\",\n        \"lat\": 48.691,\n        \"lon\": 6.1867,\n        \"dist\": 379.2,\n        \"url\": \"https://en.wikipedia.org/wiki/Nancy_Cathedral\"\n    }\n]\n",
+		},
+		{
+			name:   "YAML",
+			pages:  nearbyPages[1:2],
+			format: "yaml",
+			want:   "- pageid: 2\n  title: Nancy Cathedral\n  lat: 48.691\n  lon: 6.1867\n  dist: 379.2\n  url: https://en.wikipedia.org/wiki/Nancy_Cathedral\n",
+		},
+		{
+			name:   "Empty JSON",
+			format: "json",
+			want:   "[]\n",
+		},
+		{
+			name:   "Empty GeoJSON",
+			format: "geojson",
+			want:   "{\n    \"type\": \"FeatureCollection\",\n    \"features\": []\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writeNearby(w, "Articles near Place Stanislas", tt.pages, tt.format, tt.header)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+
+	t.Run("GeoJSON", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		assert.NoError(t, writeNearby(w, "Articles near Place Stanislas", nearbyPages, "geojson", false))
+		assertGolden(t, "nearby_geojson.golden", w.Bytes())
+	})
+}
+
+func TestParseRadius(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    int
+		wantErr bool
+	}{
+		{s: "5km", want: 5000},
+		{s: "1.5 km", want: 1500},
+		{s: "500m", want: 500},
+		{s: "500", want: 500},
+		{s: "10KM", want: 10000},
+		{s: "5m", wantErr: true},
+		{s: "11km", wantErr: true},
+		{s: "five km", wantErr: true},
+		{s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseRadius(tt.s)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatDistance(t *testing.T) {
+	assert.Equal(t, "0 m", formatDistance(0))
+	assert.Equal(t, "999 m", formatDistance(999.4))
+	assert.Equal(t, "1.0 km", formatDistance(1000))
+	assert.Equal(t, "9.9 km", formatDistance(9876.5))
+}
+
+func TestValidateNearbyFlags(t *testing.T) {
+	logLevel, logFormat = "error", "text"
+	setPoint := func(set bool) {
+		for _, f := range []string{"lat", "lon"} {
+			nearbyCmd.Flags().Lookup(f).Changed = set
+		}
+	}
+	t.Cleanup(func() {
+		output, templateText, nearbyLat, nearbyLon, nearbyTitle, nearbyRadius, nearbyLimit = "plain", "", 0, 0, "", "1km", 10
+		setPoint(false)
+	})
+
+	nearbyLat, nearbyLon, nearbyRadius, nearbyLimit = 48.69, 6.18, "1km", 10
+	setPoint(true)
+	templateText = "{{.}}"
+	for _, o := range slices.Concat(listOutputs, []string{"geojson"}) {
+		output = o
+		assert.NoError(t, validateNearbyFlags(nearbyCmd, nil), o)
+	}
+
+	output = "dot"
+	assert.Error(t, validateNearbyFlags(nearbyCmd, nil))
+
+	output, nearbyLat = "plain", 91
+	assert.Error(t, validateNearbyFlags(nearbyCmd, nil))
+
+	nearbyLat, nearbyLon = 48.69, -181
+	assert.Error(t, validateNearbyFlags(nearbyCmd, nil))
+
+	nearbyLon, nearbyRadius = 6.18, "20km"
+	assert.Error(t, validateNearbyFlags(nearbyCmd, nil))
+
+	nearbyRadius, nearbyLimit = "1km", maxQueryLimit+1
+	assert.Error(t, validateNearbyFlags(nearbyCmd, nil))
+
+	// Either a point or a title
+	nearbyLimit, nearbyTitle = 10, "Place Stanislas"
+	assert.Error(t, validateNearbyFlags(nearbyCmd, nil))
+
+	setPoint(false)
+	assert.NoError(t, validateNearbyFlags(nearbyCmd, nil))
+
+	nearbyTitle = ""
+	assert.Error(t, validateNearbyFlags(nearbyCmd, nil))
+
+	nearbyCmd.Flags().Lookup("lat").Changed = true
+	assert.Error(t, validateNearbyFlags(nearbyCmd, nil))
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var (
+	// validOverwritePolicies represents the authorized values for the 'overwrite' flag:
+	// 'never' fails when a file already exists, 'skip' leaves it untouched and 'always' replaces it
+	validOverwritePolicies = []string{"never", "skip", "always"}
+
+	// errFileExists is returned when writing a file which already exists with the 'never' overwrite policy
+	errFileExists = errors.New("file already exists")
+
+	// unsafeFilenameRegexp matches the characters which can't be part of a file name
+	// on the common file systems, including the path separators
+	unsafeFilenameRegexp = regexp.MustCompile(`[/\\<>:"|?*\x00-\x1f\x7f]+`)
+
+	// linkFile creates a hard link, replaced in the tests to mimic the file systems without hard links
+	linkFile = os.Link
+)
+
+// maxFilenameLength is the maximum length in bytes of a templated file name,
+// most file systems not accepting more than 255 bytes
+const maxFilenameLength = 200
+
+// outputFile represents an additional destination of the rendered page,
+// as given to the 'output-file' flag: "<format>:<path>".
+type outputFile struct {
+	// info is the output format the file is rendered with
+	info DisplayerInfo
+
+	// segments are the segments of the path of the file.
+	// Each one is rendered against the view of the page and sanitised separately,
+	// so the page values can't add or escape directories.
+	segments []pathSegment
+}
+
+// pathSegment represents a segment of the path of an output file.
+type pathSegment struct {
+	text string
+	// tmpl is the template of the segment, nil when it doesn't contain any template action
+	tmpl *template.Template
+}
+
+// parseOutputFile parses the value of the 'output-file' flag: "<format>:<path>",
+// the path being a Go template rendered against the view of the page, ie. "md:notes/{{.Title}}.md".
+// It returns an error if the format is unknown or the path template is invalid.
+func parseOutputFile(s string) (*outputFile, error) {
+	format, path, ok := strings.Cut(s, ":")
+	if !ok || format == "" || path == "" {
+		return nil, fmt.Errorf("invalid output file %q: expected '<format>:<path>'", s)
+	}
+
+	info, ok := displayers.lookup(format)
+	if !ok {
+		return nil, fmt.Errorf("invalid output file %q: unknown format %q", s, format)
+	}
+
+	o := &outputFile{info: info}
+	for _, text := range splitPath(path) {
+		segment := pathSegment{text: text}
+
+		if strings.Contains(text, "{{") {
+			tmpl, err := newTemplate(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output file %q: %w", s, err)
+			}
+			segment.tmpl = tmpl
+		}
+
+		o.segments = append(o.segments, segment)
+	}
+
+	return o, nil
+}
+
+// splitPath splits the given path into its segments, on the path separators outside
+// the template actions, so that an action can contain a separator, ie. '{{printf "%s/%s" .Lang .Title}}'.
+func splitPath(path string) []string {
+	var segments []string
+
+	depth, start := 0, 0
+	for i := 0; i < len(path); i++ {
+		switch {
+		case strings.HasPrefix(path[i:], "{{"):
+			depth++
+			i++
+		case strings.HasPrefix(path[i:], "}}") && depth > 0:
+			depth--
+			i++
+		case depth == 0 && (path[i] == '/' || path[i] == filepath.Separator):
+			segments = append(segments, path[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(segments, path[start:])
+}
+
+// filename returns the path of the file for the given view.
+// The path segments containing template actions are sanitised once rendered.
+func (o *outputFile) filename(v *PageView) (string, error) {
+	segments := make([]string, 0, len(o.segments))
+
+	for _, segment := range o.segments {
+		if segment.tmpl == nil {
+			segments = append(segments, segment.text)
+			continue
+		}
+
+		var b bytes.Buffer
+		if err := segment.tmpl.Execute(&b, v); err != nil {
+			return "", err
+		}
+		segments = append(segments, sanitizeFilename(b.String()))
+	}
+
+	// The rendered segments don't contain any separator
+	return filepath.Clean(filepath.FromSlash(strings.Join(segments, "/"))), nil
+}
+
+// sanitizeFilename will turn the given string into a valid file name:
+// the path separators, reserved and control characters are replaced with underscores,
+// the leading and trailing dots and spaces are removed and the name is shortened if too long.
+// It returns "_" when nothing is left.
+func sanitizeFilename(s string) string {
+	s = unsafeFilenameRegexp.ReplaceAllString(s, "_")
+	s = strings.Trim(s, ". ")
+
+	if len(s) > maxFilenameLength {
+		s = strings.ToValidUTF8(s[:maxFilenameLength], "")
+	}
+
+	if s == "" {
+		return "_"
+	}
+
+	return s
+}
+
+// writeFileAtomic will write data to the file at the given path, creating its parent directories.
+// The data is written to a temporary file in the same directory which then replaces the file,
+// so that the file is either fully written or left untouched.
+// It returns errFileExists if the file exists and the policy is 'never', and does nothing with 'skip'.
+func writeFileAtomic(path string, data []byte, policy string) (bool, error) {
+	if policy != "always" {
+		if _, err := os.Lstat(path); err == nil {
+			if policy == "skip" {
+				return false, nil
+			}
+			return false, fmt.Errorf("%s: %w", path, errFileExists)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, err
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return false, err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return false, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return false, err
+	}
+	if err := f.Close(); err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		return false, err
+	}
+
+	if policy == "always" {
+		return true, os.Rename(tmp, path)
+	}
+
+	// Unlike renaming, linking fails if the file has been created in the meantime
+	err = linkFile(tmp, path)
+	// Some file systems don't support hard links, ie. FAT or some network mounts:
+	// the file is then created exclusively and written in place
+	if errors.Is(err, os.ErrPermission) || errors.Is(err, errors.ErrUnsupported) {
+		err = writeFileExclusive(path, data)
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			if policy == "skip" {
+				return false, nil
+			}
+			return false, fmt.Errorf("%s: %w", path, errFileExists)
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// writeFileExclusive will create the file at the given path and write data to it.
+// It fails with os.ErrExist if the file already exists, and removes the file if it can't be fully written.
+func writeFileExclusive(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+
+	return err
+}
+
+// write will render the given view with the output format of the file
+// and write it according to the overwrite policy.
+// It returns the path of the file and whether it has been written.
+func (o *outputFile) write(v *PageView, policy string) (string, bool, error) {
+	path, err := o.filename(v)
+	if err != nil {
+		return "", false, err
+	}
+
+	d, err := o.info.New()
+	if err != nil {
+		return path, false, err
+	}
+
+	var b bytes.Buffer
+	if err := d.Write(&b, v); err != nil {
+		return path, false, err
+	}
+
+	written, err := writeFileAtomic(path, b.Bytes(), policy)
+	return path, written, err
+}
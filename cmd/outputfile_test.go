@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutputFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "Static path",
+			s:        "json:out.json",
+			wantName: "json",
+			wantErr:  false,
+		},
+		{
+			name:     "Alias and templated path",
+			s:        "md:notes/{{.Title}}.md",
+			wantName: "markdown",
+			wantErr:  false,
+		},
+		{
+			name:    "Without path",
+			s:       "json:",
+			wantErr: true,
+		},
+		{
+			name:    "Without format",
+			s:       "out.json",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown format",
+			s:       "invalid:out.json",
+			wantErr: true,
+		},
+		{
+			name:     "Separator in a template action",
+			s:        `json:{{printf "%s/%s" .Lang .Title}}.json`,
+			wantName: "json",
+			wantErr:  false,
+		},
+		{
+			name:    "Invalid template",
+			s:       "json:{{.Title}.json",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOutputFile(tt.s)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantName, got.info.Name)
+			}
+		})
+	}
+}
+
+func TestOutputFileFilename(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		title   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Static path",
+			s:     "json:out/page.json",
+			title: "Go",
+			want:  filepath.Join("out", "page.json"),
+		},
+		{
+			name:  "Templated path",
+			s:     "md:notes/{{.Title}}.md",
+			title: "Go (programming language)",
+			want:  filepath.Join("notes", "Go (programming language).md"),
+		},
+		{
+			name:  "Title with separators",
+			s:     "md:notes/{{.Title}}.md",
+			title: "AC/DC",
+			want:  filepath.Join("notes", "AC_DC.md"),
+		},
+		{
+			name:  "Title escaping the directory",
+			s:     "md:notes/{{.Title}}",
+			title: "../../etc/passwd",
+			want:  filepath.Join("notes", "_.._etc_passwd"),
+		},
+		{
+			name:  "Absolute path",
+			s:     "json:/tmp/{{.Pageid}}.json",
+			title: "Go",
+			want:  filepath.FromSlash("/tmp/0.json"),
+		},
+		{
+			name:  "Separator in a template action",
+			s:     `json:pages/{{printf "%s/%s" .Lang .Title}}.json`,
+			title: "Go",
+			want:  filepath.Join("pages", "en_Go.json"),
+		},
+		{
+			name:    "Unknown field",
+			s:       "json:{{.Unknown}}.json",
+			title:   "Go",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := parseOutputFile(tt.s)
+			assert.NoError(t, err)
+
+			got, err := o.filename(NewPageView(&Page{Title: tt.title}, ViewOptions{Lang: "en"}))
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "Valid name", s: "Go (programming language)", want: "Go (programming language)"},
+		{name: "Separators", s: `AC/DC\Live`, want: "AC_DC_Live"},
+		{name: "Reserved characters", s: `What? "Yes": <no>|*`, want: "What_ _Yes_ _no_"},
+		{name: "Control characters", s: "Go\nlang\t", want: "Go_lang_"},
+		{name: "Dots", s: "..", want: "_"},
+		{name: "Leading dot", s: ".hidden", want: "hidden"},
+		{name: "Empty", s: "", want: "_"},
+		{name: "Unicode", s: "Café", want: "Café"},
+		{name: "Too long", s: strings.Repeat("é", 150), want: strings.Repeat("é", 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sanitizeFilename(tt.s))
+		})
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    bool
+		policy      string
+		wantWritten bool
+		wantContent string
+		wantErr     bool
+	}{
+		{
+			name:        "New file",
+			existing:    false,
+			policy:      "never",
+			wantWritten: true,
+			wantContent: "new",
+		},
+		{
+			name:        "Existing file, never overwrite",
+			existing:    true,
+			policy:      "never",
+			wantWritten: false,
+			wantContent: "old",
+			wantErr:     true,
+		},
+		{
+			name:        "Existing file, skip",
+			existing:    true,
+			policy:      "skip",
+			wantWritten: false,
+			wantContent: "old",
+		},
+		{
+			name:        "Existing file, always overwrite",
+			existing:    true,
+			policy:      "always",
+			wantWritten: true,
+			wantContent: "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "sub", "page.txt")
+
+			if tt.existing {
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				assert.NoError(t, os.WriteFile(path, []byte("old"), 0o644))
+			}
+
+			written, err := writeFileAtomic(path, []byte("new"), tt.policy)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errFileExists)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantWritten, written)
+
+			b, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(b))
+
+			// The temporary file must have been removed
+			entries, err := os.ReadDir(filepath.Dir(path))
+			assert.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestWriteFileAtomicWithoutHardLinks(t *testing.T) {
+	t.Cleanup(func() { linkFile = os.Link })
+	linkFile = func(oldname, newname string) error {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: syscall.EPERM}
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.txt")
+
+	written, err := writeFileAtomic(path, []byte("new"), "never")
+	assert.NoError(t, err)
+	assert.True(t, written)
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(b))
+
+	// The file isn't replaced once it exists
+	written, err = writeFileAtomic(path, []byte("newer"), "never")
+	assert.ErrorIs(t, err, errFileExists)
+	assert.False(t, written)
+
+	written, err = writeFileAtomic(path, []byte("newer"), "skip")
+	assert.NoError(t, err)
+	assert.False(t, written)
+
+	b, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(b))
+
+	// The temporary files must have been removed
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestSplitPath(t *testing.T) {
+	assert.Equal(t, []string{"notes", "{{.Title}}.md"}, splitPath("notes/{{.Title}}.md"))
+	assert.Equal(t, []string{"", "tmp", `{{printf "%s/%s" .Lang .Title}}.json`}, splitPath(`/tmp/{{printf "%s/%s" .Lang .Title}}.json`))
+}
+
+func TestOutputFileWrite(t *testing.T) {
+	dir := t.TempDir()
+	v := NewPageView(&page, ViewOptions{Lang: "en"})
+
+	// The same view is rendered by several formats
+	for _, s := range []string{"json:" + dir + "/{{.Title}}.json", "md:" + dir + "/notes/{{.Title}}.md"} {
+		o, err := parseOutputFile(s)
+		assert.NoError(t, err)
+
+		_, written, err := o.write(v, "never")
+		assert.NoError(t, err)
+		assert.True(t, written)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "Golang.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n    \"title\": \"Golang\",\n    \"extract\": \""+page.Extract+"\"\n}\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(dir, "notes", "Golang.md"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), "# Golang\n"))
+}
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// articleNamespace is the namespace of the articles. ref: https://www.mediawiki.org/wiki/Manual:Namespace
+	articleNamespace = 0
+
+	// maxPathDepth is the maximum value of the 'max-depth' flag of the 'path' command
+	maxPathDepth = 10
+)
+
+// ErrRequestBudget is returned when a request is sent once the request budget is exhausted
+var ErrRequestBudget = errors.New("request budget exhausted")
+
+// ErrPathNotFound is returned when the articles aren't linked within the maximum number of links
+var ErrPathNotFound = errors.New("no path found between the articles")
+
+var (
+	pathDepth   int // maximum number of links between the articles
+	maxRequests int // maximum number of requests sent to find the path
+
+	// pathCmd represents the 'path' command
+	pathCmd = &cobra.Command{
+		Use:   "path <from> <to>",
+		Short: "Find the shortest path of links between two articles",
+		Long: `Find the shortest path of links from the Wikipedia article best matching the first title
+to the one best matching the second title, ie. the articles to follow to go from one to the other:
+  wpdia-go path "Go (programming language)" "Ken Thompson"
+
+The links are followed in both directions at once, from the first article and back from the second one,
+with at most '--concurrency' requests at the same time. The search gives up once the path would be longer
+than '--max-depth' links, or once '--max-requests' requests have been sent.`,
+
+		PreRunE: validatePathFlags,
+
+		Args: cobra.ExactArgs(2),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			w, err := NewWikiClient(APIBaseURL, "")
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			budget := newBudgetTransport(w.Client.Transport, maxRequests)
+			w.Client.Transport = budget
+
+			var titles []string
+			for _, title := range args {
+				titles = append(titles, searchPage(w, title).Title)
+			}
+
+			logger.Info("Searching path...", slog.String("from", titles[0]), slog.String("to", titles[1]))
+
+			p := newPathFinder(w, concurrency)
+			path, err := p.find(titles[0], titles[1], pathDepth)
+			if err != nil {
+				logger.Error(err.Error(), slog.String("from", titles[0]), slog.String("to", titles[1]), slog.Int64("requests", budget.sent()))
+				os.Exit(1)
+			}
+
+			logger.Info("Path found", slog.Int("links", len(path)-1), slog.Int64("requests", budget.sent()))
+
+			// The budget is for the search only
+			w.Client.Transport = budget.next
+
+			hops, err := describeHops(w, path, concurrency)
+			if err != nil {
+				logger.Error(err.Error(), slog.String("url", APIBaseURL))
+				os.Exit(1)
+			}
+
+			if err := writePath(os.Stdout, hops, output, header); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+// Hop represents an article of a path of links.
+type Hop struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	URL         string `json:"url" yaml:"url"`
+}
+
+func init() {
+	pathCmd.Flags().IntVar(&pathDepth, "max-depth", 6, fmt.Sprintf("Maximum number of links between the articles. Must be between 1 and %d.", maxPathDepth))
+	pathCmd.Flags().IntVar(&maxRequests, "max-requests", 200, "Maximum number of requests sent to find the path.")
+	pathCmd.Flags().IntVar(&concurrency, "concurrency", 4, fmt.Sprintf("Maximum number of requests sent at the same time. Must be between 1 and %d.", maxConcurrency))
+
+	rootCmd.AddCommand(pathCmd)
+}
+
+// validatePathFlags will determine whether the flags of the 'path' command are valid.
+func validatePathFlags(cmd *cobra.Command, args []string) error {
+	if pathDepth < 1 || pathDepth > maxPathDepth {
+		return fmt.Errorf("error: invalid value for flag 'max-depth': %d. Must be between 1 and %d", pathDepth, maxPathDepth)
+	}
+	if maxRequests < 1 {
+		return fmt.Errorf("error: invalid value for flag 'max-requests': %d. Must be strictly positive", maxRequests)
+	}
+	if concurrency < 1 || concurrency > maxConcurrency {
+		return fmt.Errorf("error: invalid value for flag 'concurrency': %d. Must be between 1 and %d", concurrency, maxConcurrency)
+	}
+
+	return validateListFlags(cmd, args)
+}
+
+// budgetTransport is a http.RoundTripper failing with ErrRequestBudget
+// once a given number of requests have been sent.
+type budgetTransport struct {
+	next      http.RoundTripper
+	budget    int64
+	requested atomic.Int64
+}
+
+// newBudgetTransport creates a budgetTransport sending at most budget requests with next,
+// or with the default transport when next is nil.
+func newBudgetTransport(next http.RoundTripper, budget int) *budgetTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &budgetTransport{next: next, budget: int64(budget)}
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.requested.Add(1) > t.budget {
+		return nil, fmt.Errorf("%w: %d requests sent", ErrRequestBudget, t.budget)
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// sent returns the number of requests sent.
+func (t *budgetTransport) sent() int64 {
+	return min(t.requested.Load(), t.budget)
+}
+
+// pathFinder searches the shortest path of links between two articles.
+// The links of the articles are cached, so that each article is requested once in each direction.
+type pathFinder struct {
+	w           *WikiClient
+	concurrency int
+
+	// forward caches the links from the articles, backward the links to them
+	forward  map[string]PageLinks
+	backward map[string]PageLinks
+
+	// redirects are the titles of the redirects met, which aren't part of the path
+	redirects map[string]bool
+}
+
+// newPathFinder creates a pathFinder requesting the links with the given client,
+// with at most concurrency requests at the same time.
+func newPathFinder(w *WikiClient, concurrency int) *pathFinder {
+	return &pathFinder{
+		w:           w,
+		concurrency: concurrency,
+		forward:     map[string]PageLinks{},
+		backward:    map[string]PageLinks{},
+		redirects:   map[string]bool{},
+	}
+}
+
+// find will search the shortest path of links from the article with the title from to the one with the title to,
+// with at most maxDepth links. It runs a breadth-first search from both articles at once, expanding the smallest
+// of the two frontiers at each step: with the links from the articles forward, with the links to them backward.
+// The backward frontier is expanded on a tie, as the redirects to its articles are then met without spending a link.
+// The articles of a frontier are requested by batches of maxTitlesBatch titles.
+// It returns the titles of the path, from and to included, ErrPathNotFound or any error encountered.
+func (p *pathFinder) find(from, to string, maxDepth int) ([]string, error) {
+	if from == to {
+		return []string{from}, nil
+	}
+
+	// The articles met from 'from' forward, and from 'to' backward
+	forwardVisits := map[string]visit{from: {}}
+	backwardVisits := map[string]visit{to: {}}
+	forwardFrontier, backwardFrontier := []string{from}, []string{to}
+
+	for depth := 0; depth < maxDepth; depth++ {
+		if len(forwardFrontier) == 0 || len(backwardFrontier) == 0 {
+			break
+		}
+
+		var meeting string
+		var err error
+		if len(forwardFrontier) < len(backwardFrontier) {
+			forwardFrontier, meeting, err = p.expand(forwardFrontier, forwardVisits, backwardVisits, true)
+		} else {
+			backwardFrontier, meeting, err = p.expand(backwardFrontier, backwardVisits, forwardVisits, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if meeting != "" {
+			return p.join(meeting, forwardVisits, backwardVisits), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w within %d links", ErrPathNotFound, maxDepth)
+}
+
+// visit represents an article met by the search in one direction.
+type visit struct {
+	// parent is the previous article of the path forward, the next one backward, empty for the origin
+	parent string
+
+	// depth is the number of links between the article and the origin
+	depth int
+}
+
+// expand will request the links of the articles of the given frontier, forward or backward,
+// and record the articles met for the first time in visits.
+// The redirects don't count as links: backward, a redirect to an article is recorded at the depth of the article
+// and the pages linking to it are requested in the same step.
+// It returns the next frontier and the article met which is already known from the other direction
+// with the shortest path, empty if none, or any error encountered.
+func (p *pathFinder) expand(frontier []string, visits, others map[string]visit, forward bool) ([]string, string, error) {
+	var next []string
+	meeting := ""
+	meet := func(title string) {
+		o, ok := others[title]
+		if ok && (meeting == "" || o.depth < others[meeting].depth) {
+			meeting = title
+		}
+	}
+
+	for titles := frontier; len(titles) > 0; {
+		links, err := p.links(titles, forward)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var redirects []string
+		for _, title := range titles {
+			l := links[title]
+
+			// Following a redirect forward leads to its target, which takes its place in the path
+			// and is the source of the links
+			source := title
+			if l.Title != title {
+				p.redirects[title] = true
+				if _, ok := visits[l.Title]; !ok {
+					visits[l.Title] = visits[title]
+					meet(l.Title)
+				}
+				source = l.Title
+			}
+			depth := visits[source].depth + 1
+
+			for _, r := range l.Links {
+				if _, ok := visits[r.Title]; ok {
+					continue
+				}
+
+				// A redirect backward stands for the article it redirects to
+				if r.Redirect {
+					p.redirects[r.Title] = true
+					visits[r.Title] = visit{parent: source, depth: visits[source].depth}
+					redirects = append(redirects, r.Title)
+					meet(r.Title)
+					continue
+				}
+
+				visits[r.Title] = visit{parent: source, depth: depth}
+				next = append(next, r.Title)
+				meet(r.Title)
+			}
+		}
+
+		titles = redirects
+	}
+
+	return next, meeting, nil
+}
+
+// links returns the links from the given articles when forward is true, the links to them otherwise.
+// The articles which aren't cached yet are requested by batches, concurrently.
+func (p *pathFinder) links(titles []string, forward bool) (map[string]PageLinks, error) {
+	cache := p.backward
+	get := p.w.GetPagesLinksHere
+	if forward {
+		cache = p.forward
+		get = p.w.GetPagesLinks
+	}
+
+	var missing []string
+	for _, t := range titles {
+		if _, ok := cache[t]; !ok {
+			missing = append(missing, t)
+		}
+	}
+
+	fetched, err := getBatched(missing, p.concurrency, func(batch []string) (map[string]PageLinks, error) {
+		return get(batch, []int{articleNamespace}, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(cache, fetched)
+
+	links := make(map[string]PageLinks, len(titles))
+	for _, t := range titles {
+		links[t] = cache[t]
+	}
+
+	return links, nil
+}
+
+// join returns the path going through the given article, from the articles met in both directions.
+// The redirects are left out of the path, as the links go through them to their target.
+func (p *pathFinder) join(meeting string, forwardVisits, backwardVisits map[string]visit) []string {
+	var path []string
+	for t := meeting; t != ""; t = forwardVisits[t].parent {
+		path = append(path, t)
+	}
+	slices.Reverse(path)
+	for t := backwardVisits[meeting].parent; t != ""; t = backwardVisits[t].parent {
+		path = append(path, t)
+	}
+
+	last := len(path) - 1
+	return slices.DeleteFunc(path, func(t string) bool {
+		return p.redirects[t] && t != path[0] && t != path[last]
+	})
+}
+
+// describeHops returns the given path with the short description of each article,
+// requested by batches of maxTitlesBatch titles with at most concurrency requests at the same time.
+func describeHops(w *WikiClient, path []string, concurrency int) ([]Hop, error) {
+	descriptions, err := getBatched(path, concurrency, w.GetShortDescriptions)
+	if err != nil {
+		return nil, err
+	}
+
+	hops := make([]Hop, len(path))
+	for i, t := range path {
+		hops[i] = Hop{Title: t, Description: descriptions[t], URL: articleURL(lang, t)}
+	}
+
+	return hops, nil
+}
+
+// writePath will write the given path to w in the given output format.
+// The plain output is an article per line with its short description, the markdown output a numbered list of links.
+// The csv and tsv outputs start with a row of the column names when header is true.
+func writePath(w io.Writer, hops []Hop, format string, header bool) error {
+	t := Table{Columns: []string{"title", "description", "url"}}
+	for _, h := range hops {
+		t.Rows = append(t.Rows, []string{h.Title, h.Description, h.URL})
+	}
+
+	// write writes a line per article, with its short description
+	write := func(w io.Writer, line func(i int, h Hop) string) error {
+		for i, h := range hops {
+			l := line(i, h)
+			if h.Description != "" {
+				l += ": " + h.Description
+			}
+			if _, err := fmt.Fprintln(w, l); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return writeList(w, List{
+		Title: fmt.Sprintf("Path from %s to %s", hops[0].Title, hops[len(hops)-1].Title),
+		Value: struct {
+			Links int   `json:"links" yaml:"links"`
+			Path  []Hop `json:"path" yaml:"path"`
+		}{Links: len(hops) - 1, Path: hops},
+		Table: t,
+		Plain: func(w io.Writer) error {
+			return write(w, func(i int, h Hop) string { return fmt.Sprintf("%*d  %s", len(fmt.Sprint(len(hops))), i+1, h.Title) })
+		},
+		Markdown: func(w io.Writer) error {
+			return write(w, func(i int, h Hop) string { return fmt.Sprintf("%d. %s", i+1, markdownLink(h.Title, h.URL)) })
+		},
+	}, format, header)
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// linkGraph is a synthetic graph of links between articles, with the links of each article.
+// "Golang" is a redirect to "Go".
+var linkGraph = map[string][]string{
+	"Go":           {"C", "Google", "Rob Pike"},
+	"C":            {"Unix"},
+	"Google":       {},
+	"Rob Pike":     {"Bell Labs", "Go"},
+	"Bell Labs":    {"Unix"},
+	"Unix":         {"Ken Thompson"},
+	"Ken Thompson": {"Golang", "Unix"},
+}
+
+// linkGraphCategories are the categories of the articles of the linkGraph
+var linkGraphCategories = map[string][]string{
+	"Go":           {"Category:Programming languages", "Category:Google software"},
+	"C":            {"Category:Programming languages"},
+	"Google":       {"Category:Google software", "Category:Companies"},
+	"Ken Thompson": {"Category:Programmers"},
+}
+
+// linkGraphRedirects are the redirects of the linkGraph, to their target
+var linkGraphRedirects = map[string]string{"Golang": "Go"}
+
+// newStubLinkGraphAPI starts a http server serving the links of the given graph, the short descriptions
+// of its articles, the description of an article being "About <title>", and the linkGraphCategories.
+// The requests are counted in requests.
+func newStubLinkGraphAPI(t *testing.T, graph map[string][]string, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		q := r.URL.Query()
+
+		titles := strings.Split(q.Get("titles"), "|")
+		var redirects []titleMapping
+		var pages []map[string]any
+
+		for _, title := range titles {
+			if target, ok := linkGraphRedirects[title]; ok && q.Get("redirects") == "1" {
+				redirects = append(redirects, titleMapping{From: title, To: target})
+				title = target
+			}
+
+			page := map[string]any{"ns": 0, "title": title}
+			switch q.Get("prop") {
+			case "links":
+				assert.Equal(t, "0", q.Get("plnamespace"))
+
+				links := []PageRef{}
+				for _, l := range graph[title] {
+					links = append(links, PageRef{Title: l})
+				}
+				page["links"] = links
+
+			case "linkshere":
+				assert.Equal(t, "0", q.Get("lhnamespace"))
+
+				var from []string
+				for source, links := range graph {
+					if slices.Contains(links, title) {
+						from = append(from, source)
+					}
+				}
+				slices.Sort(from)
+
+				linkshere := []PageRef{}
+				for _, source := range from {
+					linkshere = append(linkshere, PageRef{Title: source})
+				}
+				for redirect, target := range linkGraphRedirects {
+					if target == title {
+						linkshere = append(linkshere, PageRef{Title: redirect, Redirect: true})
+					}
+				}
+				page["linkshere"] = linkshere
+
+			case "categories":
+				assert.Equal(t, "!hidden", q.Get("clshow"))
+
+				categories := []PageRef{}
+				for _, c := range linkGraphCategories[title] {
+					categories = append(categories, PageRef{Ns: categoryNamespace, Title: c})
+				}
+				page["categories"] = categories
+
+			case "pageprops":
+				page["pageprops"] = map[string]string{"wikibase-shortdesc": "About " + title}
+			}
+			pages = append(pages, page)
+		}
+
+		b, _ := json.Marshal(map[string]any{"batchcomplete": true, "query": map[string]any{"redirects": redirects, "pages": pages}})
+		w.Write(b)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestPathFinderFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		graph    map[string][]string
+		from     string
+		to       string
+		maxDepth int
+		want     []string
+		wantErr  error
+	}{
+		{
+			name:     "Same article",
+			from:     "Go",
+			to:       "Go",
+			maxDepth: 1,
+			want:     []string{"Go"},
+		},
+		{
+			name:     "Direct link",
+			from:     "Go",
+			to:       "Rob Pike",
+			maxDepth: 1,
+			want:     []string{"Go", "Rob Pike"},
+		},
+		{
+			name:     "Shortest path",
+			from:     "Go",
+			to:       "Ken Thompson",
+			maxDepth: 6,
+			want:     []string{"Go", "C", "Unix", "Ken Thompson"},
+		},
+		{
+			name:     "Through a redirect",
+			from:     "Ken Thompson",
+			to:       "Go",
+			maxDepth: 1,
+			want:     []string{"Ken Thompson", "Go"},
+		},
+		{
+			name:     "Through a redirect further",
+			from:     "Unix",
+			to:       "Go",
+			maxDepth: 2,
+			want:     []string{"Unix", "Ken Thompson", "Go"},
+		},
+		{
+			// "X" has more pages linking to it than "A" has links, so "Golang" is followed forward
+			name:     "Through a redirect forward",
+			graph:    map[string][]string{"A": {"Golang"}, "Go": {"M"}, "M": {"X"}, "Y": {"X"}, "Z": {"X"}},
+			from:     "A",
+			to:       "X",
+			maxDepth: 3,
+			want:     []string{"A", "Go", "M", "X"},
+		},
+		{
+			name:     "Too far",
+			from:     "Go",
+			to:       "Ken Thompson",
+			maxDepth: 2,
+			wantErr:  ErrPathNotFound,
+		},
+		{
+			name:     "Not linked",
+			from:     "Google",
+			to:       "Go",
+			maxDepth: 6,
+			wantErr:  ErrPathNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := linkGraph
+			if tt.graph != nil {
+				graph = tt.graph
+			}
+
+			var requests atomic.Int32
+			ts := newStubLinkGraphAPI(t, graph, &requests)
+			w, err := NewWikiClient(ts.URL, "")
+			assert.NoError(t, err)
+
+			got, err := newPathFinder(w, 2).find(tt.from, tt.to, tt.maxDepth)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPathFinderLinks(t *testing.T) {
+	// A hub linking to more articles than a batch
+	graph := map[string][]string{}
+	var titles []string
+	for i := range maxTitlesBatch + 10 {
+		title := fmt.Sprintf("Page %d", i)
+		graph[title] = []string{"Hub"}
+		titles = append(titles, title)
+	}
+	graph["Hub"] = titles
+
+	var requests atomic.Int32
+	ts := newStubLinkGraphAPI(t, graph, &requests)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	p := newPathFinder(w, 2)
+	got, err := p.links(titles, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Len(t, got, len(titles))
+	assert.Equal(t, PageLinks{Title: "Page 59", Links: []PageRef{{Title: "Hub"}}}, got["Page 59"])
+
+	// The links are cached
+	_, err = p.links(append(titles, "Hub"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestPathFinderBudget(t *testing.T) {
+	var requests atomic.Int32
+	ts := newStubLinkGraphAPI(t, linkGraph, &requests)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	budget := newBudgetTransport(nil, 2)
+	w.Client.Transport = budget
+
+	_, err = newPathFinder(w, 1).find("Go", "Ken Thompson", 6)
+	assert.ErrorIs(t, err, ErrRequestBudget)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, int64(2), budget.sent())
+}
+
+func TestDescribeHops(t *testing.T) {
+	var requests atomic.Int32
+	ts := newStubLinkGraphAPI(t, linkGraph, &requests)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+	lang = "en"
+
+	got, err := describeHops(w, []string{"Go", "Golang", "Ken Thompson"}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []Hop{
+		{Title: "Go", Description: "About Go", URL: "https://en.wikipedia.org/wiki/Go"},
+		{Title: "Golang", Description: "About Go", URL: "https://en.wikipedia.org/wiki/Golang"},
+		{Title: "Ken Thompson", Description: "About Ken Thompson", URL: "https://en.wikipedia.org/wiki/Ken_Thompson"},
+	}, got)
+}
+
+func TestWritePath(t *testing.T) {
+	hops := []Hop{
+		{Title: "Go", Description: "Programming language", URL: "https://en.wikipedia.org/wiki/Go"},
+		{Title: "Ken Thompson", URL: "https://en.wikipedia.org/wiki/Ken_Thompson"},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "Plain",
+			format: "plain",
+			want:   "1  Go: Programming language\n2  Ken Thompson\n",
+		},
+		{
+			name:   "Markdown",
+			format: "markdown",
+			want:   "1. [Go](https://en.wikipedia.org/wiki/Go): Programming language\n2. [Ken Thompson](https://en.wikipedia.org/wiki/Ken_Thompson)\n",
+		},
+		{
+			name:   "JSON",
+			format: "json",
+			want:   "{\n    \"links\": 1,\n    \"path\": [\n        {\n            \"title\": \"Go\",\n            \"description\": \"Programming language\",\n            \"url\": \"https://en.wikipedia.org/wiki/Go\"\n        },\n        {\n            \"title\": \"Ken Thompson\",\n            \"url\": \"https://en.wikipedia.org/wiki/Ken_Thompson\"\n        }\n    ]\n}\n",
+		},
+		{
+			name:   "YAML",
+			format: "yaml",
+			want:   "links: 1\npath:\n- title: Go\n  description: Programming language\n  url: https://en.wikipedia.org/wiki/Go\n- title: Ken Thompson\n  url: https://en.wikipedia.org/wiki/Ken_Thompson\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writePath(w, hops, tt.format, false)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+}
+
+func TestValidatePathFlags(t *testing.T) {
+	logLevel, logFormat = "error", "text"
+	t.Cleanup(func() {
+		output, templateText, pathDepth, maxRequests, concurrency = "plain", "", 6, 200, 4
+	})
+
+	pathDepth, maxRequests, concurrency = 6, 200, 4
+	templateText = "{{.}}"
+	for _, o := range listOutputs {
+		output = o
+		assert.NoError(t, validatePathFlags(pathCmd, nil), o)
+	}
+
+	output = "dot"
+	assert.Error(t, validatePathFlags(pathCmd, nil))
+
+	output, pathDepth = "plain", 0
+	assert.Error(t, validatePathFlags(pathCmd, nil))
+
+	pathDepth, maxRequests = 6, 0
+	assert.Error(t, validatePathFlags(pathCmd, nil))
+
+	maxRequests, concurrency = 200, maxConcurrency+1
+	assert.Error(t, validatePathFlags(pathCmd, nil))
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// externalFormatterPrefix is the prefix of the executables in $PATH used as external formatters.
+// The 'foo' output is rendered by the 'wpdia-go-format-foo' executable.
+const externalFormatterPrefix = "wpdia-go-format-"
+
+// DisplayerInfo represents an output format and its metadata.
+type DisplayerInfo struct {
+	// Name is the value of the 'output' flag selecting the format
+	Name string
+
+	// Aliases are alternative names of the format, ie. 'md' for 'markdown'
+	Aliases []string
+
+	// Description is a short description of the format, displayed in the help
+	Description string
+
+	// Attribution is whether the attribution of the page is written by default,
+	// ie. for the formats republished as documents. It is overridden by the 'attribution' flag.
+	Attribution bool
+
+	// New creates the Displayer of the format.
+	// It is called after the flags are parsed, so it can use their values.
+	New func() (Displayer, error)
+
+	// path is the executable of an external formatter, empty for the registered formats
+	path string
+}
+
+// displayerRegistry holds the registered output formats, in their registration order.
+type displayerRegistry struct {
+	infos []DisplayerInfo
+}
+
+// displayers is the registry of the output formats.
+// It is populated with the builtin formats before any init() function runs.
+var displayers = newBuiltinDisplayers()
+
+// RegisterDisplayer will make an output format available through the 'output' flag.
+// It panics if the name is empty or already registered.
+func RegisterDisplayer(info DisplayerInfo) {
+	if err := displayers.register(info); err != nil {
+		panic(err)
+	}
+}
+
+// register will add the given output format to the registry.
+// It returns an error if the name is empty or already registered.
+func (r *displayerRegistry) register(info DisplayerInfo) error {
+	if info.Name == "" || info.New == nil {
+		return fmt.Errorf("displayer: name and constructor are required")
+	}
+
+	for _, name := range append([]string{info.Name}, info.Aliases...) {
+		if _, ok := r.get(name); ok {
+			return fmt.Errorf("displayer: %q is already registered", name)
+		}
+	}
+
+	r.infos = append(r.infos, info)
+
+	return nil
+}
+
+// get returns the registered output format with the given name or alias.
+func (r *displayerRegistry) get(name string) (DisplayerInfo, bool) {
+	for _, info := range r.infos {
+		if info.Name == name || isPresent(info.Aliases, name) {
+			return info, true
+		}
+	}
+	return DisplayerInfo{}, false
+}
+
+// lookup returns the output format with the given name: either a registered format,
+// or an external formatter found in $PATH.
+func (r *displayerRegistry) lookup(name string) (DisplayerInfo, bool) {
+	if info, ok := r.get(name); ok {
+		return info, true
+	}
+
+	// External formatter names must not be paths
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return DisplayerInfo{}, false
+	}
+
+	path, err := exec.LookPath(externalFormatterPrefix + name)
+	if err != nil {
+		return DisplayerInfo{}, false
+	}
+
+	return DisplayerInfo{
+		Name:        name,
+		Description: fmt.Sprintf("External formatter %s", path),
+		New: func() (Displayer, error) {
+			return NewExternalFormat(path), nil
+		},
+		path: path,
+	}, true
+}
+
+// names returns the names of the registered output formats.
+func (r *displayerRegistry) names() []string {
+	names := make([]string, 0, len(r.infos))
+	for _, info := range r.infos {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+// usage returns the list of the registered output formats with their description, one per line.
+func (r *displayerRegistry) usage() string {
+	var b strings.Builder
+
+	names := make([]string, 0, len(r.infos))
+	width := 0
+	for _, info := range r.infos {
+		name := strings.Join(append([]string{info.Name}, info.Aliases...), ", ")
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+
+	for i, info := range r.infos {
+		fmt.Fprintf(&b, "  %-*s  %s\n", width, names[i], info.Description)
+	}
+
+	return b.String()
+}
+
+// newBuiltinDisplayers creates the registry of the output formats shipped with wpdia-go.
+func newBuiltinDisplayers() *displayerRegistry {
+	r := &displayerRegistry{}
+
+	for _, info := range []DisplayerInfo{
+		{
+			Name:        "plain",
+			Description: "Plain text (--wrap)",
+			New:         func() (Displayer, error) { return NewPlainFormat(wrap), nil },
+		},
+		{
+			Name:        "pretty",
+			Description: "Rendered markdown for the terminal (--width, --theme)",
+			New:         func() (Displayer, error) { return NewPrettyFormat(prettyOptions()), nil },
+		},
+		{
+			Name:        "json",
+			Description: "Indented JSON",
+			New:         func() (Displayer, error) { return NewJsonFormat("", "    "), nil },
+		},
+		{
+			Name:        "yaml",
+			Description: "YAML",
+			New:         func() (Displayer, error) { return NewYamlFormat(), nil },
+		},
+		{
+			Name:        "markdown",
+			Aliases:     []string{"md"},
+			Description: "Markdown, with an optional YAML front matter (--front-matter)",
+			Attribution: true,
+			New:         func() (Displayer, error) { return NewMarkdownFormat(frontMatter), nil },
+		},
+		{
+			Name:        "template",
+			Description: "User-defined Go template (--template, --template-file)",
+			New: func() (Displayer, error) {
+				text, err := readTemplate()
+				if err != nil {
+					return nil, err
+				}
+
+				return NewTemplateFormat(text)
+			},
+		},
+		{
+			Name:        "ndjson",
+			Description: "One JSON object per line",
+			New:         func() (Displayer, error) { return NewNdjsonFormat(), nil },
+		},
+		{
+			Name:        "csv",
+			Description: "Comma-separated values (--fields, --header)",
+			New:         func() (Displayer, error) { return NewCsvFormat(header), nil },
+		},
+		{
+			Name:        "tsv",
+			Description: "Tab-separated values (--fields, --header)",
+			New:         func() (Displayer, error) { return NewTsvFormat(header), nil },
+		},
+		{
+			Name:        "html",
+			Description: "Standalone HTML document",
+			Attribution: true,
+			New:         func() (Displayer, error) { return NewHtmlFormat(), nil },
+		},
+	} {
+		if err := r.register(info); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// prettyOptions returns the options of the 'pretty' output, from the flags and the environment.
+func prettyOptions() PrettyOptions {
+	return PrettyOptions{
+		Width:   width,
+		Theme:   theme,
+		NoColor: os.Getenv("NO_COLOR") != "",
+		Pager:   pagerCommand(),
+	}
+}
+
+// readTemplate returns the text of the 'template' output: the 'template' flag,
+// or the content of the 'template-file' flag when set.
+func readTemplate() (string, error) {
+	if templateFile == "" {
+		return templateText, nil
+	}
+
+	b, err := os.ReadFile(templateFile)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// externalFormat is a formatter delegating the rendering to an external executable.
+type externalFormat struct {
+	path string
+}
+
+// NewExternalFormat creates a formatter running the executable at the given path.
+// The executable receives the page as JSON on its standard input
+// and must write the rendered output on its standard output.
+// The language of the page is given in the WPDIA_GO_LANG environment variable.
+func NewExternalFormat(path string) *externalFormat {
+	return &externalFormat{path: path}
+}
+
+func (d *externalFormat) Write(w io.Writer, v *PageView) error {
+	return d.run(w, v.Document(), v.Lang)
+}
+
+// run will give v as JSON to the executable and write its output to w,
+// along with the given language in the environment.
+func (d *externalFormat) run(w io.Writer, v any, lang string) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+
+	c := exec.Command(d.path)
+	c.Stdin = bytes.NewReader(b)
+	c.Stdout = w
+	c.Stderr = &stderr
+	c.Env = append(os.Environ(), "WPDIA_GO_LANG="+lang)
+
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("external formatter %s failed: %w: %s", d.path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayerRegistryRegister(t *testing.T) {
+	newPlain := func() (Displayer, error) { return NewPlainFormat(0), nil }
+
+	tests := []struct {
+		name    string
+		info    DisplayerInfo
+		wantErr bool
+	}{
+		{
+			name:    "New format",
+			info:    DisplayerInfo{Name: "custom", New: newPlain},
+			wantErr: false,
+		},
+		{
+			name:    "Already registered",
+			info:    DisplayerInfo{Name: "plain", New: newPlain},
+			wantErr: true,
+		},
+		{
+			name:    "Already registered alias",
+			info:    DisplayerInfo{Name: "custom", Aliases: []string{"md"}, New: newPlain},
+			wantErr: true,
+		},
+		{
+			name:    "Empty name",
+			info:    DisplayerInfo{Name: "", New: newPlain},
+			wantErr: true,
+		},
+		{
+			name:    "Without constructor",
+			info:    DisplayerInfo{Name: "custom"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newBuiltinDisplayers()
+			err := r.register(tt.info)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Contains(t, r.names(), tt.info.Name)
+			}
+		})
+	}
+}
+
+func TestBuiltinDisplayers(t *testing.T) {
+	r := newBuiltinDisplayers()
+
+	assert.Equal(t, []string{"plain", "pretty", "json", "yaml", "markdown", "template", "ndjson", "csv", "tsv", "html"}, r.names())
+
+	// Every builtin format must be creatable with the default flags,
+	// except the template one which requires a template
+	for _, info := range r.infos {
+		if info.Name == "template" {
+			continue
+		}
+
+		d, err := info.New()
+		assert.NoError(t, err, info.Name)
+		assert.NotNil(t, d, info.Name)
+		assert.NotEmpty(t, info.Description, info.Name)
+	}
+
+	usage := r.usage()
+	assert.Contains(t, usage, "  plain         Plain text (--wrap)\n")
+	assert.Contains(t, usage, "  markdown, md  Markdown")
+	assert.Equal(t, len(r.infos), strings.Count(usage, "\n"))
+}
+
+func TestRegisteredDisplayerHelp(t *testing.T) {
+	infos := displayers.infos
+	t.Cleanup(func() {
+		displayers.infos = infos
+		rootCmd.SetOut(nil)
+	})
+
+	// A format registered after the init() of the package, ie. by an importing package
+	displayers.infos = slices.Clone(infos)
+	RegisterDisplayer(DisplayerInfo{Name: "late", Description: "Registered late", New: func() (Displayer, error) { return NewPlainFormat(0), nil }})
+
+	for range 2 {
+		b := &bytes.Buffer{}
+		rootCmd.SetOut(b)
+		rootCmd.HelpFunc()(rootCmd, nil)
+
+		assert.Contains(t, b.String(), "  late          Registered late\n")
+		assert.Contains(t, b.String(), "late], or the name of an external formatter")
+		assert.Equal(t, 1, strings.Count(b.String(), "Output formats:"))
+	}
+}
+
+// newExternalFormatter writes an external formatter named 'wpdia-go-format-<name>' with the given shell script
+// in a temporary directory, and sets it as the only directory of $PATH.
+func newExternalFormatter(t *testing.T, name, script string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("external formatters are shell scripts")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, externalFormatterPrefix+name)
+	assert.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	t.Setenv("PATH", dir)
+
+	return path
+}
+
+func TestDisplayerRegistryLookup(t *testing.T) {
+	path := newExternalFormatter(t, "echo", "exit 0\n")
+	r := newBuiltinDisplayers()
+
+	info, ok := r.lookup("json")
+	assert.True(t, ok)
+	assert.Equal(t, "json", info.Name)
+
+	info, ok = r.lookup("md")
+	assert.True(t, ok)
+	assert.Equal(t, "markdown", info.Name)
+
+	info, ok = r.lookup("echo")
+	assert.True(t, ok)
+	assert.Equal(t, "echo", info.Name)
+	assert.Contains(t, info.Description, path)
+
+	_, ok = r.lookup("unknown")
+	assert.False(t, ok)
+
+	_, ok = r.lookup("../echo")
+	assert.False(t, ok)
+}
+
+func TestExternalFormatWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		full    bool
+		wantW   string
+		wantErr bool
+	}{
+		{
+			name:   "Page JSON on stdin",
+			script: "read -r line\necho \"$WPDIA_GO_LANG $line\"\n",
+			full:   false,
+			wantW:  "fr {\"title\":\"Golang\",\"extract\":\"" + page.Extract + "\"}\n",
+		},
+		{
+			name:    "Failing formatter",
+			script:  "echo 'something went wrong' >&2\nexit 3\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := newExternalFormatter(t, "test", tt.script)
+			w := &bytes.Buffer{}
+
+			err := NewExternalFormat(path).Write(w, NewPageView(&page, ViewOptions{Lang: "fr", Fields: selectFields(nil, tt.full)}))
+
+			assert.Equal(t, tt.wantW, w.String())
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "something went wrong")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
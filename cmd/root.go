@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"time"
 
 	internallogger "github.com/lescactus/wpdia-go/internal/logger"
@@ -12,6 +14,12 @@ import (
 
 const (
 	version = "0.4.1"
+
+	// maxExsentences is the maximum value of the 'exsentences' flag
+	maxExsentences = 10
+
+	// maxExchars is the maximum value of the 'chars' flag accepted by the TextExtracts API
+	maxExchars = 1200
 )
 
 var (
@@ -22,18 +30,41 @@ var (
 	timeout     time.Duration // http client timeout
 	lang        string        // language of the Wikipedia page
 	output      string        // output formatter of the program
-	exsentences string        // number of sentences to return from a page
+	exsentences int           // number of sentences to return from a page
 	exintro     bool          // whether or not to only the intro of a page
+	frontMatter bool          // whether or not to prepend a YAML front matter to the markdown output
 	fullOutput  bool          // whether or not to output also the page namespace and page id
 
+	fields []string // fields to output
+	header bool     // whether or not to write a header row in the 'csv' and 'tsv' outputs
+
+	templateText string // user-defined Go template of the 'template' output
+	templateFile string // file containing the user-defined Go template of the 'template' output
+
+	wrap  int    // width the 'plain' output is wrapped at, not wrapped when 0
+	width int    // width of the 'pretty' output, the width of the terminal when 0
+	theme string // theme of the 'pretty' output, or path to a glamour JSON style file
+
+	outputFiles []string // additional destinations of the page, as "<format>:<path>"
+	overwrite   string   // overwrite policy of the existing output files
+
 	logger    *slog.Logger
 	logLevel  string
 	logFormat string
 
 	randomPage bool // whether or not to look for a random page
 
-	// validOutputs represents the authorized values for the 'output' flag
-	validOutputs = []string{"plain", "pretty", "json", "yaml"}
+	section string // title or index of the section to return
+
+	fullArticle bool // whether or not to return the whole article
+	exchars     int  // number of characters to return from a page, not limited when 0
+	words       int  // number of words the extract is truncated at, on a sentence boundary. Not truncated when 0
+
+	withWikidata bool // whether or not to fetch the Wikidata entity of the page
+	withInfobox  bool // whether or not to extract the infobox of the page
+	withCoords   bool // whether or not to request the coordinates of the page
+
+	withAttribution bool // whether or not to write the attribution of the page, defaults to the one of the output format
 
 	// validLogLevel represents the authorized values for the 'loglevel' flag
 	validLogLevels = []string{"debug", "info", "warn", "error"}
@@ -90,54 +121,40 @@ The source code is available at https://github.com/lescactus/wpedia-go.`,
 				exintro = false
 			}
 
-			logger.Info("Getting text extract...", slog.String("title", title), slog.Bool("random", randomPage))
-
-			var extract *WikiTextExtractResponse
-			if randomPage {
-				// Call the Random API
-				extract, err = w.GetExtractRandom()
-			} else {
-				logger.Info("Searching title...", slog.String("title", title))
+			// A section can be anywhere in the article, which is requested entirely
+			if section != "" || fullArticle {
+				exintro = false
+				exsentences = 0
+			}
 
-				// Get the id of the page requested
-				var id uint64
-				id, err = w.SearchTitle(title)
-				if err != nil {
-					logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.String("title", title))
-					os.Exit(1)
-				}
+			// The output format and files have been validated with the flags
+			info, _ := displayers.lookup(output)
 
-				logger.Debug("Title found")
+			var files []*outputFile
+			for _, s := range outputFiles {
+				o, _ := parseOutputFile(s)
+				files = append(files, o)
+			}
 
-				// If the search was unsuccessful
-				if id == 0 {
-					logger.Error("Error: no page found on Wikipedia for the given query", slog.String("title", title))
-					os.Exit(1)
-				}
+			logger.Info("Getting text extract...", slog.String("title", title), slog.Bool("random", randomPage))
 
-				// Call the TextExtracts API for the requested page id
-				extract, err = w.GetExtract(id)
+			var page *Page
+			if randomPage {
+				page, err = w.GetPageRandom()
+			} else {
+				page, err = w.GetPage(title)
 			}
 
-			if err != nil {
-				logger.Info("Error: %s", slog.String("title", title), slog.Bool("random", randomPage))
+			if errors.Is(err, ErrPageNotFound) {
+				logger.Error("Error: no page found on Wikipedia for the given query", slog.String("title", title))
 				os.Exit(1)
 			}
-
-			logger.Debug("Text extract found", slog.String("title", title), slog.Bool("random", randomPage))
-
-			// Because we request only 1 page from Wikipedia's API,
-			// extract.Query.Pages **should be** a map of only one element
-			// If it is unexpectedly not the case, exit the program immediately with an error.
-			if len(extract.Query.Pages) != 1 {
-				logger.Error(fmt.Sprintf("Expected an anwser of 1 page, got %d", len(extract.Query.Pages)))
+			if err != nil {
+				logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.String("title", title), slog.Bool("random", randomPage))
 				os.Exit(1)
 			}
 
-			var page Page
-			for _, v := range extract.Query.Pages {
-				page = v
-			}
+			logger.Debug("Text extract found", slog.String("title", title), slog.Bool("random", randomPage))
 
 			// Ensure the page isn't a disambiguation
 			// In the case it is, simply print a message saying to refine the search
@@ -151,30 +168,100 @@ For example, Mercury can refer to a chemical element, a planet, a Roman god, and
 
 Try to refine the search in a more precise manner. Example:
 	'Nancy France' instead of 'Nancy' - or 'Go verb' instead of 'Go'`
+			} else {
+				if section != "" {
+					page.Extract, err = selectSection(page.Extract, section)
+					if err != nil {
+						logger.Error(err.Error(), slog.String("title", page.Title))
+						os.Exit(1)
+					}
+				}
+
+				if n := sentencesLimit(); n > 0 {
+					page.Extract = firstSentences(lang, n, page.Extract)
+				}
+
+				if words > 0 {
+					page.Extract = limitWords(lang, words, page.Extract)
+				}
+			}
+
+			if withWikidata {
+				page.Wikidata, err = getWikidata(page)
+				if err != nil {
+					logger.Error(err.Error(), slog.String("url", WikidataAPIBaseURL), slog.String("title", page.Title))
+					os.Exit(1)
+				}
+			}
+
+			viewFields := selectFields(fields, fullOutput)
+			if withInfobox && !isPresent(viewFields, "infobox") {
+				viewFields = append(slices.Clone(viewFields), "infobox")
+			}
+			if withCoords && !isPresent(viewFields, "coordinates") {
+				viewFields = append(slices.Clone(viewFields), "coordinates")
+			}
+
+			if isPresent(viewFields, "coordinates") && len(page.Coordinates) == 0 {
+				logger.Warn("The page has no coordinates", slog.String("title", page.Title))
+			}
+
+			if isPresent(viewFields, "infobox") {
+				page.Infobox, err = getInfobox(w, page)
+				if err != nil {
+					logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.String("title", page.Title))
+					os.Exit(1)
+				}
 			}
 
 			logger.Debug("Setting formatter...")
 
 			// Output formatter options
-			var d Displayer
-			switch output {
-			case "plain":
-				d = NewPlainFormat()
-			case "pretty":
-				d = NewPrettyFormat(100)
-			case "json":
-				d = NewJsonFormat("", "    ")
-			case "yaml":
-				d = NewYamlFormat()
+			d, err := info.New()
+			if err != nil {
+				logger.Error(err.Error(), slog.String("output", output))
+				os.Exit(1)
 			}
 			logger.Debug(fmt.Sprintf("Formatter set to %s", output))
 
+			// The view is a copy of the page, with the fields to display.
+			// The attribution depends on the output format.
+			attributionSet := cmd.Flags().Changed("attribution")
+			newView := func(info DisplayerInfo) *PageView {
+				f := viewFields
+				if hasAttribution(info, attributionSet) && !isPresent(f, "attribution") {
+					f = append(slices.Clone(f), "attribution")
+				}
+				return NewPageView(page, ViewOptions{Lang: lang, Fields: f, Selected: len(fields) > 0})
+			}
+			v := newView(info)
+
 			// Write extract to the terminal
-			d.Write(os.Stdout, &page, fullOutput)
+			err = d.Write(os.Stdout, v)
 			if err != nil {
 				logger.Error(err.Error())
 				os.Exit(1)
 			}
+
+			// Write the same page to the output files
+			failed := false
+			for _, o := range files {
+				path, written, err := o.write(newView(o.info), overwrite)
+				if err != nil {
+					logger.Error(err.Error(), slog.String("output", o.info.Name), slog.String("path", path))
+					failed = true
+					continue
+				}
+
+				if written {
+					logger.Info("Output file written", slog.String("output", o.info.Name), slog.String("path", path))
+				} else {
+					logger.Warn("Output file already exists, skipping", slog.String("output", o.info.Name), slog.String("path", path))
+				}
+			}
+			if failed {
+				os.Exit(1)
+			}
 		},
 
 		Version: version,
@@ -192,21 +279,66 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&lang, "lang", "l", "en", "Language. This will set the API endpoint used to retrieve data.")
-	rootCmd.PersistentFlags().StringVarP(&exsentences, "exsentences", "s", "10", "How many sentences to return from Wikipedia. Must be between 1 and 10. If > 10, then default to 10. Mutually exclusive with 'exintro'.")
+	rootCmd.PersistentFlags().IntVarP(&exsentences, "exsentences", "s", maxExsentences, fmt.Sprintf("How many sentences to return from Wikipedia. Must be between 1 and %d. The sentences are split locally, in the language of the page. Mutually exclusive with 'exintro'.", maxExsentences))
 	rootCmd.PersistentFlags().BoolVarP(&exintro, "exintro", "i", true, "Return only content before the first section. Mutually exclusive with 'exsentences'.")
 	rootCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "t", 15*time.Second, "Timeout value of the http client to the Wikipedia API. Examples values: '10s', '500ms'")
-	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "plain", fmt.Sprintf("Output type. Valid choices are %v.", validOutputs))
-	rootCmd.PersistentFlags().BoolVarP(&fullOutput, "full", "f", false, "Also print the page Namespace and page ID.")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "plain", outputUsage())
+	rootCmd.Flags().StringVar(&section, "section", "", "Return only the given section of the article, by title or index as listed by the 'toc' command. 0 is the content before the first section. Mutually exclusive with 'exsentences'.")
+	rootCmd.Flags().BoolVar(&fullArticle, "full-article", false, "Return the whole article. Mutually exclusive with 'exintro', 'exsentences' and 'chars'.")
+	rootCmd.Flags().IntVar(&exchars, "chars", 0, fmt.Sprintf("How many characters to return from Wikipedia, from the content before the first section, or from the whole article with '--exintro=false'. Must be between 1 and %d. Mutually exclusive with 'exsentences', 'section' and 'full-article'.", maxExchars))
+	rootCmd.Flags().IntVar(&words, "words", 0, "Truncate the extract at the last sentence ending within the given number of words. Not truncated when 0.")
+	rootCmd.Flags().BoolVar(&withInfobox, "infobox", false, "Also extract the first infobox of the page as key/value pairs, with the links and markup removed. Same as adding 'infobox' to the 'fields' flag.")
+	rootCmd.Flags().BoolVar(&withCoords, "coords", false, "Also request the primary coordinates of the page: latitude, longitude and globe. Same as adding 'coordinates' to the 'fields' flag.")
+	rootCmd.Flags().BoolVar(&withAttribution, "attribution", false, "Also write the attribution required by the license of the text: the article URL, the permalink of its revision, the history of its contributors and the license. Enabled by default in the 'html' and 'markdown' outputs.")
+	rootCmd.Flags().BoolVar(&withWikidata, "wikidata", false, "Also fetch the Wikidata entity of the page: instance of, country, coordinates, inception, official website, dates of birth and death, with labels in the 'lang' language.")
+	rootCmd.PersistentFlags().BoolVarP(&fullOutput, "full", "f", false, "Also print the page namespace, ID, properties and information: display title, length, last revision, protection and watchers.")
+	rootCmd.PersistentFlags().BoolVar(&frontMatter, "front-matter", false, "Prepend a YAML front matter with the page metadata to the 'markdown' output.")
+	rootCmd.PersistentFlags().StringSliceVar(&fields, "fields", nil, fmt.Sprintf("Comma-separated list of fields to output. Overrides 'full'. Valid fields are %v.", validFields))
+	rootCmd.PersistentFlags().BoolVar(&header, "header", true, "Write a header row with the field names in the 'csv' and 'tsv' outputs.")
+	rootCmd.PersistentFlags().IntVar(&wrap, "wrap", 0, "Width the 'plain' output is wrapped at. Not wrapped when 0.")
+	rootCmd.PersistentFlags().IntVar(&width, "width", 0, "Width the 'pretty' output is wrapped at. Defaults to the width of the terminal, or 100.")
+	rootCmd.PersistentFlags().StringVar(&theme, "theme", "auto", fmt.Sprintf("Theme of the 'pretty' output. Accepted values are %v, or the path to a glamour JSON style file. Colors are disabled when NO_COLOR is set.", validThemes))
+	rootCmd.PersistentFlags().StringVar(&templateText, "template", "", "Go template of the 'template' output, ie. '{{.Title}}: {{.ShortDescription}}'. Mutually exclusive with 'template-file'.")
+	rootCmd.PersistentFlags().StringVar(&templateFile, "template-file", "", "File containing the Go template of the 'template' output. Mutually exclusive with 'template'.")
+	rootCmd.PersistentFlags().StringArrayVar(&outputFiles, "output-file", nil, "Also write the page to a file, as '<format>:<path>', ie. 'json:out.json'. The path is a Go template, ie. 'md:notes/{{.Title}}.md'. Can be repeated.")
+	rootCmd.PersistentFlags().StringVar(&overwrite, "overwrite", "never", fmt.Sprintf("Policy when an output file already exists. Accepted values are %v.", validOverwritePolicies))
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "loglevel", "e", "error", fmt.Sprintf("Log level verbosity. Accepted values are %v.", validLogLevels))
 	rootCmd.PersistentFlags().StringVarP(&logFormat, "logformat", "a", "text", fmt.Sprintf("Log format. Accepted values are %v.", validLogFormats))
 	rootCmd.PersistentFlags().BoolVarP(&randomPage, "random", "r", false, "Return a random article.")
 
+	// List the available output formats in the help once it is rendered, rather than now:
+	// the init() functions of the packages registering formats run after this one
+	long := rootCmd.Long
+	help, usage := rootCmd.HelpFunc(), rootCmd.UsageFunc()
+	describeOutputs := func() {
+		rootCmd.Long = long + "\n\nOutput formats:\n" + displayers.usage()
+		rootCmd.PersistentFlags().Lookup("output").Usage = outputUsage()
+	}
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		describeOutputs()
+		help(cmd, args)
+	})
+	rootCmd.SetUsageFunc(func(cmd *cobra.Command) error {
+		describeOutputs()
+		return usage(cmd)
+	})
+
 	cobra.OnInitialize(initConfig, setLogger)
 }
 
+// outputUsage returns the usage of the 'output' flag, with the names of the registered output formats.
+func outputUsage() string {
+	return fmt.Sprintf("Output type. Valid choices are %v, or the name of an external formatter '%s<name>' in $PATH.", displayers.names(), externalFormatterPrefix)
+}
+
 func initConfig() {
 	// Set the API base URL corresponding to the desired language
-	APIBaseURL = fmt.Sprintf("https://%s.wikipedia.org/w/api.php", lang)
+	APIBaseURL = apiBaseURL(lang)
+}
+
+// apiBaseURL returns the base URL of the Wikipedia API for the given language.
+func apiBaseURL(lang string) string {
+	return fmt.Sprintf("https://%s.wikipedia.org/w/api.php", lang)
 }
 
 func setLogger() {
@@ -218,11 +350,93 @@ func setLogger() {
 	}
 }
 
-// validateFlags will determine whether the given value of the 'output', 'loglevel' and 'logformat' flags are valid.
+// validateFlags will determine whether the given value of the 'output', 'fields', 'exsentences', 'section', 'full-article', 'chars', 'words', 'wrap', 'width', 'theme', 'output-file', 'overwrite', 'template', 'loglevel' and 'logformat' flags are valid.
 // It exit the program with an error if not.
 func validateFlags(cmd *cobra.Command, args []string) error {
-	if !isPresent(validOutputs, output) {
-		return fmt.Errorf("error: invalid value for flag 'output'. Valid values are %v", validOutputs)
+	if _, ok := displayers.lookup(output); !ok {
+		return fmt.Errorf("error: invalid value for flag 'output'. Valid values are %v, or the name of an external formatter '%s<name>' in $PATH", displayers.names(), externalFormatterPrefix)
+	}
+
+	return validateCommonFlags(cmd, args)
+}
+
+// validateCommonFlags will determine whether the flags validated by validateFlags are valid, except the 'output' flag.
+// It is used by the commands listing items, whose outputs are validated by validateListFlags.
+func validateCommonFlags(cmd *cobra.Command, args []string) error {
+	for _, f := range fields {
+		if !isPresent(validFields, f) {
+			return fmt.Errorf("error: invalid value for flag 'fields': %q. Valid values are %v", f, validFields)
+		}
+	}
+
+	formats := []string{output}
+	for _, s := range outputFiles {
+		o, err := parseOutputFile(s)
+		if err != nil {
+			return fmt.Errorf("error: invalid value for flag 'output-file': %w", err)
+		}
+		formats = append(formats, o.info.Name)
+	}
+
+	if wrap < 0 {
+		return fmt.Errorf("error: invalid value for flag 'wrap': must be positive")
+	}
+
+	if width < 0 {
+		return fmt.Errorf("error: invalid value for flag 'width': must be positive")
+	}
+
+	if !isPresent(validThemes, theme) {
+		if _, err := os.Stat(theme); err != nil {
+			return fmt.Errorf("error: invalid value for flag 'theme'. Valid values are %v, or the path to a glamour JSON style file: %w", validThemes, err)
+		}
+	}
+
+	if !isPresent(validOverwritePolicies, overwrite) {
+		return fmt.Errorf("error: invalid value for flag 'overwrite'. Valid values are %v", validOverwritePolicies)
+	}
+
+	if isPresent(formats, "template") && templateText == "" && templateFile == "" {
+		return fmt.Errorf("error: flag 'template' or 'template-file' is required with the 'template' output")
+	}
+
+	if templateText != "" && templateFile != "" {
+		return fmt.Errorf("error: flags 'template' and 'template-file' are mutually exclusive")
+	}
+
+	if exsentences < 1 || exsentences > maxExsentences {
+		return fmt.Errorf("error: invalid value for flag 'exsentences': must be between 1 and %d", maxExsentences)
+	}
+
+	if section != "" && cmd.Flag("exsentences").Changed {
+		return fmt.Errorf("error: flags 'section' and 'exsentences' are mutually exclusive")
+	}
+
+	if fullArticle && cmd.Flag("exsentences").Changed {
+		return fmt.Errorf("error: flags 'full-article' and 'exsentences' are mutually exclusive")
+	}
+
+	if fullArticle && exintro && cmd.Flag("exintro").Changed {
+		return fmt.Errorf("error: flags 'full-article' and 'exintro' are mutually exclusive")
+	}
+
+	if exchars < 0 || exchars > maxExchars {
+		return fmt.Errorf("error: invalid value for flag 'chars': must be between 1 and %d", maxExchars)
+	}
+
+	if exchars > 0 {
+		switch {
+		case cmd.Flag("exsentences").Changed:
+			return fmt.Errorf("error: flags 'chars' and 'exsentences' are mutually exclusive")
+		case section != "":
+			return fmt.Errorf("error: flags 'chars' and 'section' are mutually exclusive")
+		case fullArticle:
+			return fmt.Errorf("error: flags 'chars' and 'full-article' are mutually exclusive")
+		}
+	}
+
+	if words < 0 {
+		return fmt.Errorf("error: invalid value for flag 'words': must be positive")
 	}
 
 	if !isPresent(validLogLevels, logLevel) {
@@ -236,6 +450,62 @@ func validateFlags(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// getWikidata will fetch the Wikidata entity of the given page, with the labels in the language of the 'lang' flag.
+// It returns nil without error when the page has no Wikidata item.
+func getWikidata(page *Page) (*Wikidata, error) {
+	if page.PageProps == nil || page.PageProps.WikiBaseItem == "" {
+		logger.Warn("The page has no Wikidata item", slog.String("title", page.Title))
+		return nil, nil
+	}
+
+	w, err := NewWikiClient(WikidataAPIBaseURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Getting Wikidata entity...", slog.String("id", page.PageProps.WikiBaseItem))
+
+	return w.GetWikidata(page.PageProps.WikiBaseItem, lang)
+}
+
+// hasAttribution returns whether the attribution is written by the given output format:
+// the value of the 'attribution' flag when set, the default of the format otherwise.
+func hasAttribution(info DisplayerInfo, set bool) bool {
+	if set {
+		return withAttribution
+	}
+
+	return info.Attribution
+}
+
+// getInfobox will fetch the wikitext of the given page to extract its first infobox.
+// It returns nil if the page has no infobox.
+func getInfobox(w *WikiClient, page *Page) (*Infobox, error) {
+	logger.Info("Getting wikitext...", slog.String("title", page.Title), slog.Int("id", *page.Pageid))
+
+	wikitext, err := w.GetWikitext(uint64(*page.Pageid))
+	if err != nil {
+		return nil, err
+	}
+
+	infobox := parseInfobox(wikitext)
+	if infobox == nil {
+		logger.Warn("The page has no infobox", slog.String("title", page.Title))
+	}
+
+	return infobox, nil
+}
+
+// sentencesLimit returns the number of sentences the extract is limited to once fetched,
+// 0 when only the content before the first section or a number of characters are requested.
+func sentencesLimit() int {
+	if exintro || exchars > 0 {
+		return 0
+	}
+
+	return exsentences
+}
+
 // hasOneArg will verify whether the slice passed in agument contains only one element.
 // It will return true if the slice contains only one element, false otherwise.
 func hasOneArg(args []string) bool {
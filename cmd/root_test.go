@@ -39,3 +39,252 @@ func TestHasOneArg(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateFlags(t *testing.T) {
+	type flags struct {
+		output       string
+		fields       []string
+		templateText string
+		templateFile string
+		outputFiles  []string
+		overwrite    string
+		section      string
+		exsentences  bool
+		sentences    int
+		exintro      bool
+		fullArticle  bool
+		exchars      int
+		words        int
+		wrap         int
+		width        int
+		theme        string
+	}
+	tests := []struct {
+		name    string
+		flags   flags
+		wantErr bool
+	}{
+		{
+			name:    "Plain output",
+			flags:   flags{output: "plain"},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid output",
+			flags:   flags{output: "invalid"},
+			wantErr: true,
+		},
+		{
+			name:    "Valid fields",
+			flags:   flags{output: "csv", fields: []string{"title", "url"}},
+			wantErr: false,
+		},
+		{
+			name:    "Infobox field",
+			flags:   flags{output: "csv", fields: []string{"title", "infobox"}},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid fields",
+			flags:   flags{output: "csv", fields: []string{"title", "invalid"}},
+			wantErr: true,
+		},
+		{
+			name:    "Template output with template",
+			flags:   flags{output: "template", templateText: "{{.Title}}"},
+			wantErr: false,
+		},
+		{
+			name:    "Template output with template file",
+			flags:   flags{output: "template", templateFile: "template.tmpl"},
+			wantErr: false,
+		},
+		{
+			name:    "Template output without template",
+			flags:   flags{output: "template"},
+			wantErr: true,
+		},
+		{
+			name:    "Output files",
+			flags:   flags{output: "pretty", outputFiles: []string{"json:out.json", "md:notes/{{.Title}}.md"}},
+			wantErr: false,
+		},
+		{
+			name:    "Output file with invalid format",
+			flags:   flags{output: "pretty", outputFiles: []string{"invalid:out.txt"}},
+			wantErr: true,
+		},
+		{
+			name:    "Output file without path",
+			flags:   flags{output: "pretty", outputFiles: []string{"json"}},
+			wantErr: true,
+		},
+		{
+			name:    "Template output file without template",
+			flags:   flags{output: "pretty", outputFiles: []string{"template:out.txt"}},
+			wantErr: true,
+		},
+		{
+			name:    "Pretty output with width and theme",
+			flags:   flags{output: "pretty", width: 80, theme: "light"},
+			wantErr: false,
+		},
+		{
+			name:    "Pretty output with style file",
+			flags:   flags{output: "pretty", theme: "root_test.go"},
+			wantErr: false,
+		},
+		{
+			name:    "Section",
+			flags:   flags{output: "plain", section: "History"},
+			wantErr: false,
+		},
+		{
+			name:    "Exsentences",
+			flags:   flags{output: "plain", exsentences: true, sentences: 3},
+			wantErr: false,
+		},
+		{
+			name:    "Exsentences out of range",
+			flags:   flags{output: "plain", exsentences: true, sentences: 11},
+			wantErr: true,
+		},
+		{
+			name:    "Negative exsentences",
+			flags:   flags{output: "plain", exsentences: true, sentences: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Section and exsentences",
+			flags:   flags{output: "plain", section: "History", exsentences: true},
+			wantErr: true,
+		},
+		{
+			name:    "Full article",
+			flags:   flags{output: "plain", fullArticle: true, words: 200},
+			wantErr: false,
+		},
+		{
+			name:    "Full article and exsentences",
+			flags:   flags{output: "plain", fullArticle: true, exsentences: true},
+			wantErr: true,
+		},
+		{
+			name:    "Full article and exintro",
+			flags:   flags{output: "plain", fullArticle: true, exintro: true},
+			wantErr: true,
+		},
+		{
+			name:    "Chars",
+			flags:   flags{output: "plain", exchars: 300},
+			wantErr: false,
+		},
+		{
+			name:    "Chars out of range",
+			flags:   flags{output: "plain", exchars: 1201},
+			wantErr: true,
+		},
+		{
+			name:    "Chars and exsentences",
+			flags:   flags{output: "plain", exchars: 300, exsentences: true},
+			wantErr: true,
+		},
+		{
+			name:    "Chars and section",
+			flags:   flags{output: "plain", exchars: 300, section: "History"},
+			wantErr: true,
+		},
+		{
+			name:    "Chars and full article",
+			flags:   flags{output: "plain", exchars: 300, fullArticle: true},
+			wantErr: true,
+		},
+		{
+			name:    "Negative words",
+			flags:   flags{output: "plain", words: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Negative wrap",
+			flags:   flags{output: "plain", wrap: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Negative width",
+			flags:   flags{output: "pretty", width: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid theme",
+			flags:   flags{output: "pretty", theme: "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid overwrite policy",
+			flags:   flags{output: "plain", overwrite: "sometimes"},
+			wantErr: true,
+		},
+		{
+			name:    "Template output with template and template file",
+			flags:   flags{output: "template", templateText: "{{.Title}}", templateFile: "template.tmpl"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, fields, templateText, templateFile = tt.flags.output, tt.flags.fields, tt.flags.templateText, tt.flags.templateFile
+			outputFiles, overwrite = tt.flags.outputFiles, tt.flags.overwrite
+			if overwrite == "" {
+				overwrite = "never"
+			}
+			wrap, width, theme = tt.flags.wrap, tt.flags.width, tt.flags.theme
+			section = tt.flags.section
+			fullArticle, exchars, words = tt.flags.fullArticle, tt.flags.exchars, tt.flags.words
+			rootCmd.Flag("exsentences").Changed = tt.flags.exsentences
+			exsentences = tt.flags.sentences
+			if exsentences == 0 {
+				exsentences = maxExsentences
+			}
+			rootCmd.Flag("exintro").Changed = tt.flags.exintro
+			if theme == "" {
+				theme = "auto"
+			}
+			logLevel, logFormat = "error", "text"
+			t.Cleanup(func() {
+				output, fields, templateText, templateFile = "plain", nil, "", ""
+				outputFiles, overwrite = nil, "never"
+				wrap, width, theme = 0, 0, "auto"
+				section = ""
+				fullArticle, exchars, words = false, 0, 0
+				rootCmd.Flag("exsentences").Changed = false
+				exsentences = maxExsentences
+				rootCmd.Flag("exintro").Changed = false
+			})
+
+			err := validateFlags(rootCmd, nil)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHasAttribution(t *testing.T) {
+	html, _ := displayers.lookup("html")
+	md, _ := displayers.lookup("md")
+	plain, _ := displayers.lookup("plain")
+
+	withAttribution = false
+	assert.True(t, hasAttribution(html, false))
+	assert.True(t, hasAttribution(md, false))
+	assert.False(t, hasAttribution(plain, false))
+	assert.False(t, hasAttribution(html, true))
+
+	withAttribution = true
+	assert.True(t, hasAttribution(plain, true))
+
+	withAttribution = false
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// wikiHeadingRegexp matches the section headings of an extract
+// requested with 'exsectionformat=wiki', ie. "== History ==".
+var wikiHeadingRegexp = regexp.MustCompile(`(?m)^(={2,6})[ \t]*(.+?)[ \t]*={2,6}[ \t]*$`)
+
+// ErrSectionNotFound is returned when the requested section isn't part of the page
+var ErrSectionNotFound = errors.New("section not found")
+
+// parseSections will look for the wikitext-style section headings in the given extract.
+// It returns the sections in the order they appear in the extract, without their text.
+func parseSections(extract string) []Section {
+	_, sections := splitSections(extract)
+
+	for i := range sections {
+		sections[i].Text = ""
+	}
+
+	return sections
+}
+
+// splitSections will split the given extract at its wikitext-style section headings.
+// It returns the lead, which is the text before the first heading,
+// and the sections with their text in the order they appear in the extract.
+func splitSections(extract string) (string, []Section) {
+	var sections []Section
+
+	matches := wikiHeadingRegexp.FindAllStringSubmatchIndex(extract, -1)
+	if len(matches) == 0 {
+		return strings.TrimSpace(extract), nil
+	}
+
+	anchors := make(map[string]int)
+
+	for i, m := range matches {
+		end := len(extract)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+
+		title := strings.TrimSpace(extract[m[4]:m[5]])
+
+		sections = append(sections, Section{
+			Index:  i + 1,
+			Level:  m[3] - m[2],
+			Title:  title,
+			Anchor: sectionAnchor(title, anchors),
+			Text:   strings.TrimSpace(extract[m[1]:end]),
+		})
+	}
+
+	return strings.TrimSpace(extract[:matches[0][0]]), sections
+}
+
+// sectionAnchor returns the anchor of the section with the given title, as MediaWiki does:
+// the spaces are replaced with underscores, and the duplicated anchors get a numeric suffix, ie. "History_2".
+// seen counts the anchors already given in the page.
+func sectionAnchor(title string, seen map[string]int) string {
+	anchor := strings.ReplaceAll(title, " ", "_")
+
+	seen[anchor]++
+	if n := seen[anchor]; n > 1 {
+		return fmt.Sprintf("%s_%d", anchor, n)
+	}
+
+	return anchor
+}
+
+// selectSection will return the part of the given extract with the requested section and its subsections,
+// its heading included. The section is either its index as listed by parseSections, 0 being the lead,
+// or its title or anchor, case-insensitively.
+// It returns ErrSectionNotFound if the extract has no such section.
+func selectSection(extract, section string) (string, error) {
+	lead, sections := splitSections(extract)
+	matches := wikiHeadingRegexp.FindAllStringIndex(extract, -1)
+
+	target := -1
+	if n, err := strconv.Atoi(section); err == nil {
+		if n == 0 && lead != "" {
+			return lead, nil
+		}
+		target = n - 1
+	} else {
+		for i, s := range sections {
+			if strings.EqualFold(s.Title, section) || strings.EqualFold(s.Anchor, section) {
+				target = i
+				break
+			}
+		}
+	}
+
+	if target < 0 || target >= len(sections) {
+		return "", fmt.Errorf("%w: %q", ErrSectionNotFound, section)
+	}
+
+	// The section ends at the next heading of the same or upper level
+	end := len(extract)
+	for i := target + 1; i < len(sections); i++ {
+		if sections[i].Level <= sections[target].Level {
+			end = matches[i][0]
+			break
+		}
+	}
+
+	return strings.TrimSpace(extract[matches[target][0]:end]), nil
+}
+
+// extractBlock represents a block of an extract: either a paragraph or a section heading.
+type extractBlock struct {
+	// Level is the heading level of a section heading, 0 for a paragraph
+	Level int
+	Text  string
+}
+
+// extractBlocks will split the given extract into blocks:
+// each non-empty line is a paragraph, except the wikitext-style
+// section headings ("== History ==") which are headings.
+func extractBlocks(extract string) []extractBlock {
+	var blocks []extractBlock
+
+	for _, line := range strings.Split(extract, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if m := wikiHeadingRegexp.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, extractBlock{Level: len(m[1]), Text: strings.TrimSpace(m[2])})
+			continue
+		}
+
+		blocks = append(blocks, extractBlock{Text: line})
+	}
+
+	return blocks
+}
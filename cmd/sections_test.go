@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		extract string
+		want    []Section
+	}{
+		{
+			name:    "No section",
+			extract: "Go is a statically typed, compiled programming language.",
+			want:    nil,
+		},
+		{
+			name:    "Nested sections",
+			extract: "Go is a programming language.\n\n\n== History ==\nGo was designed at Google.\n\n\n=== Naming ===\nThe name.\n\n\n== Design ==\nDesign.",
+			want: []Section{
+				{Index: 1, Level: 2, Title: "History", Anchor: "History"},
+				{Index: 2, Level: 3, Title: "Naming", Anchor: "Naming"},
+				{Index: 3, Level: 2, Title: "Design", Anchor: "Design"},
+			},
+		},
+		{
+			name:    "Equal signs inside a paragraph",
+			extract: "a == b is a comparison.\n\n== Syntax ==",
+			want: []Section{
+				{Index: 1, Level: 2, Title: "Syntax", Anchor: "Syntax"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseSections(tt.extract))
+		})
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	tests := []struct {
+		name         string
+		extract      string
+		wantLead     string
+		wantSections []Section
+	}{
+		{
+			name:     "No section",
+			extract:  "Go is a programming language.\nIt is compiled.\n",
+			wantLead: "Go is a programming language.\nIt is compiled.",
+		},
+		{
+			name:     "Nested sections",
+			extract:  "Go is a programming language.\n\n\n== History ==\nGo was designed at Google.\n\n\n=== Naming ===\nThe name.\n\n\n== Design ==\nDesign.\nMore design.",
+			wantLead: "Go is a programming language.",
+			wantSections: []Section{
+				{Index: 1, Level: 2, Title: "History", Anchor: "History", Text: "Go was designed at Google."},
+				{Index: 2, Level: 3, Title: "Naming", Anchor: "Naming", Text: "The name."},
+				{Index: 3, Level: 2, Title: "Design", Anchor: "Design", Text: "Design.\nMore design."},
+			},
+		},
+		{
+			name:     "Duplicated titles and empty section",
+			extract:  "== Early history ==\n\n== History ==\nFirst.\n== History ==\nSecond.",
+			wantLead: "",
+			wantSections: []Section{
+				{Index: 1, Level: 2, Title: "Early history", Anchor: "Early_history", Text: ""},
+				{Index: 2, Level: 2, Title: "History", Anchor: "History", Text: "First."},
+				{Index: 3, Level: 2, Title: "History", Anchor: "History_2", Text: "Second."},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lead, sections := splitSections(tt.extract)
+
+			assert.Equal(t, tt.wantLead, lead)
+			assert.Equal(t, tt.wantSections, sections)
+		})
+	}
+}
+
+func TestSelectSection(t *testing.T) {
+	extract := "Go is a programming language.\n\n\n== History ==\nGo was designed at Google.\n\n\n=== Naming ===\nThe name.\n\n\n== Design and development ==\nDesign."
+
+	tests := []struct {
+		name    string
+		section string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Lead",
+			section: "0",
+			want:    "Go is a programming language.",
+		},
+		{
+			name:    "Index with subsections",
+			section: "1",
+			want:    "== History ==\nGo was designed at Google.\n\n\n=== Naming ===\nThe name.",
+		},
+		{
+			name:    "Index of a subsection",
+			section: "2",
+			want:    "=== Naming ===\nThe name.",
+		},
+		{
+			name:    "Title, case-insensitive",
+			section: "design AND development",
+			want:    "== Design and development ==\nDesign.",
+		},
+		{
+			name:    "Anchor",
+			section: "Design_and_development",
+			want:    "== Design and development ==\nDesign.",
+		},
+		{
+			name:    "Unknown index",
+			section: "4",
+			wantErr: true,
+		},
+		{
+			name:    "Negative index",
+			section: "-1",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown title",
+			section: "Reception",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectSection(extract, tt.section)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrSectionNotFound)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	// The lead of an extract starting with a section doesn't exist
+	_, err := selectSection("== History ==\nHistory.", "0")
+	assert.ErrorIs(t, err, ErrSectionNotFound)
+}
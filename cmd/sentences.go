@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// abbreviations represents, by language, the abbreviations ending with a period
+// which don't end a sentence, lowercased and without their final period.
+// Single letters, ie. the initials in "J. R. R. Tolkien", are always abbreviations.
+var abbreviations = map[string][]string{
+	"en": {
+		"mr", "mrs", "ms", "dr", "prof", "rev", "hon", "st", "jr", "sr", "gen", "col", "lt", "sgt", "capt", "adm", "gov", "sen", "rep",
+		"inc", "ltd", "co", "corp", "bros", "vs", "etc", "e.g", "i.e", "cf", "al", "approx", "ca", "vol", "vols", "pp", "fig", "ed", "eds",
+		"jan", "feb", "mar", "apr", "jun", "jul", "aug", "sep", "sept", "oct", "nov", "dec", "mt", "ft", "u.s", "u.k", "a.d", "b.c",
+	},
+	"de": {
+		"arm", "gen", "ing", "dipl", "dr", "prof", "hr", "fr", "nr", "str", "bzw", "usw", "vgl", "ca", "geb", "gest", "z.b", "d.h", "u.a", "s.o", "u.ä",
+		"evtl", "ggf", "inkl", "jh", "jhd", "mio", "mrd", "bd", "hrsg", "abs", "abb", "tel", "sog", "z.t", "v.a", "n.chr", "v.chr",
+	},
+	"fr": {
+		"m", "mm", "mme", "mmes", "mlle", "mlles", "dr", "pr", "me", "st", "ste", "mgr", "etc", "cf", "av", "bd", "env", "ex", "p", "vol", "éd",
+		"apr. j.-c", "av. j.-c", "j.-c", "n°", "chap", "coll", "fig",
+	},
+	"es": {
+		"sr", "sra", "srta", "dr", "dra", "d", "dña", "prof", "ing", "lic", "etc", "p.ej", "ud", "uds", "av", "avda", "núm", "pág", "vol", "cap", "aprox",
+		"a.c", "d.c", "ee.uu", "s.a",
+	},
+	"it": {
+		"sig", "sigg", "sig.ra", "dott", "dott.ssa", "prof", "ing", "avv", "geom", "arch", "on", "ecc", "es", "pag", "pagg", "vol", "cap", "a.c", "d.c", "s.p.a",
+	},
+	"pt": {
+		"sr", "sra", "srta", "dr", "dra", "prof", "eng", "etc", "ex", "pág", "vol", "cap", "a.c", "d.c", "ltda", "s.a",
+	},
+	"nl": {
+		"dhr", "mevr", "dr", "prof", "ir", "ing", "mr", "drs", "bijv", "enz", "etc", "o.a", "m.a.w", "d.w.z", "z.g.a.n", "n.chr", "v.chr", "blz", "nr",
+	},
+}
+
+// titleAbbreviations represents the abbreviations of the military and academic titles shared by several languages,
+// ie. "Arm. gen. Ing. John Smith", which don't end a sentence in any language.
+var titleAbbreviations = []string{
+	"arm", "gen", "ing", "dipl", "dr", "prof", "col", "lt", "maj", "capt", "sgt", "adm", "brig", "cpt",
+}
+
+// sentenceTerminals represents the punctuation ending a sentence when followed by a space.
+const sentenceTerminals = ".!?…"
+
+// cjkSentenceTerminals represents the full-width punctuation ending a sentence,
+// not followed by a space in Chinese and Japanese.
+const cjkSentenceTerminals = "。！？．｡"
+
+// sentenceClosers represents the closing quotes and brackets which can follow the end of a sentence.
+const sentenceClosers = `"')]»”’」』）`
+
+// abbreviationSet returns the abbreviations of the given language, ie. "de" for "de" or "de-ch",
+// with the titleAbbreviations.
+func abbreviationSet(lang string) map[string]bool {
+	lang, _, _ = strings.Cut(strings.ToLower(lang), "-")
+
+	set := make(map[string]bool, len(abbreviations[lang])+len(titleAbbreviations))
+	for _, a := range slices.Concat(abbreviations[lang], titleAbbreviations) {
+		set[a] = true
+	}
+
+	return set
+}
+
+// splitSentences will split s into the sentences of the given language.
+// A sentence ends with a terminal punctuation followed by a space, ie. not in "1.25",
+// or with a full-width punctuation ("。"). A period doesn't end a sentence after an abbreviation
+// of the language ("Dr."), an initial ("J.") or when the next word is lowercase.
+func splitSentences(lang, s string) []string {
+	abbrs := abbreviationSet(lang)
+	r := []rune(s)
+
+	var sentences []string
+	add := func(start, end int) {
+		if sentence := strings.TrimSpace(string(r[start:end])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+
+	start := 0
+	for i := 0; i < len(r); i++ {
+		cjk := strings.ContainsRune(cjkSentenceTerminals, r[i])
+		if !cjk && !strings.ContainsRune(sentenceTerminals, r[i]) {
+			continue
+		}
+
+		// The sentence ends after the punctuation and the closing quotes or brackets
+		end := i + 1
+		for end < len(r) && (strings.ContainsRune(sentenceTerminals, r[end]) || strings.ContainsRune(cjkSentenceTerminals, r[end])) {
+			end++
+		}
+		for end < len(r) && strings.ContainsRune(sentenceClosers, r[end]) {
+			end++
+		}
+
+		next := end
+		for next < len(r) && unicode.IsSpace(r[next]) {
+			next++
+		}
+
+		if !cjk {
+			// Not followed by a space, ie. a decimal number or a domain name
+			if end < len(r) && next == end {
+				i = end - 1
+				continue
+			}
+
+			// A single period after an abbreviation or before a lowercase word
+			if r[i] == '.' && end-i == 1 && (isAbbreviation(abbrs, r[start:i]) || (next < len(r) && unicode.IsLower(r[next]))) {
+				i = end - 1
+				continue
+			}
+		}
+
+		add(start, end)
+		start = next
+		i = next - 1
+	}
+	add(start, len(r))
+
+	return sentences
+}
+
+// isAbbreviation will verify whether the last word of text is an abbreviation or an initial.
+func isAbbreviation(abbrs map[string]bool, text []rune) bool {
+	begin := len(text)
+	for begin > 0 && !unicode.IsSpace(text[begin-1]) {
+		begin--
+	}
+
+	word := strings.TrimLeft(string(text[begin:]), `"'([«“‘`)
+	if word == "" {
+		return false
+	}
+
+	if w := []rune(word); len(w) == 1 && unicode.IsLetter(w[0]) {
+		return true
+	}
+
+	// Some abbreviations contain spaces, ie. "av. J.-C." in French
+	lower := strings.ToLower(string(text))
+	for a := range abbrs {
+		if strings.HasSuffix(lower, a) && (len(lower) == len(a) || !isLetterBefore(lower, len(lower)-len(a))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isLetterBefore will verify whether the character of s before the byte offset i is a letter.
+func isLetterBefore(s string, i int) bool {
+	r := []rune(s[:i])
+	return len(r) > 0 && unicode.IsLetter(r[len(r)-1])
+}
+
+// limitSentences will keep the sentences of the extract s, in the given language, as long as keep returns true.
+// The paragraphs kept entirely and the section headings are unchanged.
+// The section headings are dropped when nothing follows them.
+// It returns the extract and whether a sentence has been left out.
+func limitSentences(lang, s string, keep func(sentence string) bool) (string, bool) {
+	lines := strings.Split(s, "\n")
+	kept := make([]string, 0, len(lines))
+	truncated := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" || wikiHeadingRegexp.MatchString(line) {
+			kept = append(kept, line)
+			continue
+		}
+
+		sentences := splitSentences(lang, line)
+		n := 0
+		for n < len(sentences) && keep(sentences[n]) {
+			n++
+		}
+
+		if n == len(sentences) {
+			kept = append(kept, line)
+			continue
+		}
+
+		if n > 0 {
+			kept = append(kept, strings.Join(sentences[:n], " "))
+		}
+		truncated = true
+		break
+	}
+
+	// Drop the trailing headings without text
+	for len(kept) > 0 {
+		last := kept[len(kept)-1]
+		if strings.TrimSpace(last) != "" && !wikiHeadingRegexp.MatchString(last) {
+			break
+		}
+		kept = kept[:len(kept)-1]
+	}
+
+	return strings.Join(kept, "\n"), truncated
+}
+
+// firstSentences will return the first n sentences of the extract s in the given language.
+// The section headings aren't counted.
+func firstSentences(lang string, n int, s string) string {
+	if n <= 0 {
+		return s
+	}
+
+	count := 0
+	limited, _ := limitSentences(lang, s, func(string) bool {
+		count++
+		return count <= n
+	})
+
+	return limited
+}
+
+// limitWords will shorten the extract s to at most n words, ending at the end of a sentence in the given language.
+// The section headings aren't counted and are dropped when nothing follows them.
+// When even the first sentence is longer than n words, it is cut after n words and ends with an ellipsis.
+func limitWords(lang string, n int, s string) string {
+	if n <= 0 {
+		return s
+	}
+
+	count := 0
+	limited, truncated := limitSentences(lang, s, func(sentence string) bool {
+		w := len(strings.Fields(sentence))
+		if count+w > n {
+			return false
+		}
+		count += w
+		return true
+	})
+
+	if truncated && count == 0 {
+		for _, line := range strings.Split(s, "\n") {
+			if strings.TrimSpace(line) == "" || wikiHeadingRegexp.MatchString(line) {
+				continue
+			}
+			return strings.Join(strings.Fields(line)[:n], " ") + "…"
+		}
+	}
+
+	return limited
+}
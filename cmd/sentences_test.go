@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSentences(t *testing.T) {
+	type args struct {
+		lang string
+		s    string
+	}
+	tests := []struct {
+		desc string
+		args args
+		want []string
+	}{
+		{
+			desc: "Terminal punctuations",
+			args: args{lang: "en", s: "Go is a language. Is it compiled? Yes! It is."},
+			want: []string{"Go is a language.", "Is it compiled?", "Yes!", "It is."},
+		},
+		{
+			desc: "Decimal number",
+			args: args{lang: "en", s: "Go 1.25 was released. It is fast."},
+			want: []string{"Go 1.25 was released.", "It is fast."},
+		},
+		{
+			desc: "English abbreviations and initials",
+			args: args{lang: "en", s: "Dr. Smith met J. R. R. Tolkien in the U.S. in 1950. They talked."},
+			want: []string{"Dr. Smith met J. R. R. Tolkien in the U.S. in 1950.", "They talked."},
+		},
+		{
+			desc: "Military and academic titles",
+			args: args{lang: "en", s: "Arm. gen. Ing. John Smith was a soldier. He lived in Vienna."},
+			want: []string{"Arm. gen. Ing. John Smith was a soldier.", "He lived in Vienna."},
+		},
+		{
+			desc: "Military and academic titles in a language without abbreviations",
+			args: args{lang: "sv", s: "Prof. Smith bodde i Wien. Han var soldat."},
+			want: []string{"Prof. Smith bodde i Wien.", "Han var soldat."},
+		},
+		{
+			desc: "German abbreviations",
+			args: args{lang: "de", s: "Arm. gen. Ing. John Smith war ein Soldat. Er lebte z.B. in Wien."},
+			want: []string{"Arm. gen. Ing. John Smith war ein Soldat.", "Er lebte z.B. in Wien."},
+		},
+		{
+			desc: "Abbreviations of another language",
+			args: args{lang: "en", s: "Er lebte in Wien, Hr. Smith auch."},
+			want: []string{"Er lebte in Wien, Hr.", "Smith auch."},
+		},
+		{
+			desc: "Regional variant",
+			args: args{lang: "de-ch", s: "Er wohnt in Zürich, vgl. Abb. 5. Sie nicht."},
+			want: []string{"Er wohnt in Zürich, vgl. Abb. 5.", "Sie nicht."},
+		},
+		{
+			desc: "French abbreviation with a space",
+			args: args{lang: "fr", s: "César est né en 100 av. J.-C. à Rome. Il est mort."},
+			want: []string{"César est né en 100 av. J.-C. à Rome.", "Il est mort."},
+		},
+		{
+			desc: "Lowercase next word",
+			args: args{lang: "it", s: "Lavora con Java, Go ecc. e vive a Roma. Parla italiano."},
+			want: []string{"Lavora con Java, Go ecc. e vive a Roma.", "Parla italiano."},
+		},
+		{
+			desc: "Closing quotes and brackets",
+			args: args{lang: "en", s: `He said "It is fast." (It is.) Then he left.`},
+			want: []string{`He said "It is fast."`, "(It is.)", "Then he left."},
+		},
+		{
+			desc: "Chinese full stops",
+			args: args{lang: "zh", s: "Go是一种编程语言。它由谷歌开发！它快吗？是的。"},
+			want: []string{"Go是一种编程语言。", "它由谷歌开发！", "它快吗？", "是的。"},
+		},
+		{
+			desc: "Japanese full stops",
+			args: args{lang: "ja", s: "Goはプログラミング言語である。「速い」と言われる。"},
+			want: []string{"Goはプログラミング言語である。", "「速い」と言われる。"},
+		},
+		{
+			desc: "Without terminal punctuation",
+			args: args{lang: "en", s: "Go is a language"},
+			want: []string{"Go is a language"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitSentences(tt.args.lang, tt.args.s))
+		})
+	}
+}
+
+func TestFirstSentences(t *testing.T) {
+	type args struct {
+		n int
+		s string
+	}
+	tests := []struct {
+		desc string
+		args args
+		want string
+	}{
+		{
+			desc: "Less sentences than n",
+			args: args{n: 3, s: "Go is a language. It is compiled."},
+			want: "Go is a language. It is compiled.",
+		},
+		{
+			desc: "More sentences than n",
+			args: args{n: 2, s: "Go is a language. Is it compiled? Yes!\nIt is."},
+			want: "Go is a language. Is it compiled?",
+		},
+		{
+			desc: "Decimal number",
+			args: args{n: 1, s: "Go 1.25 was released. It is fast."},
+			want: "Go 1.25 was released.",
+		},
+		{
+			desc: "Across sections",
+			args: args{n: 2, s: "Go is a language.\n\n\n== History ==\nIt was designed at Google. It is fast."},
+			want: "Go is a language.\n\n\n== History ==\nIt was designed at Google.",
+		},
+		{
+			desc: "Trailing heading dropped",
+			args: args{n: 1, s: "Go is a language.\n\n\n== History ==\nIt was designed at Google."},
+			want: "Go is a language.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, firstSentences("en", tt.args.n, tt.args.s))
+		})
+	}
+}
+
+func TestLimitWords(t *testing.T) {
+	type args struct {
+		n int
+		s string
+	}
+	tests := []struct {
+		desc string
+		args args
+		want string
+	}{
+		{
+			desc: "Not limited",
+			args: args{n: 0, s: "Go is a language. It is compiled."},
+			want: "Go is a language. It is compiled.",
+		},
+		{
+			desc: "Less words than n",
+			args: args{n: 10, s: "Go is a language.\n\n== History ==\nIt was designed at Google."},
+			want: "Go is a language.\n\n== History ==\nIt was designed at Google.",
+		},
+		{
+			desc: "Sentence boundary",
+			args: args{n: 6, s: "Go is a language. It is compiled. It is fast."},
+			want: "Go is a language.",
+		},
+		{
+			desc: "Abbreviation",
+			args: args{n: 8, s: "Go was designed by Dr. Pike. It is compiled."},
+			want: "Go was designed by Dr. Pike.",
+		},
+		{
+			desc: "Paragraph boundary",
+			args: args{n: 6, s: "Go is a language.\n\nIt was designed at Google."},
+			want: "Go is a language.",
+		},
+		{
+			desc: "Headings not counted",
+			args: args{n: 8, s: "Go is a language.\n\n== History ==\nIt was designed. It is fast."},
+			want: "Go is a language.\n\n== History ==\nIt was designed.",
+		},
+		{
+			desc: "Trailing heading dropped",
+			args: args{n: 5, s: "Go is a language.\n\n== History ==\nIt was designed at Google."},
+			want: "Go is a language.",
+		},
+		{
+			desc: "First sentence too long",
+			args: args{n: 3, s: "Go is a statically typed language."},
+			want: "Go is a…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, limitWords("en", tt.args.n, tt.args.s))
+		})
+	}
+}
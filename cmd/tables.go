@@ -0,0 +1,451 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/html"
+)
+
+// maxTableSpan is the maximum value of the 'rowspan' and 'colspan' attributes of a cell,
+// as browsers do for the colspan attribute
+const maxTableSpan = 1000
+
+var (
+	// tableIndex is the index of the table to export, starting at 1. The tables are listed when 0.
+	tableIndex int
+
+	// footnoteRegexp matches the footnote markers left in the text of the cells, ie. "[1]", "[a]" or "[note 2]"
+	footnoteRegexp = regexp.MustCompile(`\[(?:\d+|[a-z]|[A-Z]|(?:note|nb|n) ?\d+|citation needed)\]`)
+
+	// tablesCmd represents the 'tables' command
+	tablesCmd = &cobra.Command{
+		Use:   "tables <title>",
+		Short: "List or export the tables of an article",
+		Long: `List the data tables ("wikitable") of the Wikipedia article best matching the given title:
+their index, caption, columns and number of rows.
+
+A table can then be exported with the '--table' flag, by index, ie.
+  wpdia-go tables "List of countries by population"
+  wpdia-go tables --table 1 --output csv "List of countries by population"
+
+The cells spanning several rows or columns are repeated in each of them,
+the header rows are merged into the column names and the footnote markers are removed.`,
+
+		PreRunE: validateTablesFlags,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			w, err := NewWikiClient(APIBaseURL, "")
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+
+			p := searchPage(w, args[0])
+
+			logger.Info("Getting HTML...", slog.Uint64("pageid", p.Pageid))
+
+			text, err := w.GetHTML(p.Pageid)
+			if err != nil {
+				logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.Uint64("pageid", p.Pageid))
+				os.Exit(1)
+			}
+
+			tables, err := parseTables(text)
+			if err != nil {
+				logger.Error(err.Error(), slog.Uint64("pageid", p.Pageid))
+				os.Exit(1)
+			}
+
+			if tableIndex == 0 {
+				err = writeTables(os.Stdout, p.Title, tables, output)
+			} else {
+				if tableIndex > len(tables) {
+					logger.Error(fmt.Sprintf("Error: the article has %d tables", len(tables)), slog.Int("table", tableIndex))
+					os.Exit(1)
+				}
+				err = writeTable(os.Stdout, p.Title, tables[tableIndex-1], output, header)
+			}
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+// Table represents a data table of an article.
+type Table struct {
+	// Index is the index of the table in the article, starting at 1
+	Index   int    `json:"index" yaml:"index"`
+	Caption string `json:"caption,omitempty" yaml:"caption,omitempty"`
+
+	// Columns are the names of the columns, from the header rows of the table.
+	// It is empty when the table has no header row.
+	Columns []string   `json:"columns" yaml:"columns"`
+	Rows    [][]string `json:"rows" yaml:"rows"`
+}
+
+// tableSummary represents a table in the list of the tables of an article
+type tableSummary struct {
+	Index   int      `json:"index" yaml:"index"`
+	Caption string   `json:"caption,omitempty" yaml:"caption,omitempty"`
+	Columns []string `json:"columns" yaml:"columns"`
+	Rows    int      `json:"rows" yaml:"rows"`
+}
+
+// tableCell represents a cell of a table, once the row and column spans are expanded
+type tableCell struct {
+	text   string
+	header bool
+}
+
+func init() {
+	tablesCmd.Flags().IntVar(&tableIndex, "table", 0, "Index of the table to export, as listed by the command. Required by the 'csv' and 'tsv' outputs.")
+
+	rootCmd.AddCommand(tablesCmd)
+}
+
+// validateTablesFlags will determine whether the flags of the 'tables' command are valid.
+// The csv and tsv outputs are only supported to export a table.
+func validateTablesFlags(cmd *cobra.Command, args []string) error {
+	if tableIndex < 0 {
+		return fmt.Errorf("error: invalid value for flag 'table': %d. Must be positive", tableIndex)
+	}
+	if tableIndex == 0 && (output == "csv" || output == "tsv") {
+		return fmt.Errorf("error: the %q output requires the 'table' flag", output)
+	}
+
+	return validateListFlags(cmd, args)
+}
+
+// parseTables will parse the given HTML of an article and return its data tables,
+// ie. the tables with the "wikitable" class. The tables nested in another one are ignored.
+func parseTables(text string) ([]Table, error) {
+	doc, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the HTML of the page: %w", err)
+	}
+
+	var tables []Table
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "table" {
+			if hasClass(n, "wikitable") {
+				t := parseTable(n)
+				t.Index = len(tables) + 1
+				tables = append(tables, t)
+			}
+			return
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(doc)
+
+	return tables, nil
+}
+
+// parseTable returns the table of the given <table> node.
+// The rows are expanded on a grid: the cells spanning several rows or columns are repeated in each of them.
+// The leading rows made only of header cells are the header rows, merged into the column names.
+func parseTable(n *html.Node) Table {
+	var t Table
+	var rows []*html.Node
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+
+		switch c.Data {
+		case "caption":
+			t.Caption = cellText(c)
+		case "tr":
+			rows = append(rows, c)
+		case "thead", "tbody", "tfoot":
+			for r := c.FirstChild; r != nil; r = r.NextSibling {
+				if r.Type == html.ElementNode && r.Data == "tr" {
+					rows = append(rows, r)
+				}
+			}
+		}
+	}
+
+	grid := tableGrid(rows)
+
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+
+	headers := 0
+	for _, row := range grid {
+		if !slices.ContainsFunc(row, func(c tableCell) bool { return !c.header }) && headers < len(grid)-1 {
+			headers++
+			continue
+		}
+		break
+	}
+
+	if headers > 0 {
+		t.Columns = make([]string, width)
+		for i := range width {
+			var names []string
+			for _, row := range grid[:headers] {
+				if i >= len(row) || row[i].text == "" {
+					continue
+				}
+				// A header cell spanning several header rows is named once
+				if len(names) == 0 || names[len(names)-1] != row[i].text {
+					names = append(names, row[i].text)
+				}
+			}
+			t.Columns[i] = strings.Join(names, " / ")
+		}
+	}
+
+	for _, row := range grid[headers:] {
+		if !slices.ContainsFunc(row, func(c tableCell) bool { return c.text != "" }) {
+			continue
+		}
+
+		record := make([]string, width)
+		for i, c := range row {
+			record[i] = c.text
+		}
+		t.Rows = append(t.Rows, record)
+	}
+
+	return t
+}
+
+// tableGrid returns the cells of the given <tr> nodes, the cells spanning several rows
+// or columns being repeated in each of them.
+func tableGrid(rows []*html.Node) [][]tableCell {
+	type span struct {
+		cell tableCell
+		left int
+	}
+
+	// spans are the cells spanning the next rows, by column
+	spans := map[int]span{}
+
+	grid := make([][]tableCell, 0, len(rows))
+	for _, tr := range rows {
+		var row []tableCell
+
+		// fill adds the cells spanning from the previous rows, up to the given column
+		fill := func(until int) {
+			for col := len(row); ; col++ {
+				s, ok := spans[col]
+				if !ok && col >= until {
+					return
+				}
+
+				if ok {
+					row = append(row, s.cell)
+					if s.left--; s.left == 0 {
+						delete(spans, col)
+					} else {
+						spans[col] = s
+					}
+				} else {
+					row = append(row, tableCell{})
+				}
+			}
+		}
+
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+				continue
+			}
+
+			fill(len(row))
+
+			cell := tableCell{text: cellText(c), header: c.Data == "th"}
+			rowspan := spanAttr(c, "rowspan")
+			for range spanAttr(c, "colspan") {
+				if rowspan > 1 {
+					spans[len(row)] = span{cell: cell, left: rowspan - 1}
+				}
+				row = append(row, cell)
+			}
+		}
+
+		// The cells spanning from the previous rows after the last cell of the row
+		last := -1
+		for col := range spans {
+			last = max(last, col)
+		}
+		fill(last + 1)
+
+		grid = append(grid, row)
+	}
+
+	return grid
+}
+
+// spanAttr returns the value of the given span attribute ("rowspan" or "colspan") of the cell,
+// 1 when it is missing or invalid.
+func spanAttr(n *html.Node, name string) int {
+	v, err := strconv.Atoi(strings.TrimSpace(attr(n, name)))
+	if err != nil || v < 1 {
+		return 1
+	}
+
+	return min(v, maxTableSpan)
+}
+
+// cellText returns the text of the given node, without the footnotes, the hidden elements
+// (ie. the sort keys) and the nested tables. The white spaces are collapsed.
+func cellText(n *html.Node) string {
+	var b strings.Builder
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if isHiddenElement(n) {
+				return
+			}
+
+			switch n.Data {
+			case "br":
+				b.WriteString(" ")
+				return
+			case "p", "div", "li":
+				b.WriteString(" ")
+				defer b.WriteString(" ")
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(n)
+
+	text := footnoteRegexp.ReplaceAllString(b.String(), "")
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// isHiddenElement returns whether the given element isn't part of the text of a cell:
+// the footnotes, the elements not displayed or not printed, the styles and the nested tables.
+func isHiddenElement(n *html.Node) bool {
+	switch n.Data {
+	case "style", "script", "table":
+		return true
+	}
+
+	for _, class := range []string{"reference", "noprint", "sortkey", "mw-editsection"} {
+		if hasClass(n, class) {
+			return true
+		}
+	}
+
+	style := strings.ReplaceAll(attr(n, "style"), " ", "")
+	return strings.Contains(style, "display:none")
+}
+
+// attr returns the value of the given attribute of the node, or an empty string when missing.
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// hasClass returns whether the node has the given class.
+func hasClass(n *html.Node, class string) bool {
+	return slices.Contains(strings.Fields(attr(n, "class")), class)
+}
+
+// writeTables will write the list of the given tables of the article with the given title to w
+// in the given output format: their index, caption, columns and number of rows.
+func writeTables(w io.Writer, title string, tables []Table, format string) error {
+	var summaries []tableSummary
+	for _, t := range tables {
+		summaries = append(summaries, tableSummary{Index: t.Index, Caption: t.Caption, Columns: t.Columns, Rows: len(t.Rows)})
+	}
+
+	t := Table{Columns: []string{"index", "caption", "columns", "rows"}}
+	for _, s := range summaries {
+		t.Rows = append(t.Rows, []string{strconv.Itoa(s.Index), s.Caption, strings.Join(s.Columns, ", "), strconv.Itoa(s.Rows)})
+	}
+
+	return writeList(w, List{
+		Title: title + ": tables",
+		Value: summaries,
+		Table: t,
+		Plain: func(w io.Writer) error {
+			return writeTablesPlain(w, summaries)
+		},
+	}, format, false)
+}
+
+// writeTablesPlain will write the given tables to w, with their index, caption and number of rows
+// followed by their columns.
+func writeTablesPlain(w io.Writer, summaries []tableSummary) error {
+	if len(summaries) == 0 {
+		_, err := fmt.Fprintln(w, "The article has no table.")
+		return err
+	}
+
+	indexWidth := len(fmt.Sprint(len(summaries)))
+	for _, s := range summaries {
+		caption := s.Caption
+		if caption == "" {
+			caption = "(no caption)"
+		}
+
+		_, err := fmt.Fprintf(w, "%*d  %s (%d rows)\n", indexWidth, s.Index, caption, s.Rows)
+		if err != nil {
+			return err
+		}
+
+		if len(s.Columns) > 0 {
+			_, err = fmt.Fprintf(w, "%*s  Columns: %s\n", indexWidth, "", strings.Join(s.Columns, ", "))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeTable will write the given table of the article with the given title to w in the given output format.
+// The csv and tsv outputs start with a row of the column names when header is true and the table has some.
+// The plain output is the table with its columns aligned, the markdown output a markdown table.
+func writeTable(w io.Writer, title string, t Table, format string, header bool) error {
+	if t.Rows == nil {
+		t.Rows = [][]string{}
+	}
+
+	name := t.Caption
+	if name == "" {
+		name = fmt.Sprintf("table %d", t.Index)
+	}
+
+	return writeList(w, List{Title: title + ": " + name, Value: t, Table: t}, format, header)
+}
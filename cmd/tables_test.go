@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestParseTables(t *testing.T) {
+	text, err := os.ReadFile(filepath.Join("testdata", "tables.html"))
+	assert.NoError(t, err)
+
+	got, err := parseTables(string(text))
+	assert.NoError(t, err)
+	assert.Equal(t, []Table{
+		{
+			Index:   1,
+			Caption: "Sovereign states and dependencies by population",
+			Columns: []string{"Location", "Population / Number", "Population / % of world", "Date", "Notes"},
+			Rows: [][]string{
+				{"China", "1,409,670,000", "17.3%", "31 Dec 2023", "National annual estimate"},
+				{"India", "1,404,910,000", "17.3%", "1 Mar 2024", "National annual estimate"},
+				{"United States", "335,893,238", "4.12%", "1 Jan 2024 Monthly national estimate", "1 Jan 2024 Monthly national estimate"},
+				{"Tuvalu", "10,643", "0%", "2022", "Census Estimate"},
+				{"World", "8,119,000,000", "100%", "1 Jul 2024", "UN projection"},
+			},
+		},
+		{
+			Index: 2,
+			Rows: [][]string{
+				{"1950", "2,499,322,157"},
+				{"2000", "6,149,006,956"},
+			},
+		},
+	}, got)
+
+	got, err = parseTables("<p>No table</p>")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParseTableSpans(t *testing.T) {
+	tests := []struct {
+		name        string
+		table       string
+		wantColumns []string
+		wantRows    [][]string
+	}{
+		{
+			name:     "Rowspan in the last column",
+			table:    `<tr><td>a</td><td rowspan="3">b</td></tr><tr><td>c</td></tr><tr><td>d</td></tr>`,
+			wantRows: [][]string{{"a", "b"}, {"c", "b"}, {"d", "b"}},
+		},
+		{
+			name:     "Rowspan and colspan",
+			table:    `<tr><td rowspan="2" colspan="2">a</td><td>b</td></tr><tr><td>c</td></tr>`,
+			wantRows: [][]string{{"a", "a", "b"}, {"a", "a", "c"}},
+		},
+		{
+			name:     "Missing cells",
+			table:    `<tr><td>a</td><td>b</td><td>c</td></tr><tr><td>d</td></tr>`,
+			wantRows: [][]string{{"a", "b", "c"}, {"d", "", ""}},
+		},
+		{
+			name:     "Invalid spans",
+			table:    `<tr><td rowspan="0">a</td><td colspan="x">b</td></tr><tr><td>c</td></tr>`,
+			wantRows: [][]string{{"a", "b"}, {"c", ""}},
+		},
+		{
+			name:        "Header cell spanning the header rows",
+			table:       `<tr><th rowspan="2">Name</th><th colspan="2">Size</th></tr><tr><th>Width</th><th></th></tr><tr><td>a</td><td>1</td><td>2</td></tr>`,
+			wantColumns: []string{"Name", "Size / Width", "Size"},
+			wantRows:    [][]string{{"a", "1", "2"}},
+		},
+		{
+			name:        "Header cells in the rows",
+			table:       `<tr><th>Name</th><th>Size</th></tr><tr><th>a</th><td>1</td></tr>`,
+			wantColumns: []string{"Name", "Size"},
+			wantRows:    [][]string{{"a", "1"}},
+		},
+		{
+			name:     "Only header cells",
+			table:    `<tr><th>Name</th></tr>`,
+			wantRows: [][]string{{"Name"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTables(`<table class="wikitable">` + tt.table + `</table>`)
+			assert.NoError(t, err)
+			assert.Len(t, got, 1)
+			assert.Equal(t, tt.wantColumns, got[0].Columns)
+			assert.Equal(t, tt.wantRows, got[0].Rows)
+		})
+	}
+}
+
+func TestCellText(t *testing.T) {
+	tests := []struct {
+		name string
+		cell string
+		want string
+	}{
+		{name: "Text", cell: " Lyon\n", want: "Lyon"},
+		{name: "Links", cell: `<a href="/wiki/Lyon">Lyon</a>, <a href="/wiki/France">France</a>`, want: "Lyon, France"},
+		{name: "References", cell: `513,275<sup class="reference"><a href="#cite_note-1">[1]</a></sup>`, want: "513,275"},
+		{name: "Footnote markers", cell: `513,275[a][note 2]`, want: "513,275"},
+		{name: "Sort keys", cell: `<span class="sortkey">Lyon !</span><span style="display: none">0</span>Lyon`, want: "Lyon"},
+		{name: "Line breaks", cell: `Lyon<br>France`, want: "Lyon France"},
+		{name: "Lists", cell: `<ul><li>Lyon</li><li>Paris</li></ul>`, want: "Lyon Paris"},
+		{name: "Entities", cell: `1&#160;km &amp; 2&nbsp;km`, want: "1 km & 2 km"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader("<p>" + tt.cell + "</p>"))
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.want, cellText(doc))
+		})
+	}
+}
+
+func TestWriteTables(t *testing.T) {
+	tables := []Table{
+		{Index: 1, Caption: "Cities", Columns: []string{"Name", "Population"}, Rows: [][]string{{"Lyon", "522,250"}}},
+		{Index: 2, Rows: [][]string{{"a", "b"}, {"c", "d"}}},
+	}
+
+	tests := []struct {
+		name   string
+		tables []Table
+		format string
+		want   string
+	}{
+		{
+			name:   "Plain",
+			tables: tables,
+			format: "plain",
+			want:   "1  Cities (1 rows)\n   Columns: Name, Population\n2  (no caption) (2 rows)\n",
+		},
+		{
+			name:   "Plain without table",
+			tables: nil,
+			format: "plain",
+			want:   "The article has no table.\n",
+		},
+		{
+			name:   "JSON",
+			tables: tables[:1],
+			format: "json",
+			want:   "[\n    {\n        \"index\": 1,\n        \"caption\": \"Cities\",\n        \"columns\": [\n            \"Name\",\n            \"Population\"\n        ],\n        \"rows\": 1\n    }\n]\n",
+		},
+		{
+			name:   "JSON without table",
+			tables: nil,
+			format: "json",
+			want:   "[]\n",
+		},
+		{
+			name:   "YAML",
+			tables: tables[1:],
+			format: "yaml",
+			want:   "- index: 2\n  columns: []\n  rows: 2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writeTables(w, "Lyon", tt.tables, tt.format)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	table := Table{
+		Index:   1,
+		Caption: "Cities",
+		Columns: []string{"Name", "Population", "Notes"},
+		Rows:    [][]string{{"Lyon", "522,250", "Prefecture\tof the region"}, {"Orléans", "116,617", ""}},
+	}
+
+	tests := []struct {
+		name   string
+		table  Table
+		format string
+		header bool
+		want   string
+	}{
+		{
+			name:   "Plain",
+			table:  table,
+			format: "plain",
+			want:   "Cities\n\nName     Population  Notes\nLyon     522,250     Prefecture\tof the region\nOrléans  116,617\n",
+		},
+		{
+			name:   "CSV",
+			table:  table,
+			format: "csv",
+			header: true,
+			want:   "Name,Population,Notes\nLyon,\"522,250\",Prefecture\tof the region\nOrléans,\"116,617\",\n",
+		},
+		{
+			name:   "CSV without header",
+			table:  table,
+			format: "csv",
+			want:   "Lyon,\"522,250\",Prefecture\tof the region\nOrléans,\"116,617\",\n",
+		},
+		{
+			name:   "TSV",
+			table:  table,
+			format: "tsv",
+			header: true,
+			want:   "Name\tPopulation\tNotes\nLyon\t522,250\tPrefecture\\tof the region\nOrléans\t116,617\t\n",
+		},
+		{
+			name:   "JSON",
+			table:  Table{Index: 2, Columns: []string{"Name"}, Rows: [][]string{{"Lyon"}}},
+			format: "json",
+			want:   "{\n    \"index\": 2,\n    \"columns\": [\n        \"Name\"\n    ],\n    \"rows\": [\n        [\n            \"Lyon\"\n        ]\n    ]\n}\n",
+		},
+		{
+			name:   "JSON without row",
+			table:  Table{Index: 2, Columns: []string{"Name"}},
+			format: "json",
+			want:   "{\n    \"index\": 2,\n    \"columns\": [\n        \"Name\"\n    ],\n    \"rows\": []\n}\n",
+		},
+		{
+			name:   "YAML",
+			table:  Table{Index: 2, Caption: "Cities", Columns: []string{"Name"}, Rows: [][]string{{"Lyon"}}},
+			format: "yaml",
+			want:   "index: 2\ncaption: Cities\ncolumns:\n- Name\nrows:\n- - Lyon\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writeTable(w, "Lyon", tt.table, tt.format, tt.header)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+}
+
+func TestValidateTablesFlags(t *testing.T) {
+	logLevel, logFormat = "error", "text"
+	t.Cleanup(func() {
+		output, templateText, tableIndex = "plain", "", 0
+	})
+
+	tableIndex = 1
+	templateText = "{{.}}"
+	for _, o := range listOutputs {
+		output = o
+		assert.NoError(t, validateTablesFlags(tablesCmd, nil), o)
+	}
+
+	tableIndex = 0
+	output = "csv"
+	assert.Error(t, validateTablesFlags(tablesCmd, nil))
+
+	output = "dot"
+	assert.Error(t, validateTablesFlags(tablesCmd, nil))
+
+	output = "plain"
+	tableIndex = -1
+	assert.Error(t, validateTablesFlags(tablesCmd, nil))
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs returns the helper functions available in the user-defined templates,
+// splitting the sentences in the given language.
+// The value is the last argument of each function so they can be used in pipelines,
+// ie. '{{ .Extract | sentences 2 | wrap 80 }}'.
+func templateFuncs(lang string) template.FuncMap {
+	return template.FuncMap{
+		"wrap":     wordWrap,
+		"truncate": truncate,
+		"sentences": func(n int, s string) string {
+			return firstSentences(lang, n, s)
+		},
+		"words": func(n int, s string) string {
+			return limitWords(lang, n, s)
+		},
+		"json": jsonEscape,
+	}
+}
+
+// newTemplate will parse the given user-defined template text,
+// with the helper functions available.
+func newTemplate(text string) (*template.Template, error) {
+	return template.New("output").Funcs(templateFuncs("")).Parse(text)
+}
+
+// truncate will shorten s to at most n characters, ending with an ellipsis when it is truncated.
+func truncate(n int, s string) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+
+	return strings.TrimRight(string(r[:n-1]), " ") + "…"
+}
+
+// jsonEscape will encode v as JSON, ie. a quoted and escaped JSON string for a string.
+func jsonEscape(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
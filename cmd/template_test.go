@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	type args struct {
+		n int
+		s string
+	}
+	tests := []struct {
+		desc string
+		args args
+		want string
+	}{
+		{
+			desc: "Shorter than n",
+			args: args{n: 10, s: "Golang"},
+			want: "Golang",
+		},
+		{
+			desc: "Longer than n",
+			args: args{n: 10, s: "Go is a programming language"},
+			want: "Go is a p…",
+		},
+		{
+			desc: "Multi-byte characters",
+			args: args{n: 4, s: "Château"},
+			want: "Châ…",
+		},
+		{
+			desc: "n is 0",
+			args: args{n: 0, s: "Golang"},
+			want: "Golang",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, truncate(tt.args.n, tt.args.s))
+		})
+	}
+}
+
+func TestJsonEscape(t *testing.T) {
+	tests := []struct {
+		desc string
+		v    any
+		want string
+	}{
+		{
+			desc: "String",
+			v:    "Rock \"n\" roll\n",
+			want: `"Rock \"n\" roll\n"`,
+		},
+		{
+			desc: "Sections",
+			v:    []Section{{Index: 1, Level: 2, Title: "History", Anchor: "History"}},
+			want: `[{"index":1,"level":2,"title":"History","anchor":"History"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := jsonEscape(tt.v)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
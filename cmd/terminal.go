@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	// defaultPager is the pager used when the PAGER environment variable isn't set
+	defaultPager = "less"
+
+	// defaultLess represents the options of less when the LESS environment variable isn't set:
+	// quit if the output fits on one screen, keep the colors and hyperlinks and don't clear the screen
+	defaultLess = "FRX"
+)
+
+// validThemes represents the builtin values of the 'theme' flag.
+// Any other value is the path to a glamour JSON style file.
+var validThemes = []string{"auto", "dark", "light", "notty"}
+
+// terminalSize returns the size of the terminal w writes to.
+// ok is false if w isn't a terminal, ie. a file or a pipe.
+func terminalSize(w io.Writer) (width, height int, ok bool) {
+	f, isFile := w.(*os.File)
+	if !isFile || !term.IsTerminal(int(f.Fd())) {
+		return 0, 0, false
+	}
+
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return width, height, true
+}
+
+// hyperlink returns text as an OSC 8 hyperlink to the given url,
+// which terminals supporting it make clickable.
+// Ref: https://gist.github.com/egmontkob/eb114294efbcd5adb1944c9f3cb5feda
+func hyperlink(url, text string) string {
+	return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, text)
+}
+
+// pagerCommand returns the command of the pager: the PAGER environment variable when set,
+// 'less' otherwise. It returns an empty string when paging is disabled, ie. "PAGER=cat".
+func pagerCommand() string {
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		return defaultPager
+	}
+
+	pager = strings.TrimSpace(pager)
+	if pager == "cat" {
+		return ""
+	}
+
+	return pager
+}
+
+// writePaged will write out to w through the given pager command.
+// It writes out directly to w if the pager can't be started.
+func writePaged(pager string, w io.Writer, out []byte) error {
+	args := strings.Fields(pager)
+	if len(args) == 0 {
+		_, err := w.Write(out)
+		return err
+	}
+
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin = bytes.NewReader(out)
+	c.Stdout = w
+	c.Stderr = os.Stderr
+	c.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		c.Env = append(c.Env, "LESS="+defaultLess)
+	}
+
+	if err := c.Start(); err != nil {
+		_, err := w.Write(out)
+		return err
+	}
+
+	return c.Wait()
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalSize(t *testing.T) {
+	_, _, ok := terminalSize(&bytes.Buffer{})
+	assert.False(t, ok)
+
+	f, err := os.CreateTemp(t.TempDir(), "output")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, _, ok = terminalSize(f)
+	assert.False(t, ok)
+}
+
+func TestHyperlink(t *testing.T) {
+	assert.Equal(t, "\x1b]8;;https://en.wikipedia.org/wiki/Go\x1b\\Go\x1b]8;;\x1b\\", hyperlink("https://en.wikipedia.org/wiki/Go", "Go"))
+}
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		pager *string
+		want  string
+	}{
+		{name: "Unset", pager: nil, want: defaultPager},
+		{name: "Set", pager: ptr("more -s"), want: "more -s"},
+		{name: "Disabled with cat", pager: ptr("cat"), want: ""},
+		{name: "Disabled with empty", pager: ptr(""), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pager != nil {
+				t.Setenv("PAGER", *tt.pager)
+			} else {
+				// t.Setenv restores the variable once the test is done
+				t.Setenv("PAGER", "")
+				os.Unsetenv("PAGER")
+			}
+
+			assert.Equal(t, tt.want, pagerCommand())
+		})
+	}
+}
+
+func TestWritePaged(t *testing.T) {
+	// The output is written directly when the pager can't be started
+	w := &bytes.Buffer{}
+	assert.NoError(t, writePaged("wpdia-go-unknown-pager", w, []byte("Go\n")))
+	assert.Equal(t, "Go\n", w.String())
+
+	w.Reset()
+	assert.NoError(t, writePaged("", w, []byte("Go\n")))
+	assert.Equal(t, "Go\n", w.String())
+
+	pager := newExternalFormatter(t, "pager", "echo \"LESS=$LESS\"\nwhile IFS= read -r line; do echo \"| $line\"; done\n")
+	t.Setenv("LESS", "")
+	os.Unsetenv("LESS")
+
+	w.Reset()
+	assert.NoError(t, writePaged(pager, w, []byte("Go\n")))
+	assert.Equal(t, "LESS=FRX\n| Go\n", w.String())
+}
+
+// ptr returns a pointer to the given value.
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/uniseg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// tocCmd represents the 'toc' command
+	tocCmd = &cobra.Command{
+		Use:   "toc <title>",
+		Short: "List the sections of an article",
+		Long: `List the sections of the Wikipedia article best matching the given title:
+their index, level and anchor.
+
+A section can then be returned with the '--section' flag, by index or by title, ie.
+  wpdia-go toc golang
+  wpdia-go --section History golang`,
+
+		PreRunE: validateTOCFlags,
+
+		Args: cobra.ExactArgs(1),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			w, err := NewWikiClient(APIBaseURL, "")
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+
+			p := searchPage(w, args[0])
+
+			logger.Info("Getting sections...", slog.Uint64("pageid", p.Pageid))
+
+			sections, err := w.GetSections(p.Pageid)
+			if err != nil {
+				logger.Error(err.Error(), slog.String("url", APIBaseURL), slog.Uint64("pageid", p.Pageid))
+				os.Exit(1)
+			}
+
+			if err := writeTOC(os.Stdout, p.Title, sections, output, header); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(tocCmd)
+}
+
+// validateTOCFlags will determine whether the flags of the 'toc' command are valid.
+func validateTOCFlags(cmd *cobra.Command, args []string) error {
+	return validateListFlags(cmd, args)
+}
+
+// writeTOC will write the sections of the article with the given title to w in the given output format.
+// The plain output is a table with the index, the title indented by level and the anchor of each section,
+// the markdown output a nested list of links to the sections. The csv and tsv outputs start with a row
+// of the column names when header is true.
+func writeTOC(w io.Writer, title string, sections []Section, format string, header bool) error {
+	t := Table{Columns: []string{"index", "level", "title", "anchor"}}
+	for _, s := range sections {
+		t.Rows = append(t.Rows, []string{strconv.Itoa(s.Index), strconv.Itoa(s.Level), s.Title, s.Anchor})
+	}
+
+	return writeList(w, List{
+		Title: title + ": sections",
+		Value: sections,
+		Table: t,
+		Plain: func(w io.Writer) error {
+			return writeTOCPlain(w, sections)
+		},
+		Markdown: func(w io.Writer) error {
+			for _, s := range sections {
+				// Top level sections are level 2
+				_, err := fmt.Fprintf(w, "%s- %s\n", strings.Repeat("  ", max(s.Level-2, 0)), markdownLink(s.Title, articleURL(lang, title)+"#"+s.Anchor))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, format, header)
+}
+
+// writeTOCPlain will write the given sections to w as a table with the index,
+// the title indented by level and the anchor of each section.
+func writeTOCPlain(w io.Writer, sections []Section) error {
+	if len(sections) == 0 {
+		_, err := fmt.Fprintln(w, "The article has no section.")
+		return err
+	}
+
+	titles := make([]string, 0, len(sections))
+	indexWidth, titleWidth := 0, 0
+	for _, s := range sections {
+		// Top level sections are level 2
+		title := strings.Repeat("  ", max(s.Level-2, 0)) + s.Title
+		titles = append(titles, title)
+
+		indexWidth = max(indexWidth, len(fmt.Sprint(s.Index)))
+		titleWidth = max(titleWidth, uniseg.StringWidth(title))
+	}
+
+	for i, s := range sections {
+		padding := strings.Repeat(" ", titleWidth-uniseg.StringWidth(titles[i]))
+
+		_, err := fmt.Fprintf(w, "%*d  %s%s  #%s\n", indexWidth, s.Index, titles[i], padding, s.Anchor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTOC(t *testing.T) {
+	lang = "en"
+
+	sections := []Section{
+		{Index: 1, Level: 2, Title: "History", Anchor: "History"},
+		{Index: 2, Level: 3, Title: "Naming", Anchor: "Naming"},
+		{Index: 10, Level: 2, Title: "Café", Anchor: "Café"},
+	}
+
+	tests := []struct {
+		name     string
+		sections []Section
+		format   string
+		header   bool
+		want     string
+	}{
+		{
+			name:     "Plain",
+			sections: sections,
+			format:   "plain",
+			want:     " 1  History   #History\n 2    Naming  #Naming\n10  Café      #Café\n",
+		},
+		{
+			name:     "Plain without section",
+			sections: nil,
+			format:   "plain",
+			want:     "The article has no section.\n",
+		},
+		{
+			name:     "JSON",
+			sections: sections[:1],
+			format:   "json",
+			want:     "[\n    {\n        \"index\": 1,\n        \"level\": 2,\n        \"title\": \"History\",\n        \"anchor\": \"History\"\n    }\n]\n",
+		},
+		{
+			name:     "JSON without section",
+			sections: nil,
+			format:   "json",
+			want:     "[]\n",
+		},
+		{
+			name:     "YAML",
+			sections: sections[:1],
+			format:   "yaml",
+			want:     "- index: 1\n  level: 2\n  title: History\n  anchor: History\n",
+		},
+		{
+			name:     "CSV",
+			sections: sections[:2],
+			format:   "csv",
+			header:   true,
+			want:     "index,level,title,anchor\n1,2,History,History\n2,3,Naming,Naming\n",
+		},
+		{
+			name:     "Markdown",
+			sections: sections[:2],
+			format:   "markdown",
+			want:     "- [History](https://en.wikipedia.org/wiki/Golang#History)\n  - [Naming](https://en.wikipedia.org/wiki/Golang#Naming)\n",
+		},
+		{
+			name:     "Markdown with brackets",
+			sections: []Section{{Index: 1, Level: 2, Title: "Notes [a]", Anchor: "Notes_[a]"}},
+			format:   "markdown",
+			want:     "- [Notes \\[a\\]](https://en.wikipedia.org/wiki/Golang#Notes_[a])\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := writeTOC(w, "Golang", tt.sections, tt.format, tt.header)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+}
+
+func TestValidateTOCFlags(t *testing.T) {
+	logLevel, logFormat = "error", "text"
+
+	t.Cleanup(func() {
+		output, templateText = "plain", ""
+	})
+
+	templateText = "{{range .}}{{.Title}}\n{{end}}"
+	for _, o := range listOutputs {
+		output = o
+		assert.NoError(t, validateTOCFlags(tocCmd, nil), o)
+	}
+
+	output = "dot"
+	assert.Error(t, validateTOCFlags(tocCmd, nil))
+}
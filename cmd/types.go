@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -13,24 +15,51 @@ type WikiSearchResponse struct {
 		Continue string `json:"continue"`
 	} `json:"continue"`
 	Query struct {
-		Search []struct {
-			Ns        int       `json:"ns"`
-			Title     string    `json:"title"`
-			Pageid    uint64    `json:"pageid"`
-			Timestamp time.Time `json:"timestamp"`
-		} `json:"search"`
+		Search []WikiSearchResult `json:"search"`
 	} `json:"query"`
 }
 
+// WikiSearchResult represents a single result of the Wikipedia Search API.
+type WikiSearchResult struct {
+	Ns        int       `json:"ns"`
+	Title     string    `json:"title"`
+	Pageid    uint64    `json:"pageid"`
+	Snippet   string    `json:"snippet"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // WikiTextExtractResponse represents the Wikipedia's TextExtracts API response
 // Documentation is found here: https://www.mediawiki.org/wiki/Extension:TextExtracts#API
 type WikiTextExtractResponse struct {
 	Batchcomplete string `json:"batchcomplete"`
-	Query         struct {
+	Continue      struct {
+		// Excontinue is set when some extracts are missing from the response,
+		// they have to be requested again with the 'excontinue' parameter
+		Excontinue *int   `json:"excontinue"`
+		Continue   string `json:"continue"`
+	} `json:"continue"`
+	Query struct {
 		Pages map[string]Page `json:"pages"`
 	} `json:"query"`
 }
 
+// singlePage will return the only page of the response.
+// Because we request only 1 page from Wikipedia's API,
+// Query.Pages **should be** a map of only one element.
+// It returns an error if it is unexpectedly not the case.
+func (r *WikiTextExtractResponse) singlePage() (*Page, error) {
+	if len(r.Query.Pages) != 1 {
+		return nil, fmt.Errorf("expected an anwser of 1 page, got %d", len(r.Query.Pages))
+	}
+
+	var page Page
+	for _, v := range r.Query.Pages {
+		page = v
+	}
+
+	return &page, nil
+}
+
 // Page represents the page section of the Wikipedia's TextExtracts API response
 // Documentation is found here: https://www.mediawiki.org/wiki/Extension:TextExtracts#API
 type Page struct {
@@ -44,6 +73,36 @@ type Page struct {
 	Extract string `json:"extract"`
 
 	PageProps *WikiPageProps `json:"pageprops,omitempty" yaml:"pageprops,omitempty"`
+
+	// Information about the page, requested with 'prop=info'.
+	// Documentation is found here: https://www.mediawiki.org/wiki/API:Info
+	// URL is the canonical URL of the page, 'canonicalurl' in the API response.
+	URL          string           `json:"canonicalurl,omitempty"`
+	DisplayTitle string           `json:"displaytitle,omitempty"`
+	Length       int              `json:"length,omitempty"`
+	Touched      time.Time        `json:"touched,omitzero"`
+	LastRevID    int              `json:"lastrevid,omitempty"`
+	Protection   []WikiProtection `json:"protection,omitempty"`
+
+	// Watchers is missing when the page has too few watchers to be disclosed
+	Watchers *int `json:"watchers,omitempty"`
+
+	// Revisions is the last revision of the page, requested with 'prop=revisions'.
+	// Documentation is found here: https://www.mediawiki.org/wiki/API:Revisions
+	Revisions []WikiRevision `json:"revisions,omitempty"`
+
+	// Coordinates are the primary coordinates of the page, requested with 'prop=coordinates'.
+	// Documentation is found here: https://www.mediawiki.org/wiki/Extension:GeoData
+	Coordinates []WikiCoordinates `json:"coordinates,omitempty"`
+
+	// FetchedAt is the time the page has been retrieved from the API
+	FetchedAt time.Time `json:"-" yaml:"-"`
+
+	// Wikidata is the Wikidata entity of the page, when requested
+	Wikidata *Wikidata `json:"-" yaml:"-"`
+
+	// Infobox is the first infobox of the page, when requested
+	Infobox *Infobox `json:"-" yaml:"-"`
 }
 
 // WikiPageProps represents the Wikipedia's API response for a 'pageprops' query.
@@ -57,8 +116,206 @@ type WikiPageProps struct {
 	WikiBaseItem      string  `json:"wikibase_item,omitempty" yaml:"wikibase_item,omitempty"`
 }
 
+// WikiProtection represents a protection of a page, ie. only the autoconfirmed users can edit it.
+type WikiProtection struct {
+	// Type is the protected action, ie. "edit" or "move"
+	Type  string `json:"type" yaml:"type"`
+	Level string `json:"level" yaml:"level"`
+
+	// Expiry is either a timestamp or "infinity"
+	Expiry string `json:"expiry" yaml:"expiry"`
+}
+
+// WikiRevision represents a revision of a page.
+type WikiRevision struct {
+	RevID     int       `json:"revid,omitempty" yaml:"revid,omitempty"`
+	User      string    `json:"user,omitempty" yaml:"user,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero" yaml:"timestamp,omitempty"`
+}
+
+// WikiCoordinates represents the geographic coordinates of a page.
+type WikiCoordinates struct {
+	Lat float64 `json:"lat" yaml:"lat"`
+	Lon float64 `json:"lon" yaml:"lon"`
+
+	// Globe is the celestial body the coordinates are on, ie. "earth" or "moon"
+	Globe string `json:"globe,omitempty" yaml:"globe,omitempty"`
+}
+
+// NearbyPage represents an article found around a point by a geographic search.
+type NearbyPage struct {
+	Pageid uint64  `json:"pageid" yaml:"pageid"`
+	Title  string  `json:"title" yaml:"title"`
+	Lat    float64 `json:"lat" yaml:"lat"`
+	Lon    float64 `json:"lon" yaml:"lon"`
+
+	// Dist is the distance to the point, in meters
+	Dist float64 `json:"dist" yaml:"dist"`
+
+	// URL is the URL of the article, not returned by the API
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// Section represents a section heading of a Wikipedia article.
+type Section struct {
+	// Index is the position of the section in the article, starting at 1
+	Index int `json:"index" yaml:"index"`
+
+	// Level is the heading level of the section, as in the wikitext.
+	// Top level sections ("== Title ==") are level 2.
+	Level int    `json:"level" yaml:"level"`
+	Title string `json:"title" yaml:"title"`
+
+	// Anchor is the fragment of the URL of the section, ie. "History" in "/wiki/Go#History"
+	Anchor string `json:"anchor" yaml:"anchor"`
+
+	// Text is the text of the section, up to the next section heading.
+	// It is empty when only listing the sections.
+	Text string `json:"text,omitempty" yaml:"text,omitempty"`
+}
+
+// WikiParseResponse represents the Wikipedia's API response for a 'parse' query.
+// Documentation is found here: https://www.mediawiki.org/wiki/API:Parsing_wikitext
+type WikiParseResponse struct {
+	Parse struct {
+		Title  string `json:"title"`
+		Pageid int    `json:"pageid"`
+
+		// Wikitext is the source of the page, when requested with 'prop=wikitext'
+		Wikitext string `json:"wikitext"`
+
+		// Text is the rendered HTML of the page, when requested with 'prop=text'
+		Text string `json:"text"`
+	} `json:"parse"`
+	Error *struct {
+		Code string `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+// WikidataEntitiesResponse represents the Wikidata's API response for a 'wbgetentities' query.
+// Documentation is found here: https://www.wikidata.org/w/api.php?action=help&modules=wbgetentities
+type WikidataEntitiesResponse struct {
+	Entities map[string]WikidataEntity `json:"entities"`
+	Error    *struct {
+		Code string `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+// WikidataEntity represents a single entity of the Wikidata's API response, ie. an item or a property.
+type WikidataEntity struct {
+	ID string `json:"id"`
+
+	// Missing is set when the entity doesn't exist
+	Missing *string `json:"missing"`
+
+	Labels       map[string]WikidataText        `json:"labels"`
+	Descriptions map[string]WikidataText        `json:"descriptions"`
+	Claims       map[string][]WikidataStatement `json:"claims"`
+}
+
+// WikidataText represents a label or a description of an entity in a given language.
+type WikidataText struct {
+	Language string `json:"language"`
+	Value    string `json:"value"`
+}
+
+// WikidataStatement represents a statement of an entity, ie. "instance of: programming language".
+// Documentation is found here: https://www.wikidata.org/wiki/Help:Statements
+type WikidataStatement struct {
+	Mainsnak struct {
+		// Snaktype is "value", or "somevalue" and "novalue" without a value
+		Snaktype  string `json:"snaktype"`
+		Datavalue struct {
+			// Type is the type of the value, ie. "wikibase-entityid", "time" or "string".
+			// The layout of the value depends on the type.
+			Type  string          `json:"type"`
+			Value json.RawMessage `json:"value"`
+		} `json:"datavalue"`
+	} `json:"mainsnak"`
+
+	// Rank is either "preferred", "normal" or "deprecated"
+	Rank string `json:"rank"`
+}
+
 // IsDisambiguation will verify whether the page is a disambiguation page or not.
 // It returns true if yes, false otherwise.
 func (p *Page) IsDisambiguation() bool {
-	return p.PageProps.Disambiguation != nil
+	return p.PageProps != nil && p.PageProps.Disambiguation != nil
+}
+
+// WikiContinuedResponse represents a response of the Query API which may be continued.
+// The query is decoded by the caller, as its layout depends on the requested modules.
+// Documentation is found here: https://www.mediawiki.org/wiki/API:Continue
+type WikiContinuedResponse struct {
+	// Continue holds the parameters to request the next results, absent once they have all been returned.
+	// The values are strings or numbers depending on the module.
+	Continue map[string]json.RawMessage `json:"continue"`
+	Query    json.RawMessage            `json:"query"`
+	Error    *struct {
+		Code string `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+// PageRef represents a page referred to by another one: one of its categories,
+// one of the pages it links to or one of the pages linking to it.
+type PageRef struct {
+	Pageid uint64 `json:"pageid,omitempty" yaml:"pageid,omitempty"`
+	Ns     int    `json:"ns" yaml:"ns"`
+	Title  string `json:"title" yaml:"title"`
+
+	// Hidden is whether the category is a hidden one, ie. a maintenance category
+	Hidden bool `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+
+	// Redirect is whether the page linking to another one is a redirect to it
+	Redirect bool `json:"redirect,omitempty" yaml:"redirect,omitempty"`
+
+	// Via is the title of the redirect the page links through, empty when linking directly
+	Via string `json:"via,omitempty" yaml:"via,omitempty"`
+}
+
+// PageLinks represents the pages listed for a page: the pages it links to, the pages linking to it or its categories.
+type PageLinks struct {
+	// Title is the title of the page, once normalized and its redirect followed
+	Title string
+	Links []PageRef
+}
+
+// titleResolver represents how the API resolved the requested titles of a query, with 'redirects' for the redirects.
+// Documentation is found here: https://www.mediawiki.org/wiki/API:Query#Resolving_redirects
+type titleResolver struct {
+	Normalized []titleMapping `json:"normalized"`
+	Redirects  []titleMapping `json:"redirects"`
+}
+
+// titleMapping represents the resolution of a requested title to another one.
+type titleMapping struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// merge adds the resolutions of o to r.
+func (r *titleResolver) merge(o titleResolver) {
+	r.Normalized = append(r.Normalized, o.Normalized...)
+	r.Redirects = append(r.Redirects, o.Redirects...)
+}
+
+// resolve returns the title the given requested title was resolved to:
+// normalized, ie. "go" to "Go", and then following its redirect if any.
+func (r *titleResolver) resolve(title string) string {
+	for _, m := range r.Normalized {
+		if m.From == title {
+			title = m.To
+			break
+		}
+	}
+	for _, m := range r.Redirects {
+		if m.From == title {
+			return m.To
+		}
+	}
+
+	return title
 }
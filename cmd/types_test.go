@@ -48,3 +48,13 @@ func TestPageIsDisambiguation(t *testing.T) {
 		})
 	}
 }
+
+func TestTitleResolverResolve(t *testing.T) {
+	var r titleResolver
+	r.merge(titleResolver{Normalized: []titleMapping{{From: "golang", To: "Golang"}}})
+	r.merge(titleResolver{Redirects: []titleMapping{{From: "Golang", To: "Go (programming language)"}}})
+
+	assert.Equal(t, "Go (programming language)", r.resolve("golang"))
+	assert.Equal(t, "Go (programming language)", r.resolve("Golang"))
+	assert.Equal(t, "Ken Thompson", r.resolve("Ken Thompson"))
+}
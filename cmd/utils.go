@@ -1,5 +1,36 @@
 package cmd
 
+import (
+	"fmt"
+	"html"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+const (
+	// contentLicense and contentLicenseURL represent the license of the text content of Wikipedia.
+	// Ref: https://en.wikipedia.org/wiki/Wikipedia:Copyrights
+	contentLicense    = "CC BY-SA 4.0"
+	contentLicenseURL = "https://creativecommons.org/licenses/by-sa/4.0/"
+)
+
+// rtlLanguages represents the languages of the Wikipedias written right-to-left
+var rtlLanguages = []string{"ar", "arc", "arz", "azb", "ckb", "dv", "fa", "glk", "he", "ks", "lrc", "mzn", "nqo", "pnb", "ps", "sd", "ug", "ur", "yi"}
+
+// rlm is the right-to-left mark, an invisible character with a right-to-left direction
+const rlm = "\u200f"
+
+// htmlTagRegexp matches a HTML tag, ie. '<span class="searchmatch">'
+var htmlTagRegexp = regexp.MustCompile(`<[^>]*>`)
+
 // isPresent will verify whether a string is present in a slice.
 // Returns true if yes, false otherwise.
 func isPresent(s []string, str string) bool {
@@ -10,3 +41,202 @@ func isPresent(s []string, str string) bool {
 	}
 	return false
 }
+
+// stripHTMLTags will remove the HTML tags of the given string and unescape its HTML entities.
+// This is used for the search snippets, which highlight the matches with HTML.
+func stripHTMLTags(s string) string {
+	return html.UnescapeString(htmlTagRegexp.ReplaceAllString(s, ""))
+}
+
+// forEachConcurrently will call f with each index from 0 to n-1, with at most concurrency calls running at the same time.
+// It waits for all the calls to return and returns the error of the first failed one, by index.
+func forEachConcurrently(n, concurrency int, f func(i int) error) error {
+	sem := make(chan struct{}, max(concurrency, 1))
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			errs[i] = f(i)
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getBatched will call get with the given titles by batches of maxTitlesBatch titles,
+// with at most concurrency calls running at the same time.
+// It returns the merged results of the calls by title, or the error of the first failed one.
+func getBatched[T any](titles []string, concurrency int, get func(batch []string) (map[string]T, error)) (map[string]T, error) {
+	batches := slices.Collect(slices.Chunk(titles, maxTitlesBatch))
+	results := make([]map[string]T, len(batches))
+	err := forEachConcurrently(len(batches), concurrency, func(i int) error {
+		var err error
+		results[i], err = get(batches[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]T, len(titles))
+	for _, r := range results {
+		maps.Copy(merged, r)
+	}
+
+	return merged, nil
+}
+
+// isRTL returns whether the given Wikipedia language is written right-to-left.
+func isRTL(lang string) bool {
+	return isPresent(rtlLanguages, lang)
+}
+
+// articleURL returns the canonical URL of the Wikipedia article
+// with the given title in the given language.
+func articleURL(lang, title string) string {
+	return fmt.Sprintf("https://%s.wikipedia.org/wiki/%s", lang, url.PathEscape(strings.ReplaceAll(title, " ", "_")))
+}
+
+// wordWrap will wrap each line of s so that it doesn't exceed the given width, breaking lines between words.
+// The width is the number of terminal columns: wide characters (ie. CJK) count for two columns
+// and combining characters for none. Lines may break between two wide characters, as in Chinese and Japanese
+// which don't separate words with spaces, but never before a closing punctuation such as '。'.
+// Words longer than the width are left on their own line.
+func wordWrap(width int, s string) string {
+	if width <= 0 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		var b strings.Builder
+		lineWidth := 0
+
+		for _, word := range splitWords(line) {
+			sep := 0
+			if word.space {
+				sep = 1
+			}
+
+			if lineWidth > 0 && lineWidth+sep+word.width > width {
+				b.WriteString("\n")
+				lineWidth = 0
+			} else if lineWidth > 0 && word.space {
+				b.WriteString(" ")
+				lineWidth++
+			}
+
+			b.WriteString(word.text)
+			lineWidth += word.width
+		}
+
+		lines[i] = b.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// wrapWord represents a word of a line, between which the line can be broken.
+type wrapWord struct {
+	text  string
+	width int
+	// space is true when the word is separated from the previous one by a space
+	space bool
+}
+
+// splitWords will split line into the words it can be broken between:
+// the words separated by spaces, and the wide characters.
+func splitWords(line string) []wrapWord {
+	var (
+		words   []wrapWord
+		cur     wrapWord
+		space   bool
+		prev    string
+		prevCJK bool
+		state   = -1
+	)
+
+	flush := func() {
+		if cur.text != "" {
+			words = append(words, cur)
+		}
+		cur = wrapWord{}
+	}
+
+	for line != "" {
+		var (
+			g     string
+			width int
+		)
+		g, line, width, state = uniseg.FirstGraphemeClusterInString(line, state)
+
+		if isBreakingSpace(g) {
+			flush()
+			space, prev, prevCJK = true, "", false
+			continue
+		}
+
+		// The clusters of some scripts are wide, ie. Devanagari conjuncts,
+		// only the wide characters of East Asian scripts can be broken between
+		r, _ := utf8.DecodeRuneInString(g)
+		cjk := runewidth.RuneWidth(r) > 1
+		// Break opportunity after a wide character or before a wide character,
+		// unless it is a closing punctuation or follows an opening one
+		if cur.text != "" && (prevCJK || cjk) && !strings.Contains(noLineStart, g) && !strings.Contains(noLineEnd, prev) {
+			flush()
+		}
+
+		if cur.text == "" {
+			cur.space = space
+		}
+		cur.text += g
+		cur.width += width
+
+		space, prev, prevCJK = false, g, cjk
+	}
+	flush()
+
+	return words
+}
+
+// isBreakingSpace returns whether the grapheme g is a space the lines can be broken at.
+// The no-break spaces, ie. before the French double punctuations, are part of the words.
+func isBreakingSpace(g string) bool {
+	return strings.TrimSpace(g) == "" && g != "\u00a0" && g != "\u202f"
+}
+
+// indent will wrap s at the given width, the indentation included, and indent each of its lines with prefix.
+// The blank lines are left empty.
+func indent(prefix string, width int, s string) string {
+	if width > 0 {
+		// Always leave some room for the text
+		s = wordWrap(max(width-uniseg.StringWidth(prefix), 10), s)
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+const (
+	// noLineStart represents the characters which can't start a line, ie. the closing punctuations
+	noLineStart = "、。，．・：；？！ー）」』】〕〉》〙〗｝］”’.,:;?!)]}"
+
+	// noLineEnd represents the characters which can't end a line, ie. the opening punctuations
+	noLineEnd = "（「『【〔〈《〘〖｛［“‘([{"
+)
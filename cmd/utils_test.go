@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -52,3 +55,256 @@ func TestIsPresent(t *testing.T) {
 	}
 
 }
+
+func TestStripHTMLTags(t *testing.T) {
+	tests := []struct {
+		desc string
+		s    string
+		want string
+	}{
+		{
+			desc: "No tags",
+			s:    "Go is a programming language",
+			want: "Go is a programming language",
+		},
+		{
+			desc: "Search match",
+			s:    `<span class="searchmatch">Go</span> is a programming language`,
+			want: "Go is a programming language",
+		},
+		{
+			desc: "HTML entities",
+			s:    "Rock &amp; roll &quot;music&quot;",
+			want: `Rock & roll "music"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, stripHTMLTags(tt.s))
+		})
+	}
+}
+
+func TestArticleURL(t *testing.T) {
+	type args struct {
+		lang  string
+		title string
+	}
+	tests := []struct {
+		desc string
+		args args
+		want string
+	}{
+		{
+			desc: "Single word",
+			args: args{lang: "en", title: "Golang"},
+			want: "https://en.wikipedia.org/wiki/Golang",
+		},
+		{
+			desc: "Spaces and parenthesis",
+			args: args{lang: "en", title: "Go (programming language)"},
+			want: "https://en.wikipedia.org/wiki/Go_%28programming_language%29",
+		},
+		{
+			desc: "Non ASCII and slash",
+			args: args{lang: "fr", title: "Château de Padern/Histoire"},
+			want: "https://fr.wikipedia.org/wiki/Ch%C3%A2teau_de_Padern%2FHistoire",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, articleURL(tt.args.lang, tt.args.title))
+		})
+	}
+}
+
+func TestWordWrap(t *testing.T) {
+	type args struct {
+		width int
+		s     string
+	}
+	tests := []struct {
+		desc string
+		args args
+		want string
+	}{
+		{
+			desc: "Shorter than width",
+			args: args{width: 20, s: "Go is a language"},
+			want: "Go is a language",
+		},
+		{
+			desc: "Longer than width",
+			args: args{width: 10, s: "Go is a statically typed language"},
+			want: "Go is a\nstatically\ntyped\nlanguage",
+		},
+		{
+			desc: "Multiple lines",
+			args: args{width: 8, s: "Go is a language\nIt is compiled"},
+			want: "Go is a\nlanguage\nIt is\ncompiled",
+		},
+		{
+			desc: "Width is 0",
+			args: args{width: 0, s: "Go is a language"},
+			want: "Go is a language",
+		},
+		{
+			desc: "Word longer than width",
+			args: args{width: 8, s: "See https://go.dev for more"},
+			want: "See\nhttps://go.dev\nfor more",
+		},
+		{
+			desc: "Latin with accents",
+			args: args{width: 12, s: "Le café est très apprécié"},
+			want: "Le café est\ntrès\napprécié",
+		},
+		{
+			desc: "Combining characters",
+			args: args{width: 12, s: "Le cafe\u0301 est tre\u0300s apprécié"},
+			want: "Le cafe\u0301 est\ntre\u0300s\napprécié",
+		},
+		{
+			desc: "French no-break spaces",
+			args: args{width: 12, s: "Go\u00a0: un langage\u202f!"},
+			want: "Go\u00a0: un\nlangage\u202f!",
+		},
+		{
+			desc: "Cyrillic",
+			args: args{width: 16, s: "Go — компилируемый язык программирования"},
+			want: "Go —\nкомпилируемый\nязык\nпрограммирования",
+		},
+		{
+			desc: "Greek",
+			args: args{width: 15, s: "Η Go είναι μια γλώσσα προγραμματισμού"},
+			want: "Η Go είναι μια\nγλώσσα\nπρογραμματισμού",
+		},
+		{
+			desc: "Chinese",
+			args: args{width: 10, s: "Go是Google开发的一种静态强类型语言。"},
+			want: "Go是Google\n开发的一种\n静态强类型\n语言。",
+		},
+		{
+			desc: "Japanese closing punctuation",
+			args: args{width: 8, s: "Goは、プログラミング言語です。"},
+			want: "Goは、プ\nログラミ\nング言語\nです。",
+		},
+		{
+			desc: "Japanese opening punctuation",
+			args: args{width: 8, s: "言語「Go」です"},
+			want: "言語\n「Go」で\nす",
+		},
+		{
+			desc: "Korean",
+			args: args{width: 12, s: "고는 구글이 개발한 프로그래밍 언어이다"},
+			want: "고는 구글이\n개발한 프로\n그래밍 언어\n이다",
+		},
+		{
+			desc: "Arabic",
+			args: args{width: 12, s: "غو هي لغة برمجة مفتوحة المصدر"},
+			want: "غو هي لغة\nبرمجة مفتوحة\nالمصدر",
+		},
+		{
+			desc: "Hebrew",
+			args: args{width: 10, s: "Go היא שפת תכנות"},
+			want: "Go היא שפת\nתכנות",
+		},
+		{
+			desc: "Devanagari",
+			args: args{width: 10, s: "गो एक प्रोग्रामिंग भाषा है"},
+			want: "गो एक\nप्रोग्रामिंग\nभाषा है",
+		},
+		{
+			desc: "Emoji",
+			args: args{width: 6, s: "Go 👍 🏳️‍🌈 Go"},
+			want: "Go 👍\n🏳️‍🌈 Go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, wordWrap(tt.args.width, tt.args.s))
+		})
+	}
+}
+
+func TestIndent(t *testing.T) {
+	type args struct {
+		prefix string
+		width  int
+		s      string
+	}
+	tests := []struct {
+		desc string
+		args args
+		want string
+	}{
+		{
+			desc: "Without wrapping",
+			args: args{prefix: "  ", width: 0, s: "Go is a language.\nIt is compiled.\n\n\nHistory"},
+			want: "  Go is a language.\n  It is compiled.\n\n\n  History",
+		},
+		{
+			desc: "With wrapping",
+			args: args{prefix: "  ", width: 14, s: "Go is a statically typed language."},
+			want: "  Go is a\n  statically\n  typed\n  language.",
+		},
+		{
+			desc: "Invisible prefix characters",
+			args: args{prefix: "  " + rlm, width: 14, s: "غو هي لغة برمجة"},
+			want: "  \u200fغو هي لغة\n  \u200fبرمجة",
+		},
+		{
+			desc: "Width smaller than the prefix",
+			args: args{prefix: "    ", width: 2, s: "Go is a statically typed language."},
+			want: "    Go is a\n    statically\n    typed\n    language.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, indent(tt.args.prefix, tt.args.width, tt.args.s))
+		})
+	}
+}
+
+func TestIsRTL(t *testing.T) {
+	for _, lang := range []string{"ar", "he", "fa", "ur"} {
+		assert.True(t, isRTL(lang), lang)
+	}
+	for _, lang := range []string{"en", "fr", "ja", "zh", ""} {
+		assert.False(t, isRTL(lang), lang)
+	}
+}
+
+func TestForEachConcurrently(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	got := make([]int, 10)
+
+	err := forEachConcurrently(len(got), 3, func(i int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		got[i] = i * i
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 4, 9, 16, 25, 36, 49, 64, 81}, got)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+
+	// The error of the first failed call is returned, once all the calls have returned
+	calls := atomic.Int32{}
+	err = forEachConcurrently(5, 2, func(i int) error {
+		calls.Add(1)
+		if i >= 2 {
+			return fmt.Errorf("call %d failed", i)
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "call 2 failed")
+	assert.Equal(t, int32(5), calls.Load())
+}
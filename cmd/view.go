@@ -0,0 +1,447 @@
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// validFields represents the authorized values for the 'fields' flag
+	validFields = []string{"pageid", "ns", "title", "short_description", "extract", "url", "wikidata_item", "lang", "disambiguation", "infobox", "coordinates",
+		"display_title", "length", "touched", "last_revid", "last_rev_timestamp", "last_rev_user", "protection", "watchers", "attribution"}
+
+	// defaultFields and defaultFullFields represent the fields output when the 'fields' flag is not set,
+	// respectively without and with the 'full' flag
+	defaultFields     = []string{"title", "extract"}
+	defaultFullFields = []string{"pageid", "ns", "title", "short_description", "extract", "wikidata_item",
+		"display_title", "length", "touched", "last_revid", "last_rev_timestamp", "last_rev_user", "protection", "watchers"}
+)
+
+// ViewOptions represents the options used to build the view of a page.
+type ViewOptions struct {
+	// Lang is the language of the Wikipedia the page has been fetched from
+	Lang string
+
+	// Fields are the fields to render, among validFields.
+	// When empty, defaultFields are rendered.
+	Fields []string
+
+	// Selected is whether the fields have been selected by the user, ie. with the 'fields' flag.
+	// The 'ndjson' output then writes them as a flat object in their order, rather than as the object
+	// of the 'json' output, and the 'markdown' output only writes them, rather than its whole document.
+	Selected bool
+}
+
+// PageView is a stable and immutable view model of a page, decoupled from the API response.
+// It is what the Displayers render, and the data user-defined templates are rendered against,
+// so its exported fields must not be renamed or removed.
+//
+// A PageView owns a copy of the page it has been created from: rendering it
+// never modifies the page, and the same view can be rendered by several Displayers.
+type PageView struct {
+	Pageid           int
+	Ns               int
+	Title            string
+	ShortDescription string
+	Extract          string
+	URL              string
+	WikidataItem     string
+	Lang             string
+	Sections         []Section
+	Disambiguation   bool
+	FetchedAt        time.Time
+
+	// Information about the page and its last revision, zero when not returned by the API.
+	// The display title is in plain text, ie. "Go (programming language)".
+	DisplayTitle     string
+	Length           int
+	Touched          time.Time
+	LastRevID        int
+	LastRevTimestamp time.Time
+	LastRevUser      string
+	Protection       []WikiProtection
+
+	// Watchers is nil when the page has too few watchers to be disclosed
+	Watchers *int
+
+	// Wikidata is the Wikidata entity of the page, nil when not requested
+	Wikidata *Wikidata
+
+	// Infobox is the first infobox of the page, nil when not requested or when the page has none
+	Infobox *Infobox
+
+	// Coordinates are the primary coordinates of the page, nil when not requested or when the page has none
+	Coordinates *WikiCoordinates
+
+	// Attribution is the attribution required to reuse the text of the page
+	Attribution *Attribution
+
+	// Fields are the fields to render, among validFields
+	Fields []string
+
+	// selected is whether the fields have been selected by the user
+	selected bool
+
+	// page is a copy of the page the view has been created from
+	page Page
+}
+
+// NewPageView creates the view model of the given page with the given options.
+// The page is copied, so it can be modified afterwards without affecting the view.
+func NewPageView(p *Page, opts ViewOptions) *PageView {
+	v := &PageView{
+		Title:          p.Title,
+		Extract:        plainHeadings(p.Extract),
+		URL:            articleURL(opts.Lang, p.Title),
+		Lang:           opts.Lang,
+		Sections:       parseSections(p.Extract),
+		Disambiguation: p.IsDisambiguation(),
+		FetchedAt:      p.FetchedAt,
+		DisplayTitle:   plainDisplayTitle(p.DisplayTitle),
+		Length:         p.Length,
+		Touched:        p.Touched,
+		LastRevID:      p.LastRevID,
+		Fields:         slices.Clone(selectFields(opts.Fields, false)),
+		selected:       opts.Selected && len(opts.Fields) > 0,
+		page:           copyPage(p),
+	}
+	v.Wikidata = v.page.Wikidata
+	v.Infobox = v.page.Infobox
+	v.Protection = v.page.Protection
+	v.Watchers = v.page.Watchers
+	if len(v.page.Coordinates) > 0 {
+		v.Coordinates = &v.page.Coordinates[0]
+	}
+
+	if p.URL != "" {
+		v.URL = p.URL
+	}
+	if len(p.Revisions) > 0 {
+		v.LastRevTimestamp = p.Revisions[0].Timestamp
+		v.LastRevUser = p.Revisions[0].User
+	}
+	v.Attribution = newAttribution(opts.Lang, v.Title, v.URL, v.LastRevID)
+
+	if p.Pageid != nil {
+		v.Pageid = *p.Pageid
+	}
+	if p.Ns != nil {
+		v.Ns = *p.Ns
+	}
+	if p.PageProps != nil {
+		v.ShortDescription = p.PageProps.WikiBaseShortDesc
+		v.WikidataItem = p.PageProps.WikiBaseItem
+	}
+
+	return v
+}
+
+// copyPage returns a deep copy of the given page.
+func copyPage(p *Page) Page {
+	c := *p
+
+	if p.Pageid != nil {
+		c.Pageid = new(int)
+		*c.Pageid = *p.Pageid
+	}
+	if p.Ns != nil {
+		c.Ns = new(int)
+		*c.Ns = *p.Ns
+	}
+	if p.PageProps != nil {
+		props := *p.PageProps
+		if p.PageProps.Disambiguation != nil {
+			props.Disambiguation = new(string)
+			*props.Disambiguation = *p.PageProps.Disambiguation
+		}
+		c.PageProps = &props
+	}
+	if p.Wikidata != nil {
+		d := *p.Wikidata
+		d.Claims = make([]WikidataClaim, len(p.Wikidata.Claims))
+		for i, claim := range p.Wikidata.Claims {
+			claim.Values = slices.Clone(claim.Values)
+			d.Claims[i] = claim
+		}
+		c.Wikidata = &d
+	}
+	if p.Watchers != nil {
+		c.Watchers = new(int)
+		*c.Watchers = *p.Watchers
+	}
+	c.Protection = slices.Clone(p.Protection)
+	c.Revisions = slices.Clone(p.Revisions)
+	c.Coordinates = slices.Clone(p.Coordinates)
+	if p.Infobox != nil {
+		i := *p.Infobox
+		i.Fields = slices.Clone(p.Infobox.Fields)
+		c.Infobox = &i
+	}
+
+	return c
+}
+
+// Has returns whether the given field has to be rendered.
+func (v *PageView) Has(field string) bool {
+	return isPresent(v.Fields, field)
+}
+
+// hasValue returns whether the given field is rendered and has a value,
+// the number of watchers being missing when it isn't disclosed.
+func (v *PageView) hasValue(field string) bool {
+	return v.Has(field) && (field != "watchers" || v.Watchers != nil)
+}
+
+// Field returns the value of the given field of the view.
+// The field names are the ones of validFields. It returns nil for an unknown field.
+func (v *PageView) Field(name string) any {
+	switch name {
+	case "pageid":
+		return v.Pageid
+	case "ns":
+		return v.Ns
+	case "title":
+		return v.Title
+	case "short_description":
+		return v.ShortDescription
+	case "extract":
+		return v.Extract
+	case "url":
+		return v.URL
+	case "wikidata_item":
+		return v.WikidataItem
+	case "lang":
+		return v.Lang
+	case "disambiguation":
+		return v.Disambiguation
+	case "infobox":
+		if v.Infobox == nil {
+			return ""
+		}
+		return v.Infobox.String()
+	case "coordinates":
+		return coordinatesText(v.Coordinates)
+	case "display_title":
+		return v.DisplayTitle
+	case "length":
+		return v.Length
+	case "touched":
+		return formatTimestamp(v.Touched)
+	case "last_revid":
+		return v.LastRevID
+	case "last_rev_timestamp":
+		return formatTimestamp(v.LastRevTimestamp)
+	case "last_rev_user":
+		return v.LastRevUser
+	case "protection":
+		return protectionText(v.Protection)
+	case "watchers":
+		if v.Watchers == nil {
+			return ""
+		}
+		return *v.Watchers
+	case "attribution":
+		return v.Attribution.String()
+	default:
+		return nil
+	}
+}
+
+// rawExtract returns the extract as returned by the API,
+// with the wikitext-style section headings when requested.
+func (v *PageView) rawExtract() string {
+	return v.page.Extract
+}
+
+// pageDocument represents a page as encoded by the structured outputs, ie. json and yaml.
+// Its layout follows the one of the API response.
+type pageDocument struct {
+	Pageid *int `json:"pageid,omitempty" yaml:"pageid,omitempty"`
+	Ns     *int `json:"ns,omitempty" yaml:"ns,omitempty"`
+
+	Title   string `json:"title" yaml:"title"`
+	Extract string `json:"extract,omitempty" yaml:"extract,omitempty"`
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+	Lang    string `json:"lang,omitempty" yaml:"lang,omitempty"`
+
+	// Sections are the sections of the extract with their text,
+	// the extract being then only the text before the first section
+	Sections []Section `json:"sections,omitempty" yaml:"sections,omitempty"`
+
+	PageProps *WikiPageProps `json:"pageprops,omitempty" yaml:"pageprops,omitempty"`
+
+	DisplayTitle string           `json:"displaytitle,omitempty" yaml:"displaytitle,omitempty"`
+	Length       int              `json:"length,omitempty" yaml:"length,omitempty"`
+	Touched      *time.Time       `json:"touched,omitempty" yaml:"touched,omitempty"`
+	LastRevID    int              `json:"lastrevid,omitempty" yaml:"lastrevid,omitempty"`
+	Protection   []WikiProtection `json:"protection,omitempty" yaml:"protection,omitempty"`
+	Watchers     *int             `json:"watchers,omitempty" yaml:"watchers,omitempty"`
+
+	// Revisions is the last revision of the page, with only the selected fields
+	Revisions []WikiRevision `json:"revisions,omitempty" yaml:"revisions,omitempty"`
+
+	Wikidata    *Wikidata         `json:"wikidata,omitempty" yaml:"wikidata,omitempty"`
+	Infobox     *Infobox          `json:"infobox,omitempty" yaml:"infobox,omitempty"`
+	Coordinates []WikiCoordinates `json:"coordinates,omitempty" yaml:"coordinates,omitempty"`
+	Attribution *Attribution      `json:"attribution,omitempty" yaml:"attribution,omitempty"`
+}
+
+// Document returns the page as encoded by the structured outputs, with only the selected fields.
+// The title is always present. When the extract has sections, they are returned
+// as a structured array and the extract is only the text before the first section.
+func (v *PageView) Document() *pageDocument {
+	p := copyPage(&v.page)
+	d := &pageDocument{Title: v.Title}
+
+	if v.Has("pageid") {
+		d.Pageid = p.Pageid
+	}
+	if v.Has("ns") {
+		d.Ns = p.Ns
+	}
+	if v.Has("extract") {
+		d.Extract, d.Sections = splitSections(v.rawExtract())
+	}
+	if v.Has("url") {
+		d.URL = v.URL
+	}
+	if v.Has("lang") {
+		d.Lang = v.Lang
+	}
+
+	if p.PageProps != nil {
+		var props WikiPageProps
+		if v.Has("disambiguation") {
+			props.Disambiguation = p.PageProps.Disambiguation
+		}
+		if v.Has("short_description") {
+			props.WikiBaseShortDesc = p.PageProps.WikiBaseShortDesc
+		}
+		if v.Has("wikidata_item") {
+			props.WikiBaseItem = p.PageProps.WikiBaseItem
+		}
+
+		if props != (WikiPageProps{}) {
+			d.PageProps = &props
+		}
+	}
+
+	if v.Has("display_title") {
+		d.DisplayTitle = v.DisplayTitle
+	}
+	if v.Has("length") {
+		d.Length = v.Length
+	}
+	if v.Has("touched") && !v.Touched.IsZero() {
+		d.Touched = &v.Touched
+	}
+	if v.Has("last_revid") {
+		d.LastRevID = v.LastRevID
+	}
+	if v.Has("protection") {
+		d.Protection = p.Protection
+	}
+	if v.Has("watchers") {
+		d.Watchers = p.Watchers
+	}
+
+	if len(p.Revisions) > 0 {
+		var rev WikiRevision
+		if v.Has("last_revid") {
+			rev.RevID = p.Revisions[0].RevID
+		}
+		if v.Has("last_rev_timestamp") {
+			rev.Timestamp = p.Revisions[0].Timestamp
+		}
+		if v.Has("last_rev_user") {
+			rev.User = p.Revisions[0].User
+		}
+
+		if rev != (WikiRevision{}) {
+			d.Revisions = []WikiRevision{rev}
+		}
+	}
+
+	// The Wikidata entity is only present when requested
+	d.Wikidata = p.Wikidata
+
+	if v.Has("infobox") {
+		d.Infobox = p.Infobox
+	}
+	if v.Has("coordinates") {
+		d.Coordinates = p.Coordinates
+	}
+	if v.Has("attribution") {
+		a := *v.Attribution
+		d.Attribution = &a
+	}
+
+	return d
+}
+
+// selectFields returns the fields to output: the given fields when set,
+// the default ones depending on whether the full output is requested otherwise.
+func selectFields(fields []string, full bool) []string {
+	if len(fields) > 0 {
+		return fields
+	}
+	if full {
+		return defaultFullFields
+	}
+	return defaultFields
+}
+
+// plainDisplayTitle returns the given display title in plain text,
+// ie. "<i>Go</i> (programming language)" becomes "Go (programming language)".
+func plainDisplayTitle(title string) string {
+	return strings.TrimSpace(stripHTMLTags(title))
+}
+
+// formatTimestamp returns the given time in RFC 3339 format, or an empty string if it is zero.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// coordinatesText returns the given coordinates as text, ie. "48.693611, 6.183333".
+// The globe is added when it isn't the Earth, and an empty string is returned when c is nil.
+func coordinatesText(c *WikiCoordinates) string {
+	if c == nil {
+		return ""
+	}
+
+	text := strconv.FormatFloat(c.Lat, 'f', -1, 64) + ", " + strconv.FormatFloat(c.Lon, 'f', -1, 64)
+	if c.Globe != "" && c.Globe != "earth" {
+		text += " (" + c.Globe + ")"
+	}
+
+	return text
+}
+
+// protectionText returns the protections of a page as text, ie. "edit=autoconfirmed, move=sysop".
+// The expiry is added when the protection isn't indefinite.
+func protectionText(protections []WikiProtection) string {
+	texts := make([]string, 0, len(protections))
+	for _, p := range protections {
+		text := p.Type + "=" + p.Level
+		if p.Expiry != "" && p.Expiry != "infinity" {
+			text += " (until " + p.Expiry + ")"
+		}
+		texts = append(texts, text)
+	}
+
+	return strings.Join(texts, ", ")
+}
+
+// plainHeadings will turn the wikitext-style section headings ("== History ==")
+// of the given extract into plain text headings ("History"), as returned with 'exsectionformat=plain'.
+func plainHeadings(extract string) string {
+	return wikiHeadingRegexp.ReplaceAllStringFunc(extract, func(heading string) string {
+		return strings.TrimSpace(wikiHeadingRegexp.FindStringSubmatch(heading)[2])
+	})
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPageView(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *Page
+		opts ViewOptions
+		want *PageView
+	}{
+		{
+			name: "Full page",
+			p:    &page,
+			opts: ViewOptions{Lang: "en", Fields: []string{"title", "url"}},
+			want: &PageView{
+				Pageid:           *page.Pageid,
+				Ns:               *page.Ns,
+				Title:            page.Title,
+				ShortDescription: page.PageProps.WikiBaseShortDesc,
+				Extract:          page.Extract,
+				URL:              "https://en.wikipedia.org/wiki/Golang",
+				WikidataItem:     page.PageProps.WikiBaseItem,
+				Lang:             "en",
+				DisplayTitle:     "Golang",
+				Length:           page.Length,
+				Touched:          page.Touched,
+				LastRevID:        page.LastRevID,
+				LastRevTimestamp: page.Revisions[0].Timestamp,
+				LastRevUser:      "Gopher",
+				Protection:       page.Protection,
+				Attribution: &Attribution{
+					Title:      page.Title,
+					URL:        "https://en.wikipedia.org/wiki/Golang",
+					Permalink:  "https://en.wikipedia.org/w/index.php?oldid=1211970426&title=Golang",
+					History:    "https://en.wikipedia.org/w/index.php?action=history&title=Golang",
+					License:    contentLicense,
+					LicenseURL: contentLicenseURL,
+				},
+				Fields: []string{"title", "url"},
+				page:   copyPage(&page),
+			},
+		},
+		{
+			name: "Partial page with sections",
+			p: &Page{
+				Title:   "Go",
+				Extract: "Go is a language.\n\n\n== History ==\nHistory.",
+			},
+			opts: ViewOptions{Lang: "fr"},
+			want: &PageView{
+				Title:    "Go",
+				Extract:  "Go is a language.\n\n\nHistory\nHistory.",
+				URL:      "https://fr.wikipedia.org/wiki/Go",
+				Lang:     "fr",
+				Sections: []Section{{Index: 1, Level: 2, Title: "History", Anchor: "History"}},
+				Attribution: &Attribution{
+					Title:      "Go",
+					URL:        "https://fr.wikipedia.org/wiki/Go",
+					History:    "https://fr.wikipedia.org/w/index.php?action=history&title=Go",
+					License:    contentLicense,
+					LicenseURL: contentLicenseURL,
+				},
+				Fields: defaultFields,
+				page: Page{
+					Title:   "Go",
+					Extract: "Go is a language.\n\n\n== History ==\nHistory.",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewPageView(tt.p, tt.opts))
+		})
+	}
+}
+
+func TestPageViewField(t *testing.T) {
+	v := NewPageView(&page, ViewOptions{Lang: "en"})
+
+	tests := []struct {
+		name  string
+		field string
+		want  any
+	}{
+		{name: "pageid", field: "pageid", want: *page.Pageid},
+		{name: "title", field: "title", want: page.Title},
+		{name: "short_description", field: "short_description", want: page.PageProps.WikiBaseShortDesc},
+		{name: "url", field: "url", want: "https://en.wikipedia.org/wiki/Golang"},
+		{name: "disambiguation", field: "disambiguation", want: false},
+		{name: "infobox without infobox", field: "infobox", want: ""},
+		{name: "coordinates without coordinates", field: "coordinates", want: ""},
+		{name: "display_title", field: "display_title", want: "Golang"},
+		{name: "touched", field: "touched", want: "2024-03-06T08:00:00Z"},
+		{name: "last_rev_timestamp", field: "last_rev_timestamp", want: "2024-03-05T10:00:00Z"},
+		{name: "last_rev_user", field: "last_rev_user", want: "Gopher"},
+		{name: "protection", field: "protection", want: "edit=autoconfirmed"},
+		{name: "watchers without watchers", field: "watchers", want: ""},
+		{name: "unknown", field: "unknown", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, v.Field(tt.field))
+		})
+	}
+
+	// Every valid field must be known
+	for _, f := range validFields {
+		assert.NotNil(t, v.Field(f), f)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	assert.Equal(t, []string{"url"}, selectFields([]string{"url"}, true))
+	assert.Equal(t, defaultFields, selectFields(nil, false))
+	assert.Equal(t, defaultFullFields, selectFields(nil, true))
+}
+
+func TestNewPageViewCopiesPage(t *testing.T) {
+	p := copyPage(&page)
+	fields := []string{"pageid", "title"}
+	v := NewPageView(&p, ViewOptions{Lang: "en", Fields: fields})
+
+	// Modifying the page or the fields afterwards doesn't affect the view
+	*p.Pageid = 42
+	p.PageProps.WikiBaseItem = "Q42"
+	fields[0] = "extract"
+
+	assert.Equal(t, *page.Pageid, v.Pageid)
+	assert.Equal(t, *page.Pageid, *v.Document().Pageid)
+	assert.Equal(t, []string{"pageid", "title"}, v.Fields)
+}
+
+func TestNewPageViewCopiesInfobox(t *testing.T) {
+	p := copyPage(&page)
+	p.Infobox = &Infobox{Name: "Infobox software", Fields: []InfoboxField{{Key: "developer", Value: "Google"}}}
+	v := NewPageView(&p, ViewOptions{Lang: "en", Fields: []string{"title", "infobox"}})
+
+	p.Infobox.Fields[0].Value = "Alphabet"
+
+	assert.Equal(t, "developer: Google", v.Field("infobox"))
+	assert.Equal(t, "Google", v.Document().Infobox.Fields[0].Value)
+}
+
+func TestCoordinatesText(t *testing.T) {
+	assert.Equal(t, "", coordinatesText(nil))
+	assert.Equal(t, "48.693611, 6.183333", coordinatesText(&WikiCoordinates{Lat: 48.693611, Lon: 6.183333, Globe: "earth"}))
+	assert.Equal(t, "0.6875, 23.43 (moon)", coordinatesText(&WikiCoordinates{Lat: 0.6875, Lon: 23.43, Globe: "moon"}))
+}
+
+func TestPageViewDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *Page
+		opts ViewOptions
+		want *pageDocument
+	}{
+		{
+			name: "Default fields",
+			p:    &page,
+			opts: ViewOptions{Lang: "en"},
+			want: &pageDocument{Title: page.Title, Extract: page.Extract},
+		},
+		{
+			name: "Full fields",
+			p:    &page,
+			opts: ViewOptions{Lang: "en", Fields: defaultFullFields},
+			want: &pageDocument{
+				Pageid:    page.Pageid,
+				Ns:        page.Ns,
+				Title:     page.Title,
+				Extract:   page.Extract,
+				PageProps: page.PageProps,
+
+				DisplayTitle: "Golang",
+				Length:       page.Length,
+				Touched:      &page.Touched,
+				LastRevID:    page.LastRevID,
+				Protection:   page.Protection,
+				Revisions:    page.Revisions,
+			},
+		},
+		{
+			name: "Selected fields",
+			p:    &page,
+			opts: ViewOptions{Lang: "fr", Fields: []string{"url", "lang", "wikidata_item"}},
+			want: &pageDocument{
+				Title:     page.Title,
+				URL:       "https://fr.wikipedia.org/wiki/Golang",
+				Lang:      "fr",
+				PageProps: &WikiPageProps{WikiBaseItem: page.PageProps.WikiBaseItem},
+			},
+		},
+		{
+			name: "Sections",
+			p:    &Page{Title: "Go", Extract: "Go is a language.\n\n\n== History ==\nHistory."},
+			opts: ViewOptions{Lang: "en"},
+			want: &pageDocument{
+				Title:    "Go",
+				Extract:  "Go is a language.",
+				Sections: []Section{{Index: 1, Level: 2, Title: "History", Anchor: "History", Text: "History."}},
+			},
+		},
+		{
+			name: "Partial page",
+			p:    &Page{Title: "Go"},
+			opts: ViewOptions{Lang: "en", Fields: validFields},
+			want: &pageDocument{
+				Title: "Go",
+				URL:   "https://en.wikipedia.org/wiki/Go",
+				Lang:  "en",
+				Attribution: &Attribution{
+					Title:      "Go",
+					URL:        "https://en.wikipedia.org/wiki/Go",
+					History:    "https://en.wikipedia.org/w/index.php?action=history&title=Go",
+					License:    contentLicense,
+					LicenseURL: contentLicenseURL,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewPageView(tt.p, tt.opts).Document())
+		})
+	}
+}
+
+func TestDisplayersDoNotModifyView(t *testing.T) {
+	// Formats depending on flags or on external executables are left out
+	ds := map[string]Displayer{
+		"plain":    NewPlainFormat(0),
+		"pretty":   NewPrettyFormat(PrettyOptions{Width: 100, Theme: "notty"}),
+		"json":     NewJsonFormat("", "    "),
+		"yaml":     NewYamlFormat(),
+		"markdown": NewMarkdownFormat(true),
+		"ndjson":   NewNdjsonFormat(),
+		"html":     NewHtmlFormat(),
+	}
+
+	p := copyPage(&page)
+	p.Extract = "Go is a language.\n\n\n== History ==\nHistory."
+	want := copyPage(&p)
+
+	v := NewPageView(&p, ViewOptions{Lang: "en", Fields: validFields})
+	wantView := NewPageView(&p, ViewOptions{Lang: "en", Fields: validFields})
+
+	for name, d := range ds {
+		t.Run(name, func(t *testing.T) {
+			first, second := &bytes.Buffer{}, &bytes.Buffer{}
+
+			assert.NoError(t, d.Write(first, v))
+			assert.NoError(t, d.Write(second, v))
+
+			assert.Equal(t, first.String(), second.String())
+			assert.Equal(t, wantView, v)
+			assert.Equal(t, want, p)
+		})
+	}
+}
+
+func TestDisplayersPartialPage(t *testing.T) {
+	ds := map[string]Displayer{
+		"plain":  NewPlainFormat(0),
+		"pretty": NewPrettyFormat(PrettyOptions{Width: 100, Theme: "notty"}),
+	}
+
+	v := NewPageView(&Page{Title: "Go"}, ViewOptions{Lang: "en", Fields: defaultFullFields})
+
+	for name, d := range ds {
+		t.Run(name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			assert.NotPanics(t, func() {
+				assert.NoError(t, d.Write(w, v))
+			})
+			assert.Contains(t, w.String(), "Go")
+		})
+	}
+}
+
+func TestNewPageViewInfo(t *testing.T) {
+	p := copyPage(&page)
+	p.URL = "https://en.wikipedia.org/wiki/Go_(programming_language)"
+	p.Watchers = new(int)
+	*p.Watchers = 1042
+
+	v := NewPageView(&p, ViewOptions{Lang: "en", Fields: []string{"title", "url", "watchers"}})
+
+	assert.Equal(t, "https://en.wikipedia.org/wiki/Go_(programming_language)", v.URL)
+	assert.Equal(t, 1042, *v.Watchers)
+	assert.Equal(t, 1042, v.Field("watchers"))
+	assert.Equal(t, 1042, *v.Document().Watchers)
+
+	w := &bytes.Buffer{}
+	assert.NoError(t, NewPlainFormat(0).Write(w, v))
+	assert.Contains(t, w.String(), "Watchers:\n  1042\n")
+
+	// Only the selected fields of the last revision are output
+	v = NewPageView(&p, ViewOptions{Lang: "en", Fields: []string{"title", "last_rev_user"}})
+	assert.Equal(t, []WikiRevision{{User: "Gopher"}}, v.Document().Revisions)
+}
+
+func TestPlainDisplayTitle(t *testing.T) {
+	assert.Equal(t, "Go (programming language)", plainDisplayTitle(`<span class="mw-page-title-main">Go (programming language)</span>`))
+	assert.Equal(t, "The Go Programming Language & more", plainDisplayTitle("<i>The Go Programming Language</i> &amp; more"))
+	assert.Equal(t, "", plainDisplayTitle(""))
+}
+
+func TestProtectionText(t *testing.T) {
+	assert.Equal(t, "", protectionText(nil))
+	assert.Equal(t, "edit=autoconfirmed, move=sysop (until 2025-01-01T00:00:00Z)", protectionText([]WikiProtection{
+		{Type: "edit", Level: "autoconfirmed", Expiry: "infinity"},
+		{Type: "move", Level: "sysop", Expiry: "2025-01-01T00:00:00Z"},
+	}))
+}
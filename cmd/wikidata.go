@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxWikidataEntities is the maximum number of entities of a single 'wbgetentities' request
+const maxWikidataEntities = 50
+
+// WikidataAPIBaseURL is the base URL of the Wikidata API
+var WikidataAPIBaseURL = "https://www.wikidata.org/w/api.php"
+
+// ErrEntityNotFound is returned when the Wikidata entity doesn't exist
+var ErrEntityNotFound = errors.New("entity not found on Wikidata")
+
+// wikidataProperties represents the properties of the Wikidata entities output, in this order.
+var wikidataProperties = []string{
+	"P31",  // instance of
+	"P17",  // country
+	"P625", // coordinate location
+	"P571", // inception
+	"P856", // official website
+	"P569", // date of birth
+	"P570", // date of death
+}
+
+// Wikidata represents the Wikidata entity of a page, with a curated set of its statements.
+// The labels are in the language of the page when available.
+type Wikidata struct {
+	ID          string          `json:"id" yaml:"id"`
+	Label       string          `json:"label,omitempty" yaml:"label,omitempty"`
+	Description string          `json:"description,omitempty" yaml:"description,omitempty"`
+	URL         string          `json:"url" yaml:"url"`
+	Claims      []WikidataClaim `json:"claims,omitempty" yaml:"claims,omitempty"`
+}
+
+// WikidataClaim represents the values of a property of a Wikidata entity.
+type WikidataClaim struct {
+	// Property is the id of the property, ie. "P31"
+	Property string `json:"property" yaml:"property"`
+
+	// Label is the label of the property, ie. "instance of"
+	Label  string   `json:"label" yaml:"label"`
+	Values []string `json:"values" yaml:"values"`
+}
+
+// GetEntities will invoke the Wikidata's API to fetch the given entities,
+// with the given properties ("labels|descriptions|claims") in the given language.
+// The labels and descriptions fall back to another language when missing in the given one.
+// It returns the entities by id or any error encountered.
+func (w *WikiClient) GetEntities(ids []string, props, lang string) (map[string]WikidataEntity, error) {
+	entities := make(map[string]WikidataEntity, len(ids))
+
+	for chunk := range slices.Chunk(ids, maxWikidataEntities) {
+		params := url.Values{}
+		params.Add("action", "wbgetentities")
+		params.Add("ids", strings.Join(chunk, "|"))
+		params.Add("props", props)
+		params.Add("languages", lang)
+		params.Add("languagefallback", "1")
+
+		logger.Debug("Http request parameters set", slog.Any("params", params))
+
+		var r WikidataEntitiesResponse
+		if err := w.getJSON(params, &r); err != nil {
+			return nil, fmt.Errorf("failed to get the entities %v: %w", chunk, err)
+		}
+		if r.Error != nil {
+			return nil, fmt.Errorf("failed to get the entities %v: %s", chunk, r.Error.Info)
+		}
+
+		for id, e := range r.Entities {
+			entities[id] = e
+		}
+	}
+
+	return entities, nil
+}
+
+// GetWikidata will invoke the Wikidata's API to fetch the entity with the given id (ie. "Q37227"),
+// and resolve the labels of its properties and of the entities it refers to in the given language.
+// It returns ErrEntityNotFound if the entity doesn't exist, or any error encountered.
+func (w *WikiClient) GetWikidata(id, lang string) (*Wikidata, error) {
+	entities, err := w.GetEntities([]string{id}, "labels|descriptions|claims", lang)
+	if err != nil {
+		return nil, err
+	}
+
+	e, ok := entities[id]
+	if !ok || e.Missing != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEntityNotFound, id)
+	}
+
+	d := &Wikidata{
+		ID:          id,
+		Label:       e.Labels[lang].Value,
+		Description: e.Descriptions[lang].Value,
+		URL:         "https://www.wikidata.org/wiki/" + url.PathEscape(id),
+	}
+
+	// The labels of the properties and of the entities the values refer to are requested at once
+	var ids []string
+	for _, p := range wikidataProperties {
+		statements := bestStatements(e.Claims[p])
+		if len(statements) == 0 {
+			continue
+		}
+
+		ids = append(ids, p)
+		for _, s := range statements {
+			if ref := entityIDValue(s); ref != "" && !slices.Contains(ids, ref) {
+				ids = append(ids, ref)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return d, nil
+	}
+
+	logger.Debug("Resolving Wikidata labels...", slog.Any("ids", ids))
+
+	labels, err := w.GetEntities(ids, "labels", lang)
+	if err != nil {
+		return nil, err
+	}
+	label := func(id string) string {
+		if l := labels[id].Labels[lang].Value; l != "" {
+			return l
+		}
+		return id
+	}
+
+	for _, p := range wikidataProperties {
+		var values []string
+		for _, s := range bestStatements(e.Claims[p]) {
+			if v := wikidataValue(s, label); v != "" {
+				values = append(values, v)
+			}
+		}
+
+		if len(values) > 0 {
+			d.Claims = append(d.Claims, WikidataClaim{Property: p, Label: label(p), Values: values})
+		}
+	}
+
+	return d, nil
+}
+
+// bestStatements returns the statements with a value of the best rank:
+// the preferred ones if any, the normal ones otherwise. The deprecated ones are never returned.
+func bestStatements(statements []WikidataStatement) []WikidataStatement {
+	var preferred, normal []WikidataStatement
+	for _, s := range statements {
+		if s.Mainsnak.Snaktype != "value" {
+			continue
+		}
+
+		switch s.Rank {
+		case "preferred":
+			preferred = append(preferred, s)
+		case "normal":
+			normal = append(normal, s)
+		}
+	}
+
+	if len(preferred) > 0 {
+		return preferred
+	}
+	return normal
+}
+
+// entityIDValue returns the id of the entity the statement refers to, ie. "Q9143",
+// or an empty string if its value isn't an entity.
+func entityIDValue(s WikidataStatement) string {
+	if s.Mainsnak.Datavalue.Type != "wikibase-entityid" {
+		return ""
+	}
+
+	var v struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(s.Mainsnak.Datavalue.Value, &v); err != nil {
+		return ""
+	}
+
+	return v.ID
+}
+
+// wikidataValue returns the value of the statement as text, the entities being replaced by their label.
+// It returns an empty string if the type of the value isn't supported.
+// Documentation is found here: https://www.mediawiki.org/wiki/Wikibase/DataModel/JSON#Data_Values
+func wikidataValue(s WikidataStatement, label func(id string) string) string {
+	raw := s.Mainsnak.Datavalue.Value
+
+	switch s.Mainsnak.Datavalue.Type {
+	case "wikibase-entityid":
+		if id := entityIDValue(s); id != "" {
+			return label(id)
+		}
+
+	case "string":
+		var v string
+		if err := json.Unmarshal(raw, &v); err == nil {
+			return v
+		}
+
+	case "monolingualtext":
+		var v struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(raw, &v); err == nil {
+			return v.Text
+		}
+
+	case "quantity":
+		var v struct {
+			Amount string `json:"amount"`
+		}
+		if err := json.Unmarshal(raw, &v); err == nil {
+			return strings.TrimPrefix(v.Amount, "+")
+		}
+
+	case "globecoordinate":
+		var v struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		}
+		if err := json.Unmarshal(raw, &v); err == nil {
+			return formatCoordinates(v.Latitude, v.Longitude)
+		}
+
+	case "time":
+		var v struct {
+			Time      string `json:"time"`
+			Precision int    `json:"precision"`
+		}
+		if err := json.Unmarshal(raw, &v); err == nil {
+			return formatWikidataTime(v.Time, v.Precision)
+		}
+	}
+
+	return ""
+}
+
+// formatCoordinates returns the given coordinates in decimal degrees, ie. "48.8567, 2.3508".
+func formatCoordinates(lat, lon float64) string {
+	return strconv.FormatFloat(lat, 'f', -1, 64) + ", " + strconv.FormatFloat(lon, 'f', -1, 64)
+}
+
+// formatWikidataTime returns the given Wikidata time ("+2009-11-10T00:00:00Z") up to its precision:
+// "2009-11-10" for a day (11), "2009-11" for a month (10) and "2009" for a year (9) or less.
+// The years before the common era are suffixed with "BCE", ie. "44 BCE".
+func formatWikidataTime(t string, precision int) string {
+	date, _, _ := strings.Cut(t, "T")
+
+	bce := strings.HasPrefix(date, "-")
+	date = strings.TrimLeft(date, "+-")
+
+	parts := strings.SplitN(date, "-", 3)
+	if len(parts) != 3 {
+		return t
+	}
+
+	year := strings.TrimLeft(parts[0], "0")
+	if year == "" {
+		year = "0"
+	}
+	if bce {
+		return year + " BCE"
+	}
+
+	switch {
+	case precision >= 11:
+		return fmt.Sprintf("%s-%s-%s", year, parts[1], parts[2])
+	case precision == 10:
+		return fmt.Sprintf("%s-%s", year, parts[1])
+	default:
+		return year
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStubWikidataAPI starts a http server mimicking the 'wbgetentities' module of the Wikidata's API.
+// The Q37227 entity has claims, the labels of the other entities are in French.
+func newStubWikidataAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	labels := map[string]string{
+		"P31":   "nature de l'élément",
+		"P571":  "date de fondation ou de création",
+		"P856":  "site officiel",
+		"P625":  "coordonnées géographiques",
+		"Q9143": "langage de programmation",
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		assert.Equal(t, "wbgetentities", q.Get("action"))
+		assert.Equal(t, "fr", q.Get("languages"))
+
+		switch {
+		case q.Get("ids") == "Q404":
+			fmt.Fprint(w, `{"entities":{"Q404":{"id":"Q404","missing":""}}}`)
+		case q.Get("ids") == "Q37227":
+			assert.Equal(t, "labels|descriptions|claims", q.Get("props"))
+			fmt.Fprint(w, `{"entities":{"Q37227":{"id":"Q37227",
+				"labels":{"fr":{"language":"fr","value":"Go"}},
+				"descriptions":{"fr":{"language":"fr","value":"langage de programmation"}},
+				"claims":{
+					"P31":[
+						{"mainsnak":{"snaktype":"value","datavalue":{"type":"wikibase-entityid","value":{"entity-type":"item","id":"Q9143"}}},"rank":"normal"},
+						{"mainsnak":{"snaktype":"value","datavalue":{"type":"wikibase-entityid","value":{"entity-type":"item","id":"Q12772052"}}},"rank":"deprecated"}
+					],
+					"P571":[{"mainsnak":{"snaktype":"value","datavalue":{"type":"time","value":{"time":"+2009-11-10T00:00:00Z","precision":11}}},"rank":"normal"}],
+					"P856":[
+						{"mainsnak":{"snaktype":"value","datavalue":{"type":"string","value":"https://golang.org"}},"rank":"normal"},
+						{"mainsnak":{"snaktype":"value","datavalue":{"type":"string","value":"https://go.dev"}},"rank":"preferred"}
+					],
+					"P570":[{"mainsnak":{"snaktype":"novalue"},"rank":"normal"}],
+					"P348":[{"mainsnak":{"snaktype":"value","datavalue":{"type":"string","value":"1.25"}},"rank":"normal"}]
+				}}}}`)
+		default:
+			assert.Equal(t, "labels", q.Get("props"))
+
+			entities := map[string]WikidataEntity{}
+			for id, label := range labels {
+				entities[id] = WikidataEntity{ID: id, Labels: map[string]WikidataText{"fr": {Language: "fr", Value: label}}}
+			}
+			b, _ := json.Marshal(map[string]any{"entities": entities})
+			w.Write(b)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestWikiClientGetWikidata(t *testing.T) {
+	ts := newStubWikidataAPI(t)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetWikidata("Q37227", "fr")
+	assert.NoError(t, err)
+	assert.Equal(t, &Wikidata{
+		ID:          "Q37227",
+		Label:       "Go",
+		Description: "langage de programmation",
+		URL:         "https://www.wikidata.org/wiki/Q37227",
+		Claims: []WikidataClaim{
+			{Property: "P31", Label: "nature de l'élément", Values: []string{"langage de programmation"}},
+			{Property: "P571", Label: "date de fondation ou de création", Values: []string{"2009-11-10"}},
+			{Property: "P856", Label: "site officiel", Values: []string{"https://go.dev"}},
+		},
+	}, got)
+
+	_, err = w.GetWikidata("Q404", "fr")
+	assert.ErrorIs(t, err, ErrEntityNotFound)
+}
+
+func TestWikidataValue(t *testing.T) {
+	label := func(id string) string {
+		if id == "Q142" {
+			return "France"
+		}
+		return id
+	}
+
+	tests := []struct {
+		name      string
+		valueType string
+		value     string
+		want      string
+	}{
+		{name: "Entity", valueType: "wikibase-entityid", value: `{"entity-type":"item","id":"Q142"}`, want: "France"},
+		{name: "Entity without label", valueType: "wikibase-entityid", value: `{"entity-type":"item","id":"Q90"}`, want: "Q90"},
+		{name: "String", valueType: "string", value: `"https://go.dev"`, want: "https://go.dev"},
+		{name: "Monolingual text", valueType: "monolingualtext", value: `{"text":"Paris","language":"fr"}`, want: "Paris"},
+		{name: "Quantity", valueType: "quantity", value: `{"amount":"+2145906","unit":"1"}`, want: "2145906"},
+		{name: "Coordinates", valueType: "globecoordinate", value: `{"latitude":48.856944,"longitude":2.351389,"precision":0.0001}`, want: "48.856944, 2.351389"},
+		{name: "Day", valueType: "time", value: `{"time":"+1879-03-14T00:00:00Z","precision":11}`, want: "1879-03-14"},
+		{name: "Month", valueType: "time", value: `{"time":"+2009-11-00T00:00:00Z","precision":10}`, want: "2009-11"},
+		{name: "Year", valueType: "time", value: `{"time":"+0800-00-00T00:00:00Z","precision":9}`, want: "800"},
+		{name: "Before the common era", valueType: "time", value: `{"time":"-0044-03-15T00:00:00Z","precision":11}`, want: "44 BCE"},
+		{name: "Unsupported type", valueType: "unknown", value: `{}`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s WikidataStatement
+			s.Mainsnak.Snaktype = "value"
+			s.Mainsnak.Datavalue.Type = tt.valueType
+			s.Mainsnak.Datavalue.Value = json.RawMessage(tt.value)
+
+			assert.Equal(t, tt.want, wikidataValue(s, label))
+		})
+	}
+}
+
+func TestBestStatements(t *testing.T) {
+	statement := func(snaktype, rank string) WikidataStatement {
+		var s WikidataStatement
+		s.Mainsnak.Snaktype = snaktype
+		s.Rank = rank
+		return s
+	}
+
+	preferred := statement("value", "preferred")
+	normal := statement("value", "normal")
+	deprecated := statement("value", "deprecated")
+	unknown := statement("somevalue", "preferred")
+
+	assert.Equal(t, []WikidataStatement{preferred}, bestStatements([]WikidataStatement{normal, preferred, deprecated}))
+	assert.Equal(t, []WikidataStatement{normal}, bestStatements([]WikidataStatement{unknown, normal, deprecated}))
+	assert.Empty(t, bestStatements([]WikidataStatement{deprecated, unknown}))
+}
@@ -2,14 +2,31 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
+	// maxExtractContinuations is the maximum number of requests following 'excontinue' for a single extract
+	maxExtractContinuations = 10
+
+	// maxExtractsBatch is the maximum number of pages whose intro is extracted in a single request
+	maxExtractsBatch = 20
+
+	// maxTitlesBatch is the maximum number of titles requested at once from the Query API by the clients
+	maxTitlesBatch = 50
+
+	// maxQueryLimit is the maximum number of results per request of the Query API lists for the clients
+	maxQueryLimit = 500
+
 	// defaultUserAgent is the http User-Agent used by default.
 	//
 	// The API etiquette of the MediaWiki API ask clients to provide an informative User-Agent.
@@ -19,6 +36,9 @@ const (
 	defaultUserAgent = "wpdia-go/" + version + " (github.com/lescactus/wpdia-go) WikiClient/" + version
 )
 
+// ErrPageNotFound is returned when the search for a page doesn't match anything
+var ErrPageNotFound = errors.New("no page found on Wikipedia for the given query")
+
 // WikiClient represents the API client
 type WikiClient struct {
 	BaseURL   *url.URL
@@ -73,28 +93,646 @@ func (w *WikiClient) GetExtract(id uint64) (*WikiTextExtractResponse, error) {
 
 	logger.Debug("Http request parameters set", slog.Any("params", params))
 
-	return w.do(params)
+	return w.doContinued(params)
 }
 
-// GetExtractRandom will invoke the Wikipedia's Random API to fetch the content of a random article.
+// GetExtractRandom will invoke the Wikipedia's Random API to pick a random article,
+// and then the TextExtracts's API to fetch its content like GetExtract.
+// The random article is picked first, so that the continuations of its extract don't pick another one.
 // It takes no argument and will return the response or any error encountered.
 func (w *WikiClient) GetExtractRandom() (*WikiTextExtractResponse, error) {
-	logger.Debug("Setting http request parameters...")
+	id, err := w.RandomPageID()
+	if err != nil {
+		return nil, err
+	}
 
-	params := wikiExtractRequestParamsBuilder(exintro)
+	return w.GetExtract(id)
+}
 
-	// When requesting a random page,
-	// 'genarator=random' parameter must be set
-	// 'genarator=random' provide a set of random pages
-	params.Add("generator", "random")
+// RandomPageID will invoke the Wikipedia's Random API to pick a random article.
+// It returns the page id of the article or any error encountered.
+func (w *WikiClient) RandomPageID() (uint64, error) {
+	params := url.Values{}
+	params.Add("list", "random")
 	// Namespace 0 is 'Articles'. ref: https://www.mediawiki.org/wiki/Manual:Namespace
-	params.Add("grnnamespace", "0")
+	params.Add("rnnamespace", "0")
 	// Limit to only 1 random page returned
-	params.Add("grnlimit", "1")
+	params.Add("rnlimit", "1")
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var r struct {
+		Query struct {
+			Random []struct {
+				ID    uint64 `json:"id"`
+				Title string `json:"title"`
+			} `json:"random"`
+		} `json:"query"`
+		Error *struct {
+			Code string `json:"code"`
+			Info string `json:"info"`
+		} `json:"error"`
+	}
+	if err := w.getJSON(params, &r); err != nil {
+		return 0, fmt.Errorf("failed to pick a random page: %w", err)
+	}
+	if r.Error != nil {
+		return 0, fmt.Errorf("failed to pick a random page: %s", r.Error.Info)
+	}
+	if len(r.Query.Random) == 0 {
+		return 0, ErrPageNotFound
+	}
+
+	logger.Info("Random page picked", slog.String("title", r.Query.Random[0].Title), slog.Uint64("pageid", r.Query.Random[0].ID))
+
+	return r.Query.Random[0].ID, nil
+}
+
+// GetExtracts will invoke the Wikipedia's TextExtracts's API to extract the plain text intro of the given page ids,
+// in a single request. At most maxExtractsBatch pages can be requested at once.
+// It returns the intros by page id, without the pages which have none, or any error encountered.
+func (w *WikiClient) GetExtracts(ids []uint64) (map[uint64]string, error) {
+	if len(ids) > maxExtractsBatch {
+		return nil, fmt.Errorf("can't extract more than %d pages at once, got %d", maxExtractsBatch, len(ids))
+	}
+
+	pageids := make([]string, len(ids))
+	for i, id := range ids {
+		pageids[i] = strconv.FormatUint(id, 10)
+	}
+
+	params := url.Values{}
+	params.Add("prop", "extracts")
+	params.Add("explaintext", "1")
+	params.Add("exintro", "1")
+	params.Add("exlimit", "max")
+	params.Add("pageids", strings.Join(pageids, "|"))
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	r, err := w.doContinued(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract the pages %v: %w", ids, err)
+	}
+
+	extracts := make(map[uint64]string, len(r.Query.Pages))
+	for _, p := range r.Query.Pages {
+		if p.Pageid != nil && p.Extract != "" {
+			extracts[uint64(*p.Pageid)] = p.Extract
+		}
+	}
+
+	return extracts, nil
+}
+
+// GetSections will invoke the Wikipedia's TextExtracts's API to list the sections of the given page id.
+// It takes in argument the page id to request and will return the sections of the page or any error encountered.
+func (w *WikiClient) GetSections(id uint64) ([]Section, error) {
+	logger.Debug("Setting http request parameters...")
+
+	// Request the whole article with the wikitext-style headings,
+	// which are then parsed to build the list of sections
+	params := url.Values{}
+	params.Add("explaintext", "1")
+	params.Add("exsectionformat", "wiki")
+	params.Add("prop", "extracts")
+	params.Add("pageids", fmt.Sprintf("%d", id))
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	extract, err := w.doContinued(params)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := extract.singlePage()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSections(page.Extract), nil
+}
+
+// GetWikitext will invoke the Wikipedia's Parse API to fetch the wikitext of the given page id.
+// It takes in argument the page id to request and will return the wikitext of the page or any error encountered.
+func (w *WikiClient) GetWikitext(id uint64) (string, error) {
+	params := url.Values{}
+	params.Add("action", "parse")
+	params.Add("pageid", fmt.Sprintf("%d", id))
+	params.Add("prop", "wikitext")
+	// The wikitext is a string instead of a {"*": "..."} object with the format version 2
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var r WikiParseResponse
+	if err := w.getJSON(params, &r); err != nil {
+		return "", fmt.Errorf("failed to get the wikitext of the page %d: %w", id, err)
+	}
+	if r.Error != nil {
+		return "", fmt.Errorf("failed to get the wikitext of the page %d: %s", id, r.Error.Info)
+	}
+
+	return r.Parse.Wikitext, nil
+}
+
+// GetHTML will invoke the Wikipedia's Parse API to fetch the rendered HTML of the given page id.
+// It takes in argument the page id to request and will return the HTML of the page or any error encountered.
+func (w *WikiClient) GetHTML(id uint64) (string, error) {
+	params := url.Values{}
+	params.Add("action", "parse")
+	params.Add("pageid", fmt.Sprintf("%d", id))
+	params.Add("prop", "text")
+	params.Add("disableeditsection", "1")
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var r WikiParseResponse
+	if err := w.getJSON(params, &r); err != nil {
+		return "", fmt.Errorf("failed to get the HTML of the page %d: %w", id, err)
+	}
+	if r.Error != nil {
+		return "", fmt.Errorf("failed to get the HTML of the page %d: %s", id, r.Error.Info)
+	}
+
+	return r.Parse.Text, nil
+}
+
+// GetCategories will invoke the Wikipedia's Query API to list the categories of the given page id.
+// The hidden categories, ie. the maintenance ones, are only listed when hidden is true.
+// It returns at most limit categories, or all of them when limit is 0, or any error encountered.
+func (w *WikiClient) GetCategories(id uint64, hidden bool, limit int) ([]PageRef, error) {
+	params := url.Values{}
+	params.Add("prop", "categories")
+	params.Add("pageids", fmt.Sprintf("%d", id))
+	params.Add("clprop", "hidden")
+	params.Add("cllimit", queryLimit(limit))
+	if !hidden {
+		params.Add("clshow", "!hidden")
+	}
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var categories []PageRef
+	err := w.paginate(params, limit, func(query json.RawMessage) (int, error) {
+		var q struct {
+			Pages []struct {
+				Categories []PageRef `json:"categories"`
+			} `json:"pages"`
+		}
+		if err := json.Unmarshal(query, &q); err != nil {
+			return 0, err
+		}
+		for _, p := range q.Pages {
+			categories = append(categories, p.Categories...)
+		}
+		return len(categories), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the categories of the page %d: %w", id, err)
+	}
+
+	return truncateRefs(categories, limit), nil
+}
+
+// GetLinks will invoke the Wikipedia's Query API to list the pages the given page id links to.
+// Only the links to the given namespaces are listed, or to any namespace when empty.
+// It returns at most limit links, or all of them when limit is 0, or any error encountered.
+func (w *WikiClient) GetLinks(id uint64, namespaces []int, limit int) ([]PageRef, error) {
+	params := url.Values{}
+	params.Add("prop", "links")
+	params.Add("pageids", fmt.Sprintf("%d", id))
+	params.Add("pllimit", queryLimit(limit))
+	if len(namespaces) > 0 {
+		params.Add("plnamespace", namespacesParam(namespaces))
+	}
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var links []PageRef
+	err := w.paginate(params, limit, func(query json.RawMessage) (int, error) {
+		var q struct {
+			Pages []struct {
+				Links []PageRef `json:"links"`
+			} `json:"pages"`
+		}
+		if err := json.Unmarshal(query, &q); err != nil {
+			return 0, err
+		}
+		for _, p := range q.Pages {
+			links = append(links, p.Links...)
+		}
+		return len(links), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the links of the page %d: %w", id, err)
+	}
+
+	return truncateRefs(links, limit), nil
+}
+
+// GetBacklinks will invoke the Wikipedia's Query API to list the pages linking to the given page id.
+// Only the pages of the given namespaces are listed, or of any namespace when empty.
+// The redirects to the page are listed as such and, when redirects is true, they are followed:
+// the pages linking to a redirect are listed after it, through it.
+// It returns at most limit pages, or all of them when limit is 0, or any error encountered.
+func (w *WikiClient) GetBacklinks(id uint64, namespaces []int, redirects bool, limit int) ([]PageRef, error) {
+	params := url.Values{}
+	params.Add("list", "backlinks")
+	params.Add("blpageid", fmt.Sprintf("%d", id))
+	params.Add("bllimit", queryLimit(limit))
+	if len(namespaces) > 0 {
+		params.Add("blnamespace", namespacesParam(namespaces))
+	}
+	if redirects {
+		params.Add("blredirect", "1")
+	}
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var backlinks []PageRef
+	err := w.paginate(params, limit, func(query json.RawMessage) (int, error) {
+		var q struct {
+			Backlinks []struct {
+				PageRef
+				// Redirlinks are the pages linking to the redirect, with 'blredirect'
+				Redirlinks []PageRef `json:"redirlinks"`
+			} `json:"backlinks"`
+		}
+		if err := json.Unmarshal(query, &q); err != nil {
+			return 0, err
+		}
+		for _, b := range q.Backlinks {
+			backlinks = append(backlinks, b.PageRef)
+			for _, r := range b.Redirlinks {
+				r.Via = b.Title
+				backlinks = append(backlinks, r)
+			}
+		}
+		return len(backlinks), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the backlinks of the page %d: %w", id, err)
+	}
+
+	return truncateRefs(backlinks, limit), nil
+}
+
+// GetCategoryMembers will invoke the Wikipedia's Query API to list the articles and the subcategories
+// of the category with the given title, ie. "Category:Programming languages".
+// It returns at most limit members, or all of them when limit is 0, or any error encountered.
+func (w *WikiClient) GetCategoryMembers(title string, limit int) ([]PageRef, error) {
+	params := url.Values{}
+	params.Add("list", "categorymembers")
+	params.Add("cmtitle", title)
+	// The files are left out
+	params.Add("cmtype", "page|subcat")
+	params.Add("cmprop", "ids|title")
+	params.Add("cmlimit", queryLimit(limit))
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var members []PageRef
+	err := w.paginate(params, limit, func(query json.RawMessage) (int, error) {
+		var q struct {
+			Categorymembers []PageRef `json:"categorymembers"`
+		}
+		if err := json.Unmarshal(query, &q); err != nil {
+			return 0, err
+		}
+		members = append(members, q.Categorymembers...)
+		return len(members), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the members of %q: %w", title, err)
+	}
+
+	return truncateRefs(members, limit), nil
+}
+
+// GetPagesLinks will invoke the Wikipedia's Query API to list the pages each of the given titles links to,
+// in the given namespaces or any namespace when empty. The redirects are followed: the links of a redirect
+// are the ones of its target.
+// At most maxTitlesBatch titles can be requested at once.
+// It returns at most limit links by requested title, or all of them when limit is 0, or any error encountered.
+func (w *WikiClient) GetPagesLinks(titles []string, namespaces []int, limit int) (map[string]PageLinks, error) {
+	params := url.Values{}
+	if len(namespaces) > 0 {
+		params.Add("plnamespace", namespacesParam(namespaces))
+	}
+	params.Add("redirects", "1")
+
+	return w.getPagesLinks("links", "pl", titles, params, limit)
+}
+
+// GetPagesLinksHere will invoke the Wikipedia's Query API to list the pages linking to each of the given titles,
+// in the given namespaces or any namespace when empty. The redirects to a page are listed as such and aren't followed.
+// At most maxTitlesBatch titles can be requested at once.
+// It returns at most limit pages linking by requested title, or all of them when limit is 0, or any error encountered.
+func (w *WikiClient) GetPagesLinksHere(titles []string, namespaces []int, limit int) (map[string]PageLinks, error) {
+	params := url.Values{}
+	if len(namespaces) > 0 {
+		params.Add("lhnamespace", namespacesParam(namespaces))
+	}
+
+	return w.getPagesLinks("linkshere", "lh", titles, params, limit)
+}
+
+// GetPagesCategories will invoke the Wikipedia's Query API to list the categories of each of the given titles,
+// without the hidden ones. The redirects are followed. At most maxTitlesBatch titles can be requested at once.
+// It returns the categories by requested title, or any error encountered.
+func (w *WikiClient) GetPagesCategories(titles []string) (map[string]PageLinks, error) {
+	params := url.Values{}
+	params.Add("clshow", "!hidden")
+	params.Add("redirects", "1")
+
+	return w.getPagesLinks("categories", "cl", titles, params, 0)
+}
+
+// getPagesLinks will invoke the Wikipedia's Query API with the given property listing pages, either 'links',
+// 'linkshere' or 'categories', for the given titles and the additional http request parameters.
+// prefix is the prefix of the parameters of the property, ie. 'pl' for 'links'.
+// The API lists the pages of the titles one after the other, in the order of their page ids: with a limit,
+// the pagination stops once each title has either limit pages listed or all of them.
+// It returns at most limit pages listed by requested title, or all of them when limit is 0, or any error encountered.
+func (w *WikiClient) getPagesLinks(prop, prefix string, titles []string, params url.Values, limit int) (map[string]PageLinks, error) {
+	if len(titles) > maxTitlesBatch {
+		return nil, fmt.Errorf("can't request the %s of more than %d pages at once, got %d", prop, maxTitlesBatch, len(titles))
+	}
+
+	params.Add("prop", prop)
+	params.Add("titles", strings.Join(titles, "|"))
+	params.Add(prefix+"limit", queryLimit(limit*len(titles)))
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	// The pagination stops once collect returns 1, when every title is done
+	stop := 0
+	if limit > 0 {
+		stop = 1
+	}
+
+	links := map[string][]PageRef{}
+	ids := map[string]uint64{}
+	var resolved titleResolver
+	err := w.paginate(params, stop, func(query json.RawMessage) (int, error) {
+		var q struct {
+			titleResolver
+			Pages []struct {
+				Pageid     uint64    `json:"pageid"`
+				Title      string    `json:"title"`
+				Links      []PageRef `json:"links"`
+				Linkshere  []PageRef `json:"linkshere"`
+				Categories []PageRef `json:"categories"`
+			} `json:"pages"`
+		}
+		if err := json.Unmarshal(query, &q); err != nil {
+			return 0, err
+		}
+
+		resolved.merge(q.titleResolver)
+
+		// The pages before the last one listing pages in this response are complete
+		var last uint64
+		for _, p := range q.Pages {
+			ids[p.Title] = p.Pageid
+			if len(p.Links)+len(p.Linkshere)+len(p.Categories) > 0 {
+				last = max(last, p.Pageid)
+			}
+
+			links[p.Title] = append(links[p.Title], p.Links...)
+			links[p.Title] = append(links[p.Title], p.Linkshere...)
+			links[p.Title] = append(links[p.Title], p.Categories...)
+		}
+
+		for title, id := range ids {
+			// A missing page has no page id
+			if id != 0 && id >= last && len(links[title]) < limit {
+				return 0, nil
+			}
+		}
+		return 1, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the %s of the pages %q: %w", prop, titles, err)
+	}
+
+	pages := make(map[string]PageLinks, len(titles))
+	for _, t := range titles {
+		title := resolved.resolve(t)
+		pages[t] = PageLinks{Title: title, Links: truncateRefs(links[title], limit)}
+	}
+
+	return pages, nil
+}
+
+// GetShortDescriptions will invoke the Wikipedia's Query API to get the short description of each of the given titles.
+// The redirects are followed. At most maxTitlesBatch titles can be requested at once.
+// It returns the short descriptions by requested title, without the pages which have none, or any error encountered.
+func (w *WikiClient) GetShortDescriptions(titles []string) (map[string]string, error) {
+	if len(titles) > maxTitlesBatch {
+		return nil, fmt.Errorf("can't request the short description of more than %d pages at once, got %d", maxTitlesBatch, len(titles))
+	}
+
+	params := url.Values{}
+	params.Add("prop", "pageprops")
+	params.Add("ppprop", "wikibase-shortdesc")
+	params.Add("titles", strings.Join(titles, "|"))
+	params.Add("redirects", "1")
+	params.Add("formatversion", "2")
 
 	logger.Debug("Http request parameters set", slog.Any("params", params))
 
-	return w.do(params)
+	descriptions := map[string]string{}
+	var resolved titleResolver
+	err := w.paginate(params, 0, func(query json.RawMessage) (int, error) {
+		var q struct {
+			titleResolver
+			Pages []Page `json:"pages"`
+		}
+		if err := json.Unmarshal(query, &q); err != nil {
+			return 0, err
+		}
+
+		resolved.merge(q.titleResolver)
+		for _, p := range q.Pages {
+			if p.PageProps != nil && p.PageProps.WikiBaseShortDesc != "" {
+				descriptions[p.Title] = p.PageProps.WikiBaseShortDesc
+			}
+		}
+		return 0, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the short descriptions of the pages %q: %w", titles, err)
+	}
+
+	byTitle := make(map[string]string, len(titles))
+	for _, t := range titles {
+		if d, ok := descriptions[resolved.resolve(t)]; ok {
+			byTitle[t] = d
+		}
+	}
+
+	return byTitle, nil
+}
+
+// GetCoordinates will invoke the Wikipedia's Query API to get the primary coordinates of the given page id.
+// Documentation is found here: https://www.mediawiki.org/wiki/Extension:GeoData#prop=coordinates
+// It returns nil if the page has no coordinates, or any error encountered.
+func (w *WikiClient) GetCoordinates(id uint64) (*WikiCoordinates, error) {
+	params := url.Values{}
+	params.Add("prop", "coordinates")
+	params.Add("coprop", "globe")
+	params.Add("pageids", strconv.FormatUint(id, 10))
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var coordinates *WikiCoordinates
+	err := w.paginate(params, 0, func(query json.RawMessage) (int, error) {
+		var q struct {
+			Pages []Page `json:"pages"`
+		}
+		if err := json.Unmarshal(query, &q); err != nil {
+			return 0, err
+		}
+		for _, p := range q.Pages {
+			if len(p.Coordinates) > 0 && coordinates == nil {
+				coordinates = &p.Coordinates[0]
+			}
+		}
+		return 0, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the coordinates of the page %d: %w", id, err)
+	}
+
+	return coordinates, nil
+}
+
+// GeoSearch will invoke the Wikipedia's Query API to list the articles within radius meters
+// of the given latitude and longitude, nearest first.
+// Documentation is found here: https://www.mediawiki.org/wiki/Extension:GeoData#list=geosearch
+// It returns at most limit articles, or as many as the API allows when limit is 0, or any error encountered.
+func (w *WikiClient) GeoSearch(lat, lon float64, radius, limit int) ([]NearbyPage, error) {
+	params := url.Values{}
+	params.Add("list", "geosearch")
+	params.Add("gscoord", strconv.FormatFloat(lat, 'f', -1, 64)+"|"+strconv.FormatFloat(lon, 'f', -1, 64))
+	params.Add("gsradius", strconv.Itoa(radius))
+	params.Add("gsnamespace", "0")
+	params.Add("gslimit", queryLimit(limit))
+	params.Add("formatversion", "2")
+
+	logger.Debug("Http request parameters set", slog.Any("params", params))
+
+	var pages []NearbyPage
+	err := w.paginate(params, limit, func(query json.RawMessage) (int, error) {
+		var q struct {
+			Geosearch []NearbyPage `json:"geosearch"`
+		}
+		if err := json.Unmarshal(query, &q); err != nil {
+			return 0, err
+		}
+		pages = append(pages, q.Geosearch...)
+		return len(pages), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search the articles around %g, %g: %w", lat, lon, err)
+	}
+
+	if limit > 0 && len(pages) > limit {
+		pages = pages[:limit]
+	}
+
+	return pages, nil
+}
+
+// paginate will invoke the Query API with the given http request parameters, and then again with the
+// 'continue' parameters of each response, as long as there are more results to return.
+// The query of each response is given to collect, which returns the number of results collected so far:
+// the pagination stops once it reaches limit, unless limit is 0.
+// Documentation is found here: https://www.mediawiki.org/wiki/API:Continue
+func (w *WikiClient) paginate(params url.Values, limit int, collect func(query json.RawMessage) (int, error)) error {
+	// The parameters are modified when building the request
+	base := maps.Clone(params)
+
+	for {
+		var r WikiContinuedResponse
+		if err := w.getJSON(params, &r); err != nil {
+			return err
+		}
+		if r.Error != nil {
+			return errors.New(r.Error.Info)
+		}
+
+		if len(r.Query) > 0 {
+			n, err := collect(r.Query)
+			if err != nil {
+				return err
+			}
+			if limit > 0 && n >= limit {
+				return nil
+			}
+		}
+
+		if len(r.Continue) == 0 {
+			return nil
+		}
+
+		logger.Debug("Results incomplete, continuing...", slog.Any("continue", r.Continue))
+
+		params = maps.Clone(base)
+		for k, v := range r.Continue {
+			params.Set(k, continueValue(v))
+		}
+	}
+}
+
+// continueValue returns the given 'continue' value as a http request parameter.
+// The value is either a string or a number.
+func continueValue(v json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return s
+	}
+
+	return string(v)
+}
+
+// queryLimit returns the number of results to request per page for the given limit:
+// the limit itself when lower than the maximum of the API, "max" otherwise.
+func queryLimit(limit int) string {
+	if limit > 0 && limit < maxQueryLimit {
+		return strconv.Itoa(limit)
+	}
+
+	return "max"
+}
+
+// namespacesParam returns the given namespaces as a http request parameter, ie. "0|14".
+func namespacesParam(namespaces []int) string {
+	values := make([]string, len(namespaces))
+	for i, ns := range namespaces {
+		values[i] = strconv.Itoa(ns)
+	}
+
+	return strings.Join(values, "|")
+}
+
+// truncateRefs returns the first limit pages of refs, or all of them when limit is 0.
+func truncateRefs(refs []PageRef, limit int) []PageRef {
+	if limit > 0 && len(refs) > limit {
+		return refs[:limit]
+	}
+
+	return refs
 }
 
 // do will build a http request with the given http request parameters as arguments,
@@ -104,12 +742,75 @@ func (w *WikiClient) GetExtractRandom() (*WikiTextExtractResponse, error) {
 //
 // The function takes as argument a set of url query parameters and will return the response or any error encountered.
 func (w *WikiClient) do(params url.Values) (*WikiTextExtractResponse, error) {
+	var r WikiTextExtractResponse
+	if err := w.getJSON(params, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// doContinued will execute the TextExtracts request with the given http request parameters like do,
+// and then request the missing extracts again as long as the response has an 'excontinue' value.
+// The extracts of all the responses are merged into the first one.
+// It returns an error after maxExtractContinuations requests.
+func (w *WikiClient) doContinued(params url.Values) (*WikiTextExtractResponse, error) {
+	// The parameters are modified when building the request
+	base := maps.Clone(params)
+
+	r, err := w.do(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; r.Continue.Excontinue != nil; i++ {
+		if i == maxExtractContinuations {
+			return nil, fmt.Errorf("extract still incomplete after %d requests", maxExtractContinuations)
+		}
+
+		logger.Debug("Extract incomplete, continuing...", slog.Int("excontinue", *r.Continue.Excontinue))
+
+		next := maps.Clone(base)
+		next.Set("excontinue", strconv.Itoa(*r.Continue.Excontinue))
+		next.Set("continue", r.Continue.Continue)
+
+		c, err := w.do(next)
+		if err != nil {
+			return nil, err
+		}
+
+		// The pages already extracted are returned again without their extract
+		for id, p := range c.Query.Pages {
+			existing, ok := r.Query.Pages[id]
+			if !ok {
+				r.Query.Pages[id] = p
+				continue
+			}
+			if existing.Extract == "" {
+				existing.Extract = p.Extract
+				r.Query.Pages[id] = existing
+			}
+		}
+		r.Continue = c.Continue
+	}
+
+	return r, nil
+}
+
+// getJSON will build a http request with the given http request parameters as arguments,
+// execute it and unmarshal the response into v.
+// It will use the embedded BaseURL and User-Agent.
+// It will take care of reading the body response and to close it.
+//
+// The function takes as argument a set of url query parameters and the value to unmarshal into.
+// It returns any error encountered.
+func (w *WikiClient) getJSON(params url.Values, v any) error {
 	logger.Debug("Building http request...", slog.Any("params", params), slog.String("url", w.BaseURL.String()), slog.String("user-agent", w.UserAgent))
 
 	// Build http request
 	req, err := wikiRequestBuilder(params, w.BaseURL.String(), w.UserAgent)
 	if err != nil {
-		return nil, fmt.Errorf("error while building http request: %v", err)
+		return fmt.Errorf("error while building http request: %v", err)
 	}
 	logger.Debug("Http request built", slog.Any("params", params), slog.String("url", w.BaseURL.String()), slog.String("user-agent", w.UserAgent))
 
@@ -117,28 +818,26 @@ func (w *WikiClient) do(params url.Values) (*WikiTextExtractResponse, error) {
 	// Execute the http request
 	resp, err := w.Client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	logger.Debug("Http request sent")
 
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	logger.Debug("Reading http response body and unmarshalling...")
 
-	var r WikiTextExtractResponse
-	err = json.Unmarshal(body, &r)
+	err = json.Unmarshal(body, v)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	logger.Debug("Http response body read and unmarshalled")
 
-	return &r, nil
-
+	return nil
 }
 
 // SearchTitle will invoke the Wikipedia's Search API to lookup for the given title.
@@ -146,67 +845,118 @@ func (w *WikiClient) do(params url.Values) (*WikiTextExtractResponse, error) {
 // result if found. If the search doesn't return any result, the function return 0 or
 // any error encountered.
 func (w *WikiClient) SearchTitle(title string) (uint64, error) {
+	// We only care about the first result of the search
+	// which should match what we are searching for
+	results, err := w.Search(title, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	// results will be empty if the search doesn't match anything
+	if len(results) == 0 {
+		logger.Warn("Search didn't match anything")
+		return 0, nil
+	}
+
+	logger.Info("Search found a Page ID", slog.Uint64("pageid", results[0].Pageid))
+
+	// We only care about the first result
+	return results[0].Pageid, nil
+}
+
+// FindPage will invoke the Wikipedia's Search API to lookup for the page best matching the given title.
+// It returns the first search result, ErrPageNotFound if the search doesn't match anything,
+// or any error encountered.
+func (w *WikiClient) FindPage(title string) (*WikiSearchResult, error) {
+	results, err := w.Search(title, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrPageNotFound
+	}
+
+	logger.Info("Search found a page", slog.String("title", results[0].Title), slog.Uint64("pageid", results[0].Pageid))
+
+	return &results[0], nil
+}
+
+// Search will invoke the Wikipedia's Search API to lookup for the given query.
+// It takes in argument the query to search for and the maximum number of results to return.
+// It returns the search results, which may be empty, or any error encountered.
+func (w *WikiClient) Search(query string, limit int) ([]WikiSearchResult, error) {
 	params := url.Values{}
 
 	// Documentation about the search API: https://www.mediawiki.org/wiki/API:Search
 	//
 	// "srsearch" will search for page titles or page content
 	// matching the given value.
-	//
-	// We only care about the first result of the search
-	// which should match what we are searching for
-	params.Add("srlimit", "1")
+	params.Add("srlimit", strconv.Itoa(limit))
 	params.Add("list", "search")
 	params.Add("utf8", "1")
-	params.Add("srsearch", title)
+	params.Add("srsearch", query)
 
 	logger.Debug("Http request parameters set", slog.Any("params", params))
 
-	logger.Debug("Building http request...", slog.Any("params", params), slog.String("url", w.BaseURL.String()), slog.String("user-agent", w.UserAgent))
-
-	// Build http request
-	req, err := wikiRequestBuilder(params, w.BaseURL.String(), w.UserAgent)
-	if err != nil {
-		return 0, fmt.Errorf("error while building http request: %v", err)
+	var s WikiSearchResponse
+	if err := w.getJSON(params, &s); err != nil {
+		return nil, fmt.Errorf("failed to search %q: %w", query, err)
 	}
 
-	logger.Debug("Http request built", slog.Any("params", params), slog.String("url", w.BaseURL.String()), slog.String("user-agent", w.UserAgent))
+	return s.Query.Search, nil
+}
 
-	logger.Debug("Sending http request...")
+// GetPage will search for the given title and return the page of the first result,
+// with its text extract and its properties.
+// It returns ErrPageNotFound if the search doesn't match anything, or any error encountered.
+func (w *WikiClient) GetPage(title string) (*Page, error) {
+	logger.Info("Searching title...", slog.String("title", title))
 
-	resp, err := w.Client.Do(req)
+	// Get the id of the page requested
+	id, err := w.SearchTitle(title)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	logger.Debug("Http request sent...")
+	// If the search was unsuccessful
+	if id == 0 {
+		return nil, ErrPageNotFound
+	}
 
-	logger.Debug("Reading http response body and unmarshalling..")
+	logger.Debug("Title found")
 
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	// Call the TextExtracts API for the requested page id
+	extract, err := w.GetExtract(id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	var s WikiSearchResponse
-	err = json.Unmarshal(body, &s)
+	return fetchedPage(extract)
+}
+
+// GetPageRandom will return a random page,
+// with its text extract and its properties.
+// It returns the page or any error encountered.
+func (w *WikiClient) GetPageRandom() (*Page, error) {
+	// Call the Random API
+	extract, err := w.GetExtractRandom()
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshall response body: %w", err)
+		return nil, err
 	}
 
-	logger.Debug("Http response body read and unmarshalled")
+	return fetchedPage(extract)
+}
 
-	// Query.Search[] will be empty if the search doesn't match anything
-	if len(s.Query.Search) == 0 {
-		logger.Warn("Search didn't match anything")
-		return 0, nil
+// fetchedPage returns the only page of the given response, marked as fetched now.
+func fetchedPage(extract *WikiTextExtractResponse) (*Page, error) {
+	page, err := extract.singlePage()
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Info("Search found a Page ID", slog.Uint64("pageid", s.Query.Search[0].Pageid))
+	page.FetchedAt = time.Now().UTC()
 
-	// We only care about the first result
-	return s.Query.Search[0].Pageid, nil
+	return page, nil
 }
 
 // wikiRequestBuilder is used to build a http request to the Wikipedia's API.
@@ -218,8 +968,11 @@ func (w *WikiClient) SearchTitle(title string) (uint64, error) {
 // The function takes as argument a set of url query parameters, the base URL and the User-Agent.
 // It returns a *http.Request or any error encountered.
 func wikiRequestBuilder(params url.Values, baseURL, userAgent string) (*http.Request, error) {
-	// Common parameters for each requests to Wikipedia API
-	params.Add("action", "query")
+	// Common parameters for each requests to Wikipedia API.
+	// The action is 'query' unless another one is set, ie. 'wbgetentities' for Wikidata.
+	if !params.Has("action") {
+		params.Add("action", "query")
+	}
 	params.Add("format", "json")
 
 	// URL encode the parameters
@@ -255,16 +1008,29 @@ func wikiExtractRequestParamsBuilder(exintro bool) url.Values {
 	params := url.Values{}
 
 	params.Add("explaintext", "1")
-	params.Add("exsectionformat", "plain")
-	params.Add("prop", "extracts|pageprops")
+	// The wikitext-style section headings ("== History ==") are distinguishable from the text,
+	// so the sections can be parsed. They are turned into plain text headings by the view.
+	params.Add("exsectionformat", "wiki")
+	params.Add("prop", "extracts|pageprops|info|revisions")
+	params.Add("inprop", "url|displaytitle|protection|watchers")
+	// Only the last revision is returned when no revision is selected
+	params.Add("rvprop", "ids|timestamp|user")
 
-	// 'exintro' is mutually exclusive with 'exsentences'
-	// Either we return only the content before the first section
-	// or we return a given number of sentences
+	// The coordinates are only requested when rendered
+	if withCoords || isPresent(fields, "coordinates") {
+		params.Set("prop", params.Get("prop")+"|coordinates")
+		params.Add("coprop", "globe")
+	}
+
+	// Either we return only the content before the first section, or the whole article.
+	// 'exsentences' isn't reliable, the sentences of the whole article are split locally instead.
 	if exintro {
 		params.Add("exintro", "1")
-	} else {
-		params.Add("exsentences", exsentences)
+	}
+
+	// 'exchars' limits either the content before the first section or the whole article
+	if exchars > 0 {
+		params.Add("exchars", strconv.Itoa(exchars))
 	}
 
 	return params
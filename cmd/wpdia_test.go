@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -32,6 +36,13 @@ var (
 			WikiBaseShortDesc: "WikiBaseShortDesc",
 			WikiBaseItem:      "WikiBaseItem",
 		},
+
+		DisplayTitle: "<span class=\"mw-page-title-main\">Golang</span>",
+		Length:       81519,
+		Touched:      time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC),
+		LastRevID:    1211970426,
+		Protection:   []WikiProtection{{Type: "edit", Level: "autoconfirmed", Expiry: "infinity"}},
+		Revisions:    []WikiRevision{{RevID: 1211970426, User: "Gopher", Timestamp: time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)}},
 	}
 )
 
@@ -100,6 +111,34 @@ func TestWikiRequestBuilder(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			desc: "Action set",
+			args: args{
+				params: url.Values{
+					"action": {"wbgetentities"},
+					"ids":    {"Q37227"},
+				},
+				baseURL:   "https://api.example.com",
+				userAgent: "Custom/User-Agent",
+			},
+			want: &http.Request{
+				Method: "GET",
+				Host:   "api.example.com",
+				URL: &url.URL{
+					Scheme:   "https",
+					Host:     "api.example.com",
+					RawQuery: "action=wbgetentities&format=json&ids=Q37227",
+				},
+				Header: map[string][]string{
+					"User-Agent":   {"Custom/User-Agent"},
+					"Content-Type": {"multipart/form-data"},
+				},
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+			},
+			wantErr: false,
+		},
 		{
 			desc: "Empty User Agent",
 			args: args{
@@ -234,7 +273,9 @@ func TestNewWikiClient(t *testing.T) {
 
 func TestWikiExtractRequestParamsBuilder(t *testing.T) {
 	type args struct {
-		exintro bool
+		exintro    bool
+		exchars    int
+		withCoords bool
 	}
 	tests := []struct {
 		name string
@@ -246,27 +287,533 @@ func TestWikiExtractRequestParamsBuilder(t *testing.T) {
 			args: args{exintro: true},
 			want: url.Values{
 				"explaintext":     []string{"1"},
-				"exsectionformat": []string{"plain"},
-				"prop":            []string{"extracts|pageprops"},
+				"exsectionformat": []string{"wiki"},
+				"prop":            []string{"extracts|pageprops|info|revisions"},
+				"inprop":          []string{"url|displaytitle|protection|watchers"},
+				"rvprop":          []string{"ids|timestamp|user"},
 				"exintro":         []string{"1"},
 			},
 		},
 		{
-			name: "Exintro set to false",
+			name: "Whole article",
 			args: args{exintro: false},
 			want: url.Values{
 				"explaintext":     []string{"1"},
-				"exsectionformat": []string{"plain"},
-				"prop":            []string{"extracts|pageprops"},
-				"exsentences":     []string{"10"},
+				"exsectionformat": []string{"wiki"},
+				"prop":            []string{"extracts|pageprops|info|revisions"},
+				"inprop":          []string{"url|displaytitle|protection|watchers"},
+				"rvprop":          []string{"ids|timestamp|user"},
+			},
+		},
+		{
+			name: "Exchars with exintro",
+			args: args{exintro: true, exchars: 300},
+			want: url.Values{
+				"explaintext":     []string{"1"},
+				"exsectionformat": []string{"wiki"},
+				"prop":            []string{"extracts|pageprops|info|revisions"},
+				"inprop":          []string{"url|displaytitle|protection|watchers"},
+				"rvprop":          []string{"ids|timestamp|user"},
+				"exintro":         []string{"1"},
+				"exchars":         []string{"300"},
+			},
+		},
+		{
+			name: "Exchars without exintro",
+			args: args{exintro: false, exchars: 300},
+			want: url.Values{
+				"explaintext":     []string{"1"},
+				"exsectionformat": []string{"wiki"},
+				"prop":            []string{"extracts|pageprops|info|revisions"},
+				"inprop":          []string{"url|displaytitle|protection|watchers"},
+				"rvprop":          []string{"ids|timestamp|user"},
+				"exchars":         []string{"300"},
+			},
+		},
+		{
+			name: "Coordinates",
+			args: args{exintro: true, withCoords: true},
+			want: url.Values{
+				"explaintext":     []string{"1"},
+				"exsectionformat": []string{"wiki"},
+				"prop":            []string{"extracts|pageprops|info|revisions|coordinates"},
+				"inprop":          []string{"url|displaytitle|protection|watchers"},
+				"rvprop":          []string{"ids|timestamp|user"},
+				"coprop":          []string{"globe"},
+				"exintro":         []string{"1"},
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			exchars, withCoords = tt.args.exchars, tt.args.withCoords
+			t.Cleanup(func() { exchars, withCoords = 0, false })
+
 			got := wikiExtractRequestParamsBuilder(tt.args.exintro)
 
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+// newStubWikiAPI starts a http server mimicking the subset of the Wikipedia's API used by the WikiClient.
+// The search for "nothing" doesn't match any page, any other search matches the 'page' fixture.
+func newStubWikiAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		switch {
+		case q.Get("action") == "parse" && q.Get("prop") == "text":
+			fmt.Fprint(w, `{"parse":{"title":"Go","pageid":1,"text":"<div class=\"mw-parser-output\"><table class=\"wikitable\"><tr><td>Go</td></tr></table></div>"}}`)
+		case q.Get("action") == "parse":
+			if q.Get("pageid") != fmt.Sprint(*page.Pageid) {
+				fmt.Fprint(w, `{"error":{"code":"nosuchpageid","info":"There is no page with ID 404."}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"parse":{"title":"%s","pageid":%d,"wikitext":"{{Infobox programming language\n| developer = [[Google]]\n}}\n'''Go''' is a language."}}`, page.Title, *page.Pageid)
+		case q.Get("list") == "search":
+			if q.Get("srsearch") == "nothing" {
+				fmt.Fprint(w, `{"batchcomplete":"","query":{"search":[]}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"batchcomplete":"","query":{"search":[{"ns":0,"title":"%s","pageid":%d,"snippet":"<span class=\"searchmatch\">Go</span> is a language"},{"ns":0,"title":"Go (game)","pageid":12,"snippet":"Go is a game"}]}}`, page.Title, *page.Pageid)
+		case q.Get("list") == "random":
+			assert.Equal(t, "0", q.Get("rnnamespace"))
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"random":[{"id":42,"ns":0,"title":"Random"}]}}`)
+		case q.Get("pageids") == "42":
+			fmt.Fprint(w, `{"batchcomplete":"","query":{"pages":{"42":{"pageid":42,"ns":0,"title":"Random","extract":"A random page."}}}}`)
+		case q.Has("pageids") && !q.Has("exintro"):
+			// Whole article
+			fmt.Fprintf(w, `{"batchcomplete":"","query":{"pages":{"%[1]d":{"pageid":%[1]d,"ns":0,"title":"%[2]s","extract":"Go is a language.\n\n\n== History ==\nHistory.\n\n\n=== Naming ===\nNaming."}}}}`, *page.Pageid, page.Title)
+		case q.Get("pageids") != "":
+			b, _ := json.Marshal(map[string]any{"query": map[string]any{"pages": map[string]Page{q.Get("pageids"): page}}})
+			w.Write(b)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestWikiClientSearch(t *testing.T) {
+	ts := newStubWikiAPI(t)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	results, err := w.Search("golang", 2)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, page.Title, results[0].Title)
+	assert.Equal(t, uint64(*page.Pageid), results[0].Pageid)
+
+	results, err = w.Search("nothing", 2)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestWikiClientFindPage(t *testing.T) {
+	ts := newStubWikiAPI(t)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.FindPage("golang")
+	assert.NoError(t, err)
+	assert.Equal(t, page.Title, got.Title)
+	assert.Equal(t, uint64(*page.Pageid), got.Pageid)
+
+	_, err = w.FindPage("nothing")
+	assert.ErrorIs(t, err, ErrPageNotFound)
+}
+
+func TestWikiClientGetPage(t *testing.T) {
+	ts := newStubWikiAPI(t)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetPage("golang")
+	assert.NoError(t, err)
+	assert.Equal(t, page.Title, got.Title)
+	assert.Equal(t, page.Extract, got.Extract)
+
+	_, err = w.GetPage("nothing")
+	assert.ErrorIs(t, err, ErrPageNotFound)
+
+	got, err = w.GetPageRandom()
+	assert.NoError(t, err)
+	assert.Equal(t, "Random", got.Title)
+}
+
+func TestWikiClientGetExtractContinued(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+
+		assert.Equal(t, []string{"query"}, q["action"])
+
+		switch q.Get("excontinue") {
+		case "":
+			// The extract is too large to be part of the first response
+			fmt.Fprint(w, `{"continue":{"excontinue":0,"continue":"||"},"query":{"pages":{"1":{"pageid":1,"ns":0,"title":"Go"}}}}`)
+		case "0":
+			assert.Equal(t, "||", q.Get("continue"))
+			fmt.Fprint(w, `{"batchcomplete":"","query":{"pages":{"1":{"pageid":1,"ns":0,"title":"Go","extract":"Go is a language."}}}}`)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetExtract(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, "Go is a language.", got.Query.Pages["1"].Extract)
+	assert.Nil(t, got.Continue.Excontinue)
+}
+
+func TestWikiClientGetExtractRandomContinued(t *testing.T) {
+	var picks, requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+
+		switch {
+		case q.Get("list") == "random":
+			picks++
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"random":[{"id":1,"ns":0,"title":"Go"}]}}`)
+		case q.Get("pageids") == "1" && q.Get("excontinue") == "":
+			assert.False(t, q.Has("generator"))
+			fmt.Fprint(w, `{"continue":{"excontinue":0,"continue":"||"},"query":{"pages":{"1":{"pageid":1,"ns":0,"title":"Go"}}}}`)
+		case q.Get("pageids") == "1" && q.Get("excontinue") == "0":
+			fmt.Fprint(w, `{"batchcomplete":"","query":{"pages":{"1":{"pageid":1,"ns":0,"title":"Go","extract":"Go is a language."}}}}`)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	// The continuation requests the extract of the same article, rather than picking another one
+	got, err := w.GetExtractRandom()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, picks)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, "Go is a language.", got.Query.Pages["1"].Extract)
+}
+
+func TestWikiClientGetSections(t *testing.T) {
+	ts := newStubWikiAPI(t)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetSections(uint64(*page.Pageid))
+	assert.NoError(t, err)
+	assert.Equal(t, []Section{
+		{Index: 1, Level: 2, Title: "History", Anchor: "History"},
+		{Index: 2, Level: 3, Title: "Naming", Anchor: "Naming"},
+	}, got)
+}
+
+func TestWikiClientGetWikitext(t *testing.T) {
+	ts := newStubWikiAPI(t)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetWikitext(uint64(*page.Pageid))
+	assert.NoError(t, err)
+	assert.Equal(t, "{{Infobox programming language\n| developer = [[Google]]\n}}\n'''Go''' is a language.", got)
+
+	_, err = w.GetWikitext(404)
+	assert.ErrorContains(t, err, "There is no page with ID 404.")
+}
+
+func TestWikiClientGetHTML(t *testing.T) {
+	ts := newStubWikiAPI(t)
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetHTML(1)
+	assert.NoError(t, err)
+	assert.Equal(t, `<div class="mw-parser-output"><table class="wikitable"><tr><td>Go</td></tr></table></div>`, got)
+}
+
+func TestWikiClientPaginate(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+
+		assert.Equal(t, "links", q.Get("prop"))
+		assert.Equal(t, "2", q.Get("formatversion"))
+
+		switch q.Get("plcontinue") {
+		case "":
+			fmt.Fprint(w, `{"continue":{"plcontinue":"1|0|Google","continue":"||"},"query":{"pages":[{"pageid":1,"ns":0,"title":"Go","links":[{"ns":0,"title":"C"},{"ns":0,"title":"Gopher"}]}]}}`)
+		case "1|0|Google":
+			assert.Equal(t, "||", q.Get("continue"))
+			fmt.Fprint(w, `{"continue":{"plcontinue":"1|0|Rob_Pike","continue":"||"},"query":{"pages":[{"pageid":1,"ns":0,"title":"Go","links":[{"ns":0,"title":"Google"}]}]}}`)
+		case "1|0|Rob_Pike":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":1,"ns":0,"title":"Go","links":[{"ns":0,"title":"Rob Pike"}]}]}}`)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetLinks(1, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, []PageRef{{Title: "C"}, {Title: "Gopher"}, {Title: "Google"}, {Title: "Rob Pike"}}, got)
+
+	// The pagination stops once the limit is reached
+	requests = 0
+	got, err = w.GetLinks(1, nil, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []PageRef{{Title: "C"}, {Title: "Gopher"}, {Title: "Google"}}, got)
+}
+
+func TestWikiClientGetPagesLinks(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+
+		assert.Equal(t, "links", q.Get("prop"))
+		assert.Equal(t, "Go|Unix", q.Get("titles"))
+
+		// The links of "Go" then the ones of "Unix", 2 by response
+		pages := `"pages":[{"pageid":1,"ns":0,"title":"Go","links":[%s]},{"pageid":2,"ns":0,"title":"Unix","links":[%s]}]`
+		switch q.Get("plcontinue") {
+		case "":
+			fmt.Fprintf(w, `{"continue":{"plcontinue":"1|0|Rob_Pike","continue":"||"},"query":{`+pages+`}}`, `{"ns":0,"title":"C"},{"ns":0,"title":"Google"}`, "")
+		case "1|0|Rob_Pike":
+			fmt.Fprintf(w, `{"continue":{"plcontinue":"2|0|Linux","continue":"||"},"query":{`+pages+`}}`, `{"ns":0,"title":"Rob Pike"}`, `{"ns":0,"title":"Bell Labs"}`)
+		case "2|0|Linux":
+			fmt.Fprintf(w, `{"batchcomplete":true,"query":{`+pages+`}}`, "", `{"ns":0,"title":"Linux"},{"ns":0,"title":"Ken Thompson"}`)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		limit        int
+		wantRequests int
+		want         map[string]PageLinks
+	}{
+		{
+			name:         "All the links",
+			wantRequests: 3,
+			want: map[string]PageLinks{
+				"Go":   {Title: "Go", Links: []PageRef{{Title: "C"}, {Title: "Google"}, {Title: "Rob Pike"}}},
+				"Unix": {Title: "Unix", Links: []PageRef{{Title: "Bell Labs"}, {Title: "Linux"}, {Title: "Ken Thompson"}}},
+			},
+		},
+		{
+			// The pagination stops once each page has its links
+			name:         "Limited",
+			limit:        1,
+			wantRequests: 2,
+			want: map[string]PageLinks{
+				"Go":   {Title: "Go", Links: []PageRef{{Title: "C"}}},
+				"Unix": {Title: "Unix", Links: []PageRef{{Title: "Bell Labs"}}},
+			},
+		},
+		{
+			name:         "Limited beyond a response",
+			limit:        2,
+			wantRequests: 3,
+			want: map[string]PageLinks{
+				"Go":   {Title: "Go", Links: []PageRef{{Title: "C"}, {Title: "Google"}}},
+				"Unix": {Title: "Unix", Links: []PageRef{{Title: "Bell Labs"}, {Title: "Linux"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			got, err := w.GetPagesLinks([]string{"Go", "Unix"}, nil, tt.limit)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestWikiClientGetCategories(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		assert.Equal(t, "categories", q.Get("prop"))
+		assert.Equal(t, "hidden", q.Get("clprop"))
+
+		if q.Get("clshow") == "!hidden" {
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":1,"ns":0,"title":"Go","categories":[{"ns":14,"title":"Category:Programming languages"}]}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":1,"ns":0,"title":"Go","categories":[{"ns":14,"title":"Category:Articles with short description","hidden":true},{"ns":14,"title":"Category:Programming languages"}]}]}}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetCategories(1, false, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []PageRef{{Ns: 14, Title: "Category:Programming languages"}}, got)
+
+	got, err = w.GetCategories(1, true, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []PageRef{
+		{Ns: 14, Title: "Category:Articles with short description", Hidden: true},
+		{Ns: 14, Title: "Category:Programming languages"},
+	}, got)
+}
+
+func TestWikiClientGetBacklinks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		assert.Equal(t, "backlinks", q.Get("list"))
+		assert.Equal(t, "1", q.Get("blpageid"))
+		assert.Equal(t, "0|14", q.Get("blnamespace"))
+
+		switch {
+		case q.Get("blredirect") == "1":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"backlinks":[{"pageid":10,"ns":0,"title":"Gopher"},{"pageid":11,"ns":0,"title":"Golang","redirect":true,"redirlinks":[{"pageid":12,"ns":0,"title":"Rob Pike"}]}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"backlinks":[{"pageid":10,"ns":0,"title":"Gopher"},{"pageid":11,"ns":0,"title":"Golang","redirect":true}]}}`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetBacklinks(1, []int{0, 14}, true, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []PageRef{
+		{Pageid: 10, Title: "Gopher"},
+		{Pageid: 11, Title: "Golang", Redirect: true},
+		{Pageid: 12, Title: "Rob Pike", Via: "Golang"},
+	}, got)
+
+	got, err = w.GetBacklinks(1, []int{0, 14}, false, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []PageRef{
+		{Pageid: 10, Title: "Gopher"},
+		{Pageid: 11, Title: "Golang", Redirect: true},
+	}, got)
+}
+
+func TestWikiClientPaginateError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error":{"code":"badvalue","info":"Unrecognized value for parameter \"plnamespace\": -1."}}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	_, err = w.GetLinks(1, []int{-1}, 0)
+	assert.ErrorContains(t, err, `failed to get the links of the page 1: Unrecognized value for parameter "plnamespace": -1.`)
+}
+
+func TestContinueValue(t *testing.T) {
+	assert.Equal(t, "1|0|Google", continueValue([]byte(`"1|0|Google"`)))
+	assert.Equal(t, "10", continueValue([]byte(`10`)))
+}
+
+func TestWikiClientGetExtracts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		assert.Equal(t, "1|2|3", q.Get("pageids"))
+		assert.Equal(t, "1", q.Get("exintro"))
+
+		// The page 3 has no extract
+		fmt.Fprint(w, `{"batchcomplete":"","query":{"pages":{"1":{"pageid":1,"ns":0,"title":"Go","extract":"Go is a language."},"2":{"pageid":2,"ns":0,"title":"C","extract":"C is a language."},"3":{"pageid":3,"ns":0,"title":"Empty","extract":""}}}}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetExtracts([]uint64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint64]string{1: "Go is a language.", 2: "C is a language."}, got)
+
+	_, err = w.GetExtracts(make([]uint64, maxExtractsBatch+1))
+	assert.Error(t, err)
+}
+
+func TestWikiClientGetCoordinates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		assert.Equal(t, "coordinates", q.Get("prop"))
+		assert.Equal(t, "globe", q.Get("coprop"))
+
+		switch q.Get("pageids") {
+		case "1":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":1,"ns":0,"title":"Place Stanislas","coordinates":[{"lat":48.693611,"lon":6.183333,"primary":true,"globe":"earth"}]}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":2,"ns":0,"title":"Go"}]}}`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GetCoordinates(1)
+	assert.NoError(t, err)
+	assert.Equal(t, &WikiCoordinates{Lat: 48.693611, Lon: 6.183333, Globe: "earth"}, got)
+
+	got, err = w.GetCoordinates(2)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestWikiClientGeoSearch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		assert.Equal(t, "geosearch", q.Get("list"))
+		assert.Equal(t, "48.69|6.18", q.Get("gscoord"))
+		assert.Equal(t, "5000", q.Get("gsradius"))
+		assert.Equal(t, "0", q.Get("gsnamespace"))
+		assert.Equal(t, "2", q.Get("gslimit"))
+
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"geosearch":[{"pageid":1,"ns":0,"title":"Place Stanislas","lat":48.693611,"lon":6.183333,"dist":420.5,"primary":true},{"pageid":2,"ns":0,"title":"Nancy Cathedral","lat":48.691,"lon":6.1867,"dist":610.1,"primary":true}]}}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	w, err := NewWikiClient(ts.URL, "")
+	assert.NoError(t, err)
+
+	got, err := w.GeoSearch(48.69, 6.18, 5000, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []NearbyPage{
+		{Pageid: 1, Title: "Place Stanislas", Lat: 48.693611, Lon: 6.183333, Dist: 420.5},
+		{Pageid: 2, Title: "Nancy Cathedral", Lat: 48.691, Lon: 6.1867, Dist: 610.1},
+	}, got)
+}
@@ -2,11 +2,18 @@ package logger
 
 import (
 	"errors"
+	"io"
 	"log/slog"
 	"os"
 )
 
 func New(level, format string) (*slog.Logger, error) {
+	return NewWithWriter(os.Stdout, level, format)
+}
+
+// NewWithWriter creates a new logger writing to w instead of the standard output.
+// This is useful when the standard output is reserved, ie. for a protocol.
+func NewWithWriter(w io.Writer, level, format string) (*slog.Logger, error) {
 	var logger *slog.Logger
 	logLevel, err := toLeveler(level)
 	if err != nil {
@@ -26,11 +33,11 @@ func New(level, format string) (*slog.Logger, error) {
 
 	switch format {
 	case "text":
-		logger = slog.New(slog.NewTextHandler(os.Stdout, opts))
+		logger = slog.New(slog.NewTextHandler(w, opts))
 	case "json":
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, opts))
+		logger = slog.New(slog.NewJSONHandler(w, opts))
 	default:
-		logger = slog.New(slog.NewTextHandler(os.Stdout, opts))
+		logger = slog.New(slog.NewTextHandler(w, opts))
 	}
 
 	return logger, nil
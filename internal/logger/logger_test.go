@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"bytes"
 	"log/slog"
 	"testing"
 
@@ -114,3 +115,49 @@ func TestNewLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestNewWithWriter(t *testing.T) {
+	type args struct {
+		loglevel string
+		format   string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		contains string
+		wantErr  bool
+	}{
+		{
+			name:    "Loglevel: invalid / Format: text",
+			args:    args{loglevel: "invalid", format: "text"},
+			wantErr: true,
+		},
+		{
+			name:     "Loglevel: error / Format: text",
+			args:     args{loglevel: "error", format: "text"},
+			contains: "level=ERROR msg=message",
+			wantErr:  false,
+		},
+		{
+			name:     "Loglevel: error / Format: json",
+			args:     args{loglevel: "error", format: "json"},
+			contains: `"level":"ERROR","msg":"message"`,
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			got, err := NewWithWriter(w, tt.args.loglevel, tt.args.format)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			got.Error("message")
+			assert.Contains(t, w.String(), tt.contains)
+		})
+	}
+}